@@ -13,15 +13,19 @@ import (
 	"cards-110-api/pkg/cache"
 	"cards-110-api/pkg/db"
 	"cards-110-api/pkg/game"
+	"cards-110-api/pkg/match"
 	"cards-110-api/pkg/profile"
+	"cards-110-api/pkg/rating"
 	"cards-110-api/pkg/settings"
 	"cards-110-api/pkg/stats"
+	"cards-110-api/pkg/tournament"
 	"context"
 	"github.com/go-redis/redis/v8"
 	"log"
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -95,7 +99,35 @@ func main() {
 	})
 
 	gameCache := cache.NewRedisCache[game.State](rdb, ctx)
-	statsCache := cache.NewRedisCache[[]stats.PlayerStats](rdb, ctx)
+	statsCache := cache.NewRedisCache[stats.Stats](rdb, ctx)
+	leaderboardCache := cache.NewRedisCache[stats.Leaderboard](rdb, ctx)
+	roundStatsCache := cache.NewRedisCache[stats.RoundStats](rdb, ctx)
+	headToHeadCache := cache.NewRedisCache[stats.HeadToHead](rdb, ctx)
+	statsGenerations := cache.NewRedisCache[int64](rdb, ctx)
+
+	// Forward game revisions published by any instance to the clients connected to this one
+	gameEvents := cache.NewRedisPubSub[game.Revised](rdb, ctx)
+	gameHub := game.NewHub()
+	if err := gameHub.Listen(ctx, gameEvents); err != nil {
+		cancel()
+		log.Fatal("Failed to subscribe to game revisions: ", err)
+	}
+
+	// Configure how much the Monte Carlo bots can think about each move
+	botBudget := game.DefaultISMCTS
+	if iterations := os.Getenv("BOT_ITERATIONS"); iterations != "" {
+		botBudget.Iterations, err = strconv.Atoi(iterations)
+		if err != nil {
+			log.Fatalf("Failed to parse BOT_ITERATIONS: %v", err)
+		}
+	}
+	if timeLimit := os.Getenv("BOT_TIME_LIMIT"); timeLimit != "" {
+		botBudget.TimeLimit, err = time.ParseDuration(timeLimit)
+		if err != nil {
+			log.Fatalf("Failed to parse BOT_TIME_LIMIT: %v", err)
+		}
+	}
+	game.SetStrategy(game.MonteCarlo, botBudget)
 
 	// Configure collections
 	userCol, err := db.GetCollection(ctx, dbName, "appUsers")
@@ -113,6 +145,31 @@ func main() {
 		cancel()
 		log.Fatal("Failed to get games collection: ", err)
 	}
+	gameEventsCol, err := db.GetCollection(ctx, dbName, "gameEvents")
+	if err != nil {
+		cancel()
+		log.Fatal("Failed to get gameEvents collection: ", err)
+	}
+	matchCol, err := db.GetCollection(ctx, dbName, "matches")
+	if err != nil {
+		cancel()
+		log.Fatal("Failed to get matches collection: ", err)
+	}
+	tournamentCol, err := db.GetCollection(ctx, dbName, "tournaments")
+	if err != nil {
+		cancel()
+		log.Fatal("Failed to get tournaments collection: ", err)
+	}
+	ratingCol, err := db.GetCollection(ctx, dbName, "ratings")
+	if err != nil {
+		cancel()
+		log.Fatal("Failed to get ratings collection: ", err)
+	}
+	ratingHistoryCol, err := db.GetCollection(ctx, dbName, "ratingHistory")
+	if err != nil {
+		cancel()
+		log.Fatal("Failed to get ratingHistory collection: ", err)
+	}
 
 	// Configure services
 	profileColRec := db.Collection[profile.Profile]{Col: userCol}
@@ -122,10 +179,47 @@ func main() {
 	settingsService := settings.Service{Col: settingsColRec}
 	settingsHandler := settings.Handler{S: &settingsService}
 	gamesColRec := db.Collection[game.Game]{Col: gameCol}
-	gameService := game.Service{Col: &gamesColRec, Cache: gameCache}
+	gameEventsColRec := db.Collection[game.Event]{Col: gameEventsCol}
+	gameService := game.Service{Col: &gamesColRec, History: &gameEventsColRec, Cache: gameCache, Hub: gameHub, Events: gameEvents, Settings: &settingsService}
 	gameHandler := game.Handler{S: &gameService}
-	statsService := stats.Service{Col: &gamesColRec, Cache: statsCache}
+	matchColRec := db.Collection[match.Match]{Col: matchCol}
+	matchService := match.Service{Col: &matchColRec, Games: &gameService}
+	matchHandler := match.Handler{S: &matchService}
+	ratingColRec := db.Collection[rating.Rating]{Col: ratingCol}
+	ratingHistoryColRec := db.Collection[rating.Change]{Col: ratingHistoryCol}
+	ratingService := rating.Service{Col: &ratingColRec, History: &ratingHistoryColRec, Games: &gamesColRec}
+	ratingHandler := rating.Handler{S: &ratingService}
+	gameService.OnCompleted(ratingService.GameCompleted)
+	gameService.OnCompleted(matchService.GameCompleted)
+	gameService.OnDelete(matchService.CheckDelete)
+	tournamentColRec := db.Collection[tournament.Tournament]{Col: tournamentCol}
+	tournamentService := tournament.Service{Col: &tournamentColRec, Games: &gameService}
+	tournamentHandler := tournament.Handler{S: &tournamentService}
+	gameService.OnCompleted(tournamentService.GameCompleted)
+	gameService.OnDelete(tournamentService.CheckDelete)
+	statsService := stats.Service{Col: &gamesColRec, Cache: statsCache, LeaderboardCache: leaderboardCache, RoundStatsCache: roundStatsCache, HeadToHeadCache: headToHeadCache, Generations: statsGenerations}
 	statsHandler := stats.Handler{S: &statsService}
+	gameService.OnCompleted(statsService.GameCompleted)
+
+	// Let the bots take their go in the background
+	botWorkers := 4
+	if workers := os.Getenv("BOT_WORKERS"); workers != "" {
+		botWorkers, err = strconv.Atoi(workers)
+		if err != nil {
+			log.Fatalf("Failed to parse BOT_WORKERS: %v", err)
+		}
+	}
+	gameService.RunBots(ctx, botWorkers)
+
+	// Take the go of any player who runs out of time
+	turnTimerInterval := 5 * time.Second
+	if interval := os.Getenv("TURN_TIMER_INTERVAL"); interval != "" {
+		turnTimerInterval, err = time.ParseDuration(interval)
+		if err != nil {
+			log.Fatalf("Failed to parse TURN_TIMER_INTERVAL: %v", err)
+		}
+	}
+	go gameService.RunTurnTimer(ctx, turnTimerInterval)
 
 	// Set up the API routes.
 	router := gin.Default()
@@ -160,15 +254,39 @@ func main() {
 	router.PUT("/api/v1/settings", auth.EnsureValidTokenGin([]string{auth.ReadGame}), settingsHandler.Update)
 	router.GET("/api/v1/game/:gameId", auth.EnsureValidTokenGin([]string{auth.ReadGame}), gameHandler.Get)
 	router.GET("/api/v1/game/:gameId/state", auth.EnsureValidTokenGin([]string{auth.ReadGame}), gameHandler.GetState)
+	router.GET("/api/v1/game/:gameId/replay", auth.EnsureValidTokenGin([]string{auth.ReadGame}), gameHandler.Replay)
+	router.GET("/api/v1/game/:gameId/ws", auth.TokenFromQuery("access_token"), auth.EnsureValidTokenGin([]string{auth.ReadGame}), gameHandler.Subscribe)
 	router.PUT("/api/v1/game/:gameId/call", auth.EnsureValidTokenGin([]string{auth.WriteGame}), gameHandler.Call)
 	router.PUT("/api/v1/game/:gameId/suit", auth.EnsureValidTokenGin([]string{auth.WriteGame}), gameHandler.SelectSuit)
 	router.PUT("/api/v1/game/:gameId/buy", auth.EnsureValidTokenGin([]string{auth.WriteGame}), gameHandler.Buy)
 	router.PUT("/api/v1/game/:gameId/play", auth.EnsureValidTokenGin([]string{auth.WriteGame}), gameHandler.Play)
 	router.GET("/api/v1/game/all", auth.EnsureValidTokenGin([]string{auth.ReadGame}), gameHandler.GetAll)
 	router.PUT("/api/v1/game", auth.EnsureValidTokenGin([]string{auth.WriteAdmin}), gameHandler.Create)
+	router.PUT("/api/v1/lobby", auth.EnsureValidTokenGin([]string{auth.WriteAdmin}), gameHandler.CreateLobby)
+	router.PUT("/api/v1/lobby/join/:code", auth.EnsureValidTokenGin([]string{auth.WriteGame}), gameHandler.Join)
+	router.PUT("/api/v1/game/:gameId/invite", auth.EnsureValidTokenGin([]string{auth.WriteAdmin}), gameHandler.Invite)
+	router.PUT("/api/v1/game/:gameId/accept", auth.EnsureValidTokenGin([]string{auth.WriteGame}), gameHandler.Accept)
+	router.PUT("/api/v1/game/:gameId/decline", auth.EnsureValidTokenGin([]string{auth.WriteGame}), gameHandler.Decline)
+	router.PUT("/api/v1/game/:gameId/leave", auth.EnsureValidTokenGin([]string{auth.WriteGame}), gameHandler.Leave)
+	router.PUT("/api/v1/game/:gameId/start", auth.EnsureValidTokenGin([]string{auth.WriteAdmin}), gameHandler.Start)
+	router.PUT("/api/v1/game/:gameId/rematch", auth.EnsureValidTokenGin([]string{auth.WriteAdmin}), gameHandler.Rematch)
 	router.DELETE("/api/v1/game/:gameId", auth.EnsureValidTokenGin([]string{auth.WriteAdmin}), gameHandler.Delete)
+	router.PUT("/api/v1/match", auth.EnsureValidTokenGin([]string{auth.WriteAdmin}), matchHandler.Create)
+	router.GET("/api/v1/match/:matchId", auth.EnsureValidTokenGin([]string{auth.ReadGame}), matchHandler.Get)
+	router.GET("/api/v1/tournament/all", auth.EnsureValidTokenGin([]string{auth.ReadGame}), tournamentHandler.GetAll)
+	router.GET("/api/v1/tournament/:tournamentId", auth.EnsureValidTokenGin([]string{auth.ReadGame}), tournamentHandler.Get)
+	router.GET("/api/v1/tournament/:tournamentId/standings", auth.EnsureValidTokenGin([]string{auth.ReadGame}), tournamentHandler.GetStandings)
+	router.PUT("/api/v1/tournament", auth.EnsureValidTokenGin([]string{auth.WriteAdmin}), tournamentHandler.Create)
+	router.PUT("/api/v1/tournament/:tournamentId/register", auth.EnsureValidTokenGin([]string{auth.WriteGame}), tournamentHandler.Register)
+	router.PUT("/api/v1/tournament/:tournamentId/start", auth.EnsureValidTokenGin([]string{auth.WriteAdmin}), tournamentHandler.Start)
+	router.GET("/api/v1/rating/:playerId", auth.EnsureValidTokenGin([]string{auth.ReadGame}), ratingHandler.Get)
+	router.GET("/api/v1/rating/:playerId/history", auth.EnsureValidTokenGin([]string{auth.ReadGame}), ratingHandler.GetHistory)
 	router.GET("/api/v1/stats", auth.EnsureValidTokenGin([]string{auth.ReadGame}), statsHandler.GetStats)
+	router.GET("/api/v1/leaderboard", auth.EnsureValidTokenGin([]string{auth.ReadGame}), statsHandler.GetLeaderboard)
 	router.GET("/api/v1/stats/:playerId", auth.EnsureValidTokenGin([]string{auth.ReadAdmin}), statsHandler.GetStatsForPlayer)
+	router.GET("/api/v1/stats/rounds", auth.EnsureValidTokenGin([]string{auth.ReadGame}), statsHandler.GetRoundStats)
+	router.GET("/api/v1/stats/:playerId/rounds", auth.EnsureValidTokenGin([]string{auth.ReadAdmin}), statsHandler.GetRoundStatsForPlayer)
+	router.GET("/api/v1/stats/head-to-head/:playerId/:opponentId", auth.EnsureValidTokenGin([]string{auth.ReadGame}), statsHandler.GetHeadToHead)
 
 	// Use the generated docs in the docs package.
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL("/swagger/doc.json")))
@@ -0,0 +1,64 @@
+// Command backfill-ratings recalculates every player's rating by replaying all completed games in the order they were
+// played. It is safe to run more than once.
+//
+// Stop the API before running it. Games completed while it runs are rated by the API as well, and one of the two would
+// overwrite the other's ratings.
+package main
+
+import (
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"cards-110-api/pkg/rating"
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+func init() {
+	// Load .env file if it exists
+	_ = godotenv.Load()
+}
+
+func main() {
+	ctx := context.Background()
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = db.CloseMongoConnection(shutdownCtx)
+	}()
+
+	// Get the db name
+	dbName := os.Getenv("MONGODB_DB")
+	if dbName == "" {
+		dbName = "cards-110"
+	}
+
+	// Configure collections
+	gameCol, err := db.GetCollection(ctx, dbName, "games")
+	if err != nil {
+		log.Fatal("Failed to get games collection: ", err)
+	}
+	ratingCol, err := db.GetCollection(ctx, dbName, "ratings")
+	if err != nil {
+		log.Fatal("Failed to get ratings collection: ", err)
+	}
+	ratingHistoryCol, err := db.GetCollection(ctx, dbName, "ratingHistory")
+	if err != nil {
+		log.Fatal("Failed to get ratingHistory collection: ", err)
+	}
+
+	ratingService := rating.Service{
+		Col:     &db.Collection[rating.Rating]{Col: ratingCol},
+		History: &db.Collection[rating.Change]{Col: ratingHistoryCol},
+		Games:   &db.Collection[game.Game]{Col: gameCol},
+	}
+
+	rated, err := ratingService.Backfill(ctx)
+	if err != nil {
+		log.Fatal("Failed to backfill ratings: ", err)
+	}
+	log.Printf("Rated %d games", rated)
+}
@@ -22,7 +22,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Creates a new game with the given name and players",
+                "description": "Creates a new game with the given name and players. Any bots are seated alongside the players and make their moves automatically.\nThe rules are one of HOUSE, CLASSIC or SHORT, house rules are used if none are given.\nIf a turn time limit is given, a player who doesn't take their go within that many seconds has it taken for them.",
                 "consumes": [
                     "application/json"
                 ],
@@ -143,6 +143,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -157,7 +163,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Deletes a game with the given ID",
+                "description": "Deletes a game with the given ID. Games that are part of a match or tournament can't be deleted.",
                 "tags": [
                     "Game"
                 ],
@@ -181,6 +187,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -190,21 +214,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/game/{gameId}/buy": {
+        "/game/{gameId}/accept": {
             "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "When in the Buying state, the Goer can buy cards from the deck",
+                "description": "Accepts the current user's invitation to the lobby of the game with the given ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Game"
+                    "Lobby"
                 ],
-                "operationId": "buy",
+                "operationId": "accept-invite",
                 "parameters": [
                     {
                         "type": "string",
@@ -227,6 +251,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -236,21 +278,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/game/{gameId}/call": {
+        "/game/{gameId}/buy": {
             "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Makes a call for the current user in the game with the given ID",
+                "description": "When in the Buying state, the Goer can buy cards from the deck",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Game"
                 ],
-                "operationId": "call",
+                "operationId": "buy",
                 "parameters": [
                     {
                         "type": "string",
@@ -258,13 +300,6 @@ const docTemplate = `{
                         "name": "gameId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Call",
-                        "name": "call",
-                        "in": "query",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -280,6 +315,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -289,21 +342,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/game/{gameId}/play": {
+        "/game/{gameId}/call": {
             "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "When in the Playing state, the current player can play a card",
+                "description": "Makes a call for the current user in the game with the given ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Game"
                 ],
-                "operationId": "play",
+                "operationId": "call",
                 "parameters": [
                     {
                         "type": "string",
@@ -313,9 +366,9 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Card",
-                        "name": "card",
+                        "type": "integer",
+                        "description": "Call",
+                        "name": "call",
                         "in": "query",
                         "required": true
                     }
@@ -333,6 +386,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -342,21 +413,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/game/{gameId}/state": {
-            "get": {
+        "/game/{gameId}/decline": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Returns the state of a game with the given ID for the current user",
+                "description": "Declines the current user's invitation to the lobby of the game with the given ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Game"
+                    "Lobby"
                 ],
-                "operationId": "get-game-state",
+                "operationId": "decline-invite",
                 "parameters": [
                     {
                         "type": "string",
@@ -364,12 +435,6 @@ const docTemplate = `{
                         "name": "gameId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Revision",
-                        "name": "revision",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -379,15 +444,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/game.State"
                         }
                     },
-                    "204": {
-                        "description": "No Content"
-                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -397,21 +477,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/game/{gameId}/suit": {
+        "/game/{gameId}/invite": {
             "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "When in the Called state, the Goer can select the suit and what cards they want to keep from their hand and the dummy hand",
+                "description": "Invites players to the lobby of the game with the given ID. Only the admin can invite players.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Game"
+                    "Lobby"
                 ],
-                "operationId": "select-suit",
+                "operationId": "invite",
                 "parameters": [
                     {
                         "type": "string",
@@ -419,6 +502,15 @@ const docTemplate = `{
                         "name": "gameId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Players",
+                        "name": "players",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/game.InviteRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -434,6 +526,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -443,34 +553,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/profile": {
-            "get": {
+        "/game/{gameId}/leave": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Returns the user's profile.",
+                "description": "Removes the current user from the lobby of the game with the given ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Lobby"
                 ],
-                "operationId": "get-profile",
+                "operationId": "leave-lobby",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Player ID",
-                        "name": "playerId",
-                        "in": "query"
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/profile.Profile"
+                            "$ref": "#/definitions/game.State"
                         }
                     },
                     "400": {
@@ -479,12 +590,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -492,40 +615,44 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/game/{gameId}/play": {
             "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Updates the user's profile or creates it if it doesn't exist.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "When in the Playing state, the current player can play a card\nIf the card doesn't follow suit the error details give the suit led, the trump suit and the cards that could have been played",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Game"
                 ],
-                "operationId": "update-profile",
+                "operationId": "play",
                 "parameters": [
                     {
-                        "description": "Profile",
-                        "name": "profile",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/profile.UpdateProfileRequest"
-                        }
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Card",
+                        "name": "card",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/profile.Profile"
+                            "$ref": "#/definitions/game.State"
                         }
                     },
                     "400": {
@@ -534,12 +661,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -549,29 +688,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/profile/all": {
-            "get": {
+        "/game/{gameId}/rematch": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Returns all profiles.",
+                "description": "Creates a new game for the same table once the game with the given ID is over. Everyone keeps their seat, the rules stay the same and the first deal moves on to the next player.\nThe games are linked by previousGameId and nextGameId so the series can be followed. Only the admin can create the rematch.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Game"
+                ],
+                "operationId": "rematch-game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "operationId": "get-all-profiles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/profile.Profile"
-                            }
+                            "$ref": "#/definitions/game.Game"
                         }
                     },
                     "400": {
@@ -580,6 +725,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -589,27 +752,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/settings": {
+        "/game/{gameId}/replay": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Returns the user's settings.",
+                "description": "Returns the game as it stood at the given revision, or at the end of the given hand of a round (hand 0 is the start of the round).\nOnce a game is completed it is returned in full, including everyone's cards. For active games only the current user's state is returned.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Settings"
+                    "Game"
                 ],
-                "operationId": "get-settings",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/settings.Settings"
-                        }
+                "operationId": "replay-game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision",
+                        "name": "revision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Round number",
+                        "name": "round",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Hand number, only with a round",
+                        "name": "hand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/game.Replayed"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -630,14 +820,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/game/{gameId}/start": {
             "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Updates the user's settings.",
+                "description": "Deals the game with the players that have joined the lobby, filling any remaining seats with bots. Only the admin can start the game.",
                 "consumes": [
                     "application/json"
                 ],
@@ -645,17 +837,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Settings"
+                    "Lobby"
                 ],
-                "operationId": "update-settings",
+                "operationId": "start-game",
                 "parameters": [
                     {
-                        "description": "Settings",
-                        "name": "settings",
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Bots",
+                        "name": "bots",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/settings.Settings"
+                            "$ref": "#/definitions/game.StartGameRequest"
                         }
                     }
                 ],
@@ -663,7 +862,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/settings.Settings"
+                            "$ref": "#/definitions/game.State"
                         }
                     },
                     "400": {
@@ -672,12 +871,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -687,34 +898,58 @@ const docTemplate = `{
                 }
             }
         },
-        "/stats": {
+        "/game/{gameId}/state": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Returns stats for the current user",
+                "description": "Returns the state of a game with the given ID for the current user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Stats"
+                    "Game"
+                ],
+                "operationId": "get-game-state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision",
+                        "name": "revision",
+                        "in": "query"
+                    }
                 ],
-                "operationId": "get-stats",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/stats.PlayerStats"
+                            "$ref": "#/definitions/game.State"
                         }
                     },
+                    "204": {
+                        "description": "No Content"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -724,21 +959,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/stats/{playerId}": {
-            "get": {
-                "description": "Returns stats for a player",
+        "/game/{gameId}/suit": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "When in the Called state, the Goer can select the suit and what cards they want to keep from their hand and the dummy hand",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Stats"
+                    "Game"
                 ],
-                "operationId": "get-stats-for-player",
+                "operationId": "select-suit",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Player ID",
-                        "name": "playerId",
+                        "description": "Game ID",
+                        "name": "gameId",
                         "in": "path",
                         "required": true
                     }
@@ -747,7 +987,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/stats.PlayerStats"
+                            "$ref": "#/definitions/game.State"
                         }
                     },
                     "400": {
@@ -756,6 +996,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -764,315 +1022,2453 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "game.Call": {
-            "type": "integer",
-            "enum": [
-                0
-            ],
-            "x-enum-varnames": [
-                "Pass"
-            ]
-        },
-        "game.CardName": {
-            "type": "string",
-            "enum": [
-                "EMPTY"
-            ],
-            "x-enum-varnames": [
-                "EMPTY_CARD"
-            ]
         },
-        "game.CreateGameRequest": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string"
-                },
-                "players": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        "/game/{gameId}/ws": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
                     }
-                }
-            }
-        },
-        "game.Game": {
-            "type": "object",
-            "properties": {
-                "adminId": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "players": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/game.Player"
+                ],
+                "description": "Upgrades the connection to a WebSocket and pushes the state of the game for the current user every time the game is revised.\nThe access token can be passed in the access_token query parameter as browsers can't set headers on a WebSocket handshake.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Game"
+                ],
+                "operationId": "subscribe-game-state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "access_token",
+                        "in": "query"
                     }
-                },
-                "revision": {
-                    "type": "integer"
-                },
-                "status": {
-                    "$ref": "#/definitions/game.Status"
-                },
-                "timestamp": {
-                    "type": "string"
-                }
-            }
-        },
-        "game.Hand": {
-            "type": "object",
-            "properties": {
-                "currentPlayerId": {
-                    "type": "string"
-                },
-                "leadOut": {
-                    "$ref": "#/definitions/game.CardName"
-                },
-                "playedCards": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/game.PlayedCard"
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/game.State"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
-                },
-                "timestamp": {
-                    "type": "string"
                 }
             }
         },
-        "game.PlayedCard": {
-            "type": "object",
-            "properties": {
-                "card": {
-                    "$ref": "#/definitions/game.CardName"
-                },
-                "playerId": {
+        "/leaderboard": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Ranks the players who completed a game in a window of time. Players can be ranked by WINS, WIN_RATE, AVERAGE_SCORE, RINGS or RATING, where rating is always a player's current rating.\nThe window is ALL_TIME, SEASON (the current quarter), MONTH or WEEK (starting on Monday), all in UTC.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "operationId": "get-leaderboard",
+                "parameters": [
+                    {
+                        "enum": [
+                            "WINS",
+                            "WIN_RATE",
+                            "AVERAGE_SCORE",
+                            "RINGS",
+                            "RATING"
+                        ],
+                        "type": "string",
+                        "default": "WINS",
+                        "description": "Metric",
+                        "name": "metric",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ALL_TIME",
+                            "SEASON",
+                            "MONTH",
+                            "WEEK"
+                        ],
+                        "type": "string",
+                        "default": "ALL_TIME",
+                        "description": "Window",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of players",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.Leaderboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Creates an open table for a game. Players can be invited or join with the join code, the game is dealt once the admin starts it.\nThe rules are one of HOUSE, CLASSIC or SHORT, house rules are used if none are given.\nIf a turn time limit is given, a player who doesn't take their go within that many seconds has it taken for them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lobby"
+                ],
+                "operationId": "create-lobby",
+                "parameters": [
+                    {
+                        "description": "Lobby",
+                        "name": "lobby",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/game.CreateLobbyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/game.CreatedLobby"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/lobby/join/{code}": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Joins the lobby with the given join code, no invitation is needed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lobby"
+                ],
+                "operationId": "join-lobby",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Join code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/game.State"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/match": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Creates a best of N match and its first game. The game is set up in the same way as a single game.\nWhen a game completes its winning team is awarded the game and the next game is started, until a team has won a majority of the games.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Match"
+                ],
+                "operationId": "create-match",
+                "parameters": [
+                    {
+                        "description": "Match",
+                        "name": "match",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/match.CreateMatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/match.Match"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/match/{matchId}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns a match with the given ID, including the games played so far and how many each team has won",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Match"
+                ],
+                "operationId": "get-match",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Match ID",
+                        "name": "matchId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/match.Match"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns the user's profile.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "operationId": "get-profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/profile.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Updates the user's profile or creates it if it doesn't exist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "operationId": "update-profile",
+                "parameters": [
+                    {
+                        "description": "Profile",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/profile.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/profile.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/all": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns all profiles.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "operationId": "get-all-profiles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/profile.Profile"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rating/{playerId}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns the current rating of a player. Players who haven't completed a game have the initial rating of 1500.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rating"
+                ],
+                "operationId": "get-rating",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rating.Rating"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rating/{playerId}/history": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns how a player's rating changed after each game they completed, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rating"
+                ],
+                "operationId": "get-rating-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rating.Change"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns the user's settings.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "operationId": "get-settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/settings.Settings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Updates the user's settings.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "operationId": "update-settings",
+                "parameters": [
+                    {
+                        "description": "Settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/settings.Settings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/settings.Settings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns a page of the current user's completed games, newest first, along with a summary of all the games that match the filters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "operationId": "get-stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only games created at or after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only games created before this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only games played with this player",
+                        "name": "opponent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only games with this number of players",
+                        "name": "players",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page, at most 10000",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Games per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.Stats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/head-to-head/{playerId}/{opponentId}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Compares two players over the completed games they played together: how many games each won, the average difference in their final scores and how many tricks and rounds each won against the other",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "operationId": "get-head-to-head",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opponent ID",
+                        "name": "opponentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.HeadToHead"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/rounds": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns stats for the current user from the rounds of their completed games: the calls they made, the contracts they made and failed, jinks, tricks, rounds in the bunker and how often they took a call as the dealer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "operationId": "get-round-stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.RoundStats"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/{playerId}": {
+            "get": {
+                "description": "Returns a page of a player's completed games, newest first, along with a summary of all the games that match the filters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "operationId": "get-stats-for-player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only games created at or after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only games created before this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only games played with this player",
+                        "name": "opponent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only games with this number of players",
+                        "name": "players",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page, at most 10000",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Games per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.Stats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/{playerId}/rounds": {
+            "get": {
+                "description": "Returns stats for a player from the rounds of their completed games",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "operationId": "get-round-stats-for-player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.RoundStats"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Creates a tournament that players can register for until the admin starts it.\nThe format is ROUND_ROBIN, where everyone plays a fixed number of rounds against different opponents, or KNOCKOUT, where only the winners of each table go through to the next round.\nTables are made up of 2-6 players and every game is played with the same rules and turn time limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tournament"
+                ],
+                "operationId": "create-tournament",
+                "parameters": [
+                    {
+                        "description": "Tournament",
+                        "name": "tournament",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/tournament.CreateTournamentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/tournament.Tournament"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/all": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns all tournaments",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tournament"
+                ],
+                "operationId": "get-all-tournaments",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/tournament.Tournament"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/{tournamentId}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns a tournament with the given ID, including the tables and games of every round played so far",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tournament"
+                ],
+                "operationId": "get-tournament",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament ID",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/tournament.Tournament"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/{tournamentId}/register": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Enters the current user in a tournament that hasn't started yet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tournament"
+                ],
+                "operationId": "register-tournament",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament ID",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/tournament.Tournament"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/{tournamentId}/standings": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns the entrants of a tournament ordered by how they are doing. In a knockout tournament the entrants still in the tournament come first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tournament"
+                ],
+                "operationId": "get-tournament-standings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament ID",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/tournament.Standing"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tournament/{tournamentId}/start": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Closes registration, draws the tables for the first round and creates their games. Only the admin can start a tournament.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tournament"
+                ],
+                "operationId": "start-tournament",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tournament ID",
+                        "name": "tournamentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/tournament.Tournament"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.ErrorCode": {
+            "type": "string",
+            "enum": [
+                "NOT_FOUND"
+            ],
+            "x-enum-varnames": [
+                "NotFound"
+            ]
+        },
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "enum": [
+                        "NOT_FOUND",
+                        "NOT_YOUR_TURN",
+                        "ILLEGAL_MOVE",
+                        "WRONG_PHASE",
+                        "FORBIDDEN",
+                        "CONFLICT",
+                        "INVALID_REQUEST",
+                        "INTERNAL"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.ErrorCode"
+                        }
+                    ]
+                },
+                "details": {},
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "game.BotType": {
+            "type": "string",
+            "enum": [
+                "RULE_BASED",
+                "MONTE_CARLO"
+            ],
+            "x-enum-varnames": [
+                "RuleBased",
+                "MonteCarlo"
+            ]
+        },
+        "game.Call": {
+            "type": "integer",
+            "enum": [
+                0
+            ],
+            "x-enum-varnames": [
+                "Pass"
+            ]
+        },
+        "game.CardName": {
+            "type": "string",
+            "enum": [
+                "EMPTY"
+            ],
+            "x-enum-varnames": [
+                "EMPTY_CARD"
+            ]
+        },
+        "game.CreateGameRequest": {
+            "type": "object",
+            "properties": {
+                "bots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.BotType"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rules": {
+                    "$ref": "#/definitions/game.RulesPreset"
+                },
+                "turnTimeLimit": {
+                    "type": "integer"
+                }
+            }
+        },
+        "game.CreateLobbyRequest": {
+            "type": "object",
+            "properties": {
+                "invited": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rules": {
+                    "$ref": "#/definitions/game.RulesPreset"
+                },
+                "turnTimeLimit": {
+                    "type": "integer"
+                }
+            }
+        },
+        "game.CreatedLobby": {
+            "type": "object",
+            "properties": {
+                "adminId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invited": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "joinCode": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nextGameId": {
+                    "type": "string"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.Player"
+                    }
+                },
+                "previousGameId": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "$ref": "#/definitions/game.Rules"
+                },
+                "status": {
+                    "$ref": "#/definitions/game.Status"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "game.FullPlayer": {
+            "type": "object",
+            "properties": {
+                "bot": {
+                    "$ref": "#/definitions/game.BotType"
+                },
+                "call": {
+                    "$ref": "#/definitions/game.Call"
+                },
+                "cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.CardName"
+                    }
+                },
+                "cardsBought": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rings": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "seatNumber": {
+                    "type": "integer"
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "winner": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "game.FullState": {
+            "type": "object",
+            "properties": {
+                "completedRounds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.Round"
+                    }
+                },
+                "deck": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.CardName"
+                    }
+                },
+                "dummy": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.CardName"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.FullPlayer"
+                    }
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "round": {
+                    "$ref": "#/definitions/game.Round"
+                },
+                "seed": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/game.Status"
+                }
+            }
+        },
+        "game.Game": {
+            "type": "object",
+            "properties": {
+                "adminId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invited": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nextGameId": {
+                    "type": "string"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.Player"
+                    }
+                },
+                "previousGameId": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "$ref": "#/definitions/game.Rules"
+                },
+                "status": {
+                    "$ref": "#/definitions/game.Status"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "game.Hand": {
+            "type": "object",
+            "properties": {
+                "currentPlayerId": {
+                    "type": "string"
+                },
+                "leadOut": {
+                    "$ref": "#/definitions/game.CardName"
+                },
+                "playedCards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.PlayedCard"
+                    }
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "game.InviteRequest": {
+            "type": "object",
+            "properties": {
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "game.Keep": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.CardName"
+                    }
+                },
+                "max": {
+                    "type": "integer"
+                },
+                "min": {
+                    "type": "integer"
+                }
+            }
+        },
+        "game.Moves": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.Call"
+                    }
+                },
+                "cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.CardName"
+                    }
+                },
+                "keep": {
+                    "$ref": "#/definitions/game.Keep"
+                },
+                "suits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.Suit"
+                    }
+                }
+            }
+        },
+        "game.PlayedCard": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "$ref": "#/definitions/game.CardName"
+                },
+                "playerId": {
+                    "type": "string"
+                }
+            }
+        },
+        "game.Player": {
+            "type": "object",
+            "properties": {
+                "bot": {
+                    "$ref": "#/definitions/game.BotType"
+                },
+                "call": {
+                    "$ref": "#/definitions/game.Call"
+                },
+                "cardsBought": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rings": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "seatNumber": {
+                    "type": "integer"
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "winner": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "game.PlayerCall": {
+            "type": "object",
+            "properties": {
+                "call": {
+                    "$ref": "#/definitions/game.Call"
+                },
+                "playerId": {
+                    "type": "string"
+                }
+            }
+        },
+        "game.Replayed": {
+            "type": "object",
+            "properties": {
+                "game": {
+                    "$ref": "#/definitions/game.FullState"
+                },
+                "state": {
+                    "$ref": "#/definitions/game.State"
+                }
+            }
+        },
+        "game.Round": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "description": "Calls are the calls made in the round, in the order they were made",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.PlayerCall"
+                    }
+                },
+                "completedHands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.Hand"
+                    }
+                },
+                "currentHand": {
+                    "$ref": "#/definitions/game.Hand"
+                },
+                "dealerId": {
+                    "type": "string"
+                },
+                "dealerSeeingCall": {
+                    "type": "boolean"
+                },
+                "goerId": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "scores": {
+                    "description": "Scores are only set for rounds that were played out",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.RoundScore"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/game.RoundStatus"
+                },
+                "suit": {
+                    "$ref": "#/definitions/game.Suit"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "game.RoundScore": {
+            "type": "object",
+            "properties": {
+                "playerId": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "game.RoundStatus": {
+            "type": "string",
+            "enum": [
+                "CALLING"
+            ],
+            "x-enum-varnames": [
+                "Calling"
+            ]
+        },
+        "game.Rules": {
+            "type": "object",
+            "properties": {
+                "bunker": {
+                    "description": "Bunker is the score below which a player can only pass.",
+                    "type": "integer"
+                },
+                "jinkPoints": {
+                    "description": "JinkPoints is the score for making a jink.",
+                    "type": "integer"
+                },
+                "minKeep": {
+                    "description": "MinKeep is the minimum number of cards that must be kept when buying, indexed by the number of players.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "preset": {
+                    "$ref": "#/definitions/game.RulesPreset"
+                },
+                "target": {
+                    "description": "Target is the score a team must reach to win the game.",
+                    "type": "integer"
+                },
+                "tenCallPlayers": {
+                    "description": "TenCallPlayers are the numbers of players that a call of 10 is allowed with.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "turnTimeLimit": {
+                    "description": "TurnTimeLimit is the number of seconds a player has to take their go before it is taken for them, 0 for no limit.",
+                    "type": "integer"
+                }
+            }
+        },
+        "game.RulesPreset": {
+            "type": "string",
+            "enum": [
+                "HOUSE"
+            ],
+            "x-enum-varnames": [
+                "HouseRules"
+            ]
+        },
+        "game.StartGameRequest": {
+            "type": "object",
+            "properties": {
+                "bots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.BotType"
+                    }
+                }
+            }
+        },
+        "game.State": {
+            "type": "object",
+            "properties": {
+                "cards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.CardName"
+                    }
+                },
+                "iamAdmin": {
+                    "type": "boolean"
+                },
+                "iamDealer": {
+                    "type": "boolean"
+                },
+                "iamGoer": {
+                    "type": "boolean"
+                },
+                "iamSpectator": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isMyGo": {
+                    "type": "boolean"
+                },
+                "joinCode": {
+                    "description": "JoinCode is only given to the admin of a lobby",
+                    "type": "string"
+                },
+                "maxCall": {
+                    "$ref": "#/definitions/game.Call"
+                },
+                "me": {
+                    "$ref": "#/definitions/game.Player"
+                },
+                "moves": {
+                    "$ref": "#/definitions/game.Moves"
+                },
+                "nextGameId": {
+                    "type": "string"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.Player"
+                    }
+                },
+                "previousGameId": {
+                    "type": "string"
+                },
+                "previousRound": {
+                    "$ref": "#/definitions/game.Round"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "round": {
+                    "$ref": "#/definitions/game.Round"
+                },
+                "rules": {
+                    "$ref": "#/definitions/game.Rules"
+                },
+                "seed": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/game.Status"
+                }
+            }
+        },
+        "game.Status": {
+            "type": "string",
+            "enum": [
+                "ACTIVE"
+            ],
+            "x-enum-varnames": [
+                "Active"
+            ]
+        },
+        "game.Suit": {
+            "type": "string",
+            "enum": [
+                "EMPTY"
+            ],
+            "x-enum-varnames": [
+                "Empty"
+            ]
+        },
+        "match.CreateMatchRequest": {
+            "type": "object",
+            "properties": {
+                "bestOf": {
+                    "type": "integer"
+                },
+                "bots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/game.BotType"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rules": {
+                    "$ref": "#/definitions/game.RulesPreset"
+                },
+                "turnTimeLimit": {
+                    "type": "integer"
+                }
+            }
+        },
+        "match.Match": {
+            "type": "object",
+            "properties": {
+                "adminId": {
+                    "type": "string"
+                },
+                "bestOf": {
+                    "type": "integer"
+                },
+                "gameIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/match.Status"
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/match.Team"
+                    }
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "winnerId": {
+                    "type": "string"
+                },
+                "winsNeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "match.Status": {
+            "type": "string",
+            "enum": [
+                "ACTIVE"
+            ],
+            "x-enum-varnames": [
+                "Active"
+            ]
+        },
+        "match.Team": {
+            "type": "object",
+            "properties": {
+                "playerIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "teamId": {
+                    "type": "string"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "profile.Profile": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "lastAccess": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "pictureLocked": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "profile.UpdateProfileRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "forceUpdate": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "picture": {
+                    "type": "string"
+                }
+            }
+        },
+        "rating.Change": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "number"
+                },
+                "before": {
+                    "type": "number"
+                },
+                "gameId": {
+                    "type": "string"
+                },
+                "playerId": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "rating.Rating": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "integer"
+                },
+                "playerId": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "timestamp": {
                     "type": "string"
                 }
             }
         },
-        "game.Player": {
+        "settings.Settings": {
+            "type": "object",
+            "properties": {
+                "autoBuyCards": {
+                    "type": "boolean"
+                },
+                "autoPlayCards": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "stats.HeadToHead": {
             "type": "object",
             "properties": {
-                "call": {
-                    "$ref": "#/definitions/game.Call"
+                "averageScoreDifference": {
+                    "description": "AverageScoreDifference is the player's final score minus the opponent's, averaged over the games",
+                    "type": "number"
                 },
-                "cardsBought": {
+                "games": {
                     "type": "integer"
                 },
-                "id": {
+                "opponentId": {
                     "type": "string"
                 },
-                "rings": {
+                "opponentRoundsWon": {
                     "type": "integer"
                 },
-                "score": {
+                "opponentTricks": {
                     "type": "integer"
                 },
-                "seatNumber": {
+                "opponentWins": {
                     "type": "integer"
                 },
-                "teamId": {
+                "playerId": {
                     "type": "string"
                 },
-                "winner": {
-                    "type": "boolean"
+                "roundsDrawn": {
+                    "description": "RoundsDrawn are the rounds played out in which they won the same number of tricks. Rounds where neither of them\nwon a trick aren't counted.",
+                    "type": "integer"
+                },
+                "roundsWon": {
+                    "description": "RoundsWon are the rounds played out in which the player won more tricks than the opponent",
+                    "type": "integer"
+                },
+                "tricks": {
+                    "type": "integer"
+                },
+                "wins": {
+                    "type": "integer"
                 }
             }
         },
-        "game.Round": {
+        "stats.Leaderboard": {
             "type": "object",
             "properties": {
-                "completedHands": {
+                "entries": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/game.Hand"
+                        "$ref": "#/definitions/stats.LeaderboardEntry"
                     }
                 },
-                "currentHand": {
-                    "$ref": "#/definitions/game.Hand"
-                },
-                "dealerId": {
+                "from": {
                     "type": "string"
                 },
-                "dealerSeeingCall": {
-                    "type": "boolean"
+                "metric": {
+                    "$ref": "#/definitions/stats.Metric"
                 },
-                "goerId": {
+                "window": {
+                    "$ref": "#/definitions/stats.Window"
+                }
+            }
+        },
+        "stats.LeaderboardEntry": {
+            "type": "object",
+            "properties": {
+                "averageScore": {
+                    "type": "number"
+                },
+                "games": {
+                    "type": "integer"
+                },
+                "playerId": {
                     "type": "string"
                 },
-                "number": {
+                "rank": {
                     "type": "integer"
                 },
-                "status": {
-                    "$ref": "#/definitions/game.RoundStatus"
+                "rating": {
+                    "type": "number"
                 },
-                "suit": {
-                    "$ref": "#/definitions/game.Suit"
+                "rings": {
+                    "type": "integer"
                 },
-                "timestamp": {
-                    "type": "string"
+                "winRate": {
+                    "type": "number"
+                },
+                "wins": {
+                    "type": "integer"
                 }
             }
         },
-        "game.RoundStatus": {
+        "stats.Metric": {
             "type": "string",
             "enum": [
-                "CALLING"
+                "WINS"
             ],
             "x-enum-varnames": [
-                "Calling"
+                "Wins"
             ]
         },
-        "game.State": {
+        "stats.PlayerStats": {
             "type": "object",
             "properties": {
-                "cards": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/game.CardName"
-                    }
+                "gameId": {
+                    "type": "string"
                 },
-                "iamAdmin": {
-                    "type": "boolean"
+                "rings": {
+                    "type": "integer"
                 },
-                "iamDealer": {
-                    "type": "boolean"
+                "score": {
+                    "type": "integer"
                 },
-                "iamGoer": {
-                    "type": "boolean"
+                "timestamp": {
+                    "type": "string"
                 },
-                "iamSpectator": {
+                "winner": {
                     "type": "boolean"
+                }
+            }
+        },
+        "stats.RoundStats": {
+            "type": "object",
+            "properties": {
+                "bestCardTricks": {
+                    "description": "BestCardTricks are the tricks won with the best trump, worth 10 points",
+                    "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "calls": {
+                    "description": "Calls is how many times each call was made, passes included",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
                 },
-                "isMyGo": {
-                    "type": "boolean"
+                "contractsFailed": {
+                    "type": "integer"
                 },
-                "maxCall": {
-                    "$ref": "#/definitions/game.Call"
+                "contractsMade": {
+                    "type": "integer"
                 },
-                "me": {
-                    "$ref": "#/definitions/game.Player"
+                "dealerSeeing": {
+                    "description": "DealerSeeing is how many times the player took a call as the dealer",
+                    "type": "integer"
                 },
-                "players": {
+                "games": {
+                    "type": "integer"
+                },
+                "jinksAttempted": {
+                    "type": "integer"
+                },
+                "jinksSucceeded": {
+                    "type": "integer"
+                },
+                "playerId": {
+                    "type": "string"
+                },
+                "rounds": {
+                    "type": "integer"
+                },
+                "roundsInBunker": {
+                    "type": "integer"
+                },
+                "tricksWon": {
+                    "type": "integer"
+                }
+            }
+        },
+        "stats.Stats": {
+            "type": "object",
+            "properties": {
+                "games": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/game.Player"
+                        "$ref": "#/definitions/stats.PlayerStats"
                     }
                 },
-                "previousRound": {
-                    "$ref": "#/definitions/game.Round"
+                "limit": {
+                    "type": "integer"
                 },
-                "revision": {
+                "page": {
                     "type": "integer"
                 },
-                "round": {
-                    "$ref": "#/definitions/game.Round"
+                "summary": {
+                    "$ref": "#/definitions/stats.Summary"
+                }
+            }
+        },
+        "stats.Summary": {
+            "type": "object",
+            "properties": {
+                "currentStreak": {
+                    "description": "CurrentStreak is the number of games won in a row up to the latest game, or lost in a row if negative",
+                    "type": "integer"
                 },
-                "status": {
-                    "$ref": "#/definitions/game.Status"
+                "games": {
+                    "type": "integer"
+                },
+                "longestLossStreak": {
+                    "type": "integer"
+                },
+                "longestWinStreak": {
+                    "type": "integer"
+                },
+                "meanScore": {
+                    "type": "number"
+                },
+                "rings": {
+                    "type": "integer"
+                },
+                "winRate": {
+                    "description": "WinRate is the fraction of the games that were won",
+                    "type": "number"
+                },
+                "wins": {
+                    "type": "integer"
                 }
             }
         },
-        "game.Status": {
+        "stats.Window": {
             "type": "string",
             "enum": [
-                "ACTIVE"
+                "ALL_TIME"
             ],
             "x-enum-varnames": [
-                "Active"
+                "AllTime"
             ]
         },
-        "game.Suit": {
+        "tournament.CreateTournamentRequest": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "$ref": "#/definitions/tournament.Format"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rules": {
+                    "$ref": "#/definitions/game.RulesPreset"
+                },
+                "tableSize": {
+                    "type": "integer"
+                },
+                "turnTimeLimit": {
+                    "type": "integer"
+                }
+            }
+        },
+        "tournament.Format": {
             "type": "string",
             "enum": [
-                "EMPTY"
+                "ROUND_ROBIN"
             ],
             "x-enum-varnames": [
-                "Empty"
+                "RoundRobin"
             ]
         },
-        "profile.Profile": {
+        "tournament.Round": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "lastAccess": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "picture": {
-                    "type": "string"
+                "number": {
+                    "type": "integer"
                 },
-                "pictureLocked": {
-                    "type": "boolean"
+                "tables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/tournament.Table"
+                    }
                 }
             }
         },
-        "profile.UpdateProfileRequest": {
+        "tournament.Standing": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "forceUpdate": {
+                "byes": {
+                    "type": "integer"
+                },
+                "eliminated": {
                     "type": "boolean"
                 },
-                "name": {
-                    "type": "string"
+                "played": {
+                    "type": "integer"
                 },
-                "picture": {
+                "playerId": {
                     "type": "string"
+                },
+                "won": {
+                    "type": "integer"
                 }
             }
         },
-        "settings.Settings": {
+        "tournament.Status": {
+            "type": "string",
+            "enum": [
+                "REGISTERING"
+            ],
+            "x-enum-varnames": [
+                "Registering"
+            ]
+        },
+        "tournament.Table": {
             "type": "object",
             "properties": {
-                "autoBuyCards": {
+                "bye": {
                     "type": "boolean"
+                },
+                "completed": {
+                    "type": "boolean"
+                },
+                "gameId": {
+                    "type": "string"
+                },
+                "playerIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "winnerIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "stats.PlayerStats": {
+        "tournament.Tournament": {
             "type": "object",
             "properties": {
-                "gameId": {
+                "adminId": {
                     "type": "string"
                 },
-                "rings": {
+                "entrants": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "format": {
+                    "$ref": "#/definitions/tournament.Format"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revision": {
                     "type": "integer"
                 },
-                "score": {
+                "rounds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/tournament.Round"
+                    }
+                },
+                "rules": {
+                    "$ref": "#/definitions/game.RulesPreset"
+                },
+                "status": {
+                    "$ref": "#/definitions/tournament.Status"
+                },
+                "tableSize": {
                     "type": "integer"
                 },
                 "timestamp": {
                     "type": "string"
                 },
-                "winner": {
-                    "type": "boolean"
+                "turnTimeLimit": {
+                    "type": "integer"
+                },
+                "winnerIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         }
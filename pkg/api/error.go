@@ -1,6 +1,87 @@
 package api
 
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorCode identifies the kind of error. Codes are stable so clients can rely on them rather than the message.
+type ErrorCode string
+
+const (
+	NotFound       ErrorCode = "NOT_FOUND"
+	NotYourTurn              = "NOT_YOUR_TURN"
+	IllegalMove              = "ILLEGAL_MOVE"
+	WrongPhase               = "WRONG_PHASE"
+	Forbidden                = "FORBIDDEN"
+	Conflict                 = "CONFLICT"
+	InvalidRequest           = "INVALID_REQUEST"
+	Internal                 = "INTERNAL"
+)
+
+// Status returns the HTTP status code for an error code.
+func (c ErrorCode) Status() int {
+	switch c {
+	case NotFound:
+		return http.StatusNotFound
+	case NotYourTurn, Forbidden:
+		return http.StatusForbidden
+	case IllegalMove, InvalidRequest:
+		return http.StatusBadRequest
+	case WrongPhase, Conflict:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// Error is an error caused by the request rather than the server.
+// Details can be set to explain the error to the client, e.g. which moves would have been legal.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Details any
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errorf returns an error with the given code and a formatted message.
+func Errorf(code ErrorCode, format string, a ...any) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// ErrorWithDetails returns an error with the given code, details and a formatted message.
+func ErrorWithDetails(code ErrorCode, details any, format string, a ...any) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, a...), Details: details}
+}
+
+// CodeOf returns the code of an error. Errors without a code are internal errors.
+func CodeOf(err error) ErrorCode {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return Internal
+}
+
 // ErrorResponse represents a generic error response.
 type ErrorResponse struct {
-	Message string `json:"message"`
+	Code    ErrorCode `json:"code" enums:"NOT_FOUND,NOT_YOUR_TURN,ILLEGAL_MOVE,WRONG_PHASE,FORBIDDEN,CONFLICT,INVALID_REQUEST,INTERNAL"`
+	Message string    `json:"message"`
+	Details any       `json:"details,omitempty"`
+}
+
+// WriteError responds with the error and the HTTP status code for its error code.
+func WriteError(c *gin.Context, err error) {
+	response := ErrorResponse{Code: Internal, Message: err.Error()}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		response.Code = apiErr.Code
+		response.Details = apiErr.Details
+	}
+	c.JSON(response.Code.Status(), response)
 }
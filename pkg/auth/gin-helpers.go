@@ -18,3 +18,16 @@ func CheckValidated(c *gin.Context) (string, bool) {
 	claims := user.(*CustomClaims)
 	return claims.RegisteredClaims.Subject, true
 }
+
+// TokenFromQuery copies an access token passed in the given query parameter into the Authorization header.
+// Browsers can't set headers on a WebSocket handshake so the token has to be passed in the URL instead.
+// It must run before EnsureValidTokenGin so the token is validated in the usual way.
+func TokenFromQuery(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query(param)
+		if token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
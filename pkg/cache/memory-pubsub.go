@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+type memorySubscriber[T any] struct {
+	messages chan T
+	done     <-chan struct{}
+}
+
+// MemoryPubSub is an in-process broker. It is useful in tests and when running a single instance without Redis.
+type MemoryPubSub[T any] struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*memorySubscriber[T]]struct{}
+}
+
+func NewMemoryPubSub[T any]() *MemoryPubSub[T] {
+	return &MemoryPubSub[T]{subscribers: make(map[string]map[*memorySubscriber[T]]struct{})}
+}
+
+func (p *MemoryPubSub[T]) Publish(channel string, message T) error {
+	// Take a copy of the subscribers so we aren't holding the lock while delivering
+	p.mu.RLock()
+	subscribers := make([]*memorySubscriber[T], 0, len(p.subscribers[channel]))
+	for sub := range p.subscribers[channel] {
+		subscribers = append(subscribers, sub)
+	}
+	p.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		select {
+		case sub.messages <- message:
+		case <-sub.done:
+		}
+	}
+	return nil
+}
+
+// Subscribe to a channel. The returned channel is closed when the context is cancelled.
+func (p *MemoryPubSub[T]) Subscribe(ctx context.Context, channel string) (<-chan T, error) {
+	sub := &memorySubscriber[T]{messages: make(chan T, 16), done: ctx.Done()}
+
+	p.mu.Lock()
+	if p.subscribers[channel] == nil {
+		p.subscribers[channel] = make(map[*memorySubscriber[T]]struct{})
+	}
+	p.subscribers[channel][sub] = struct{}{}
+	p.mu.Unlock()
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		defer func() {
+			p.mu.Lock()
+			delete(p.subscribers[channel], sub)
+			p.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-sub.messages:
+				select {
+				case out <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
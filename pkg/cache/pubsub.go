@@ -0,0 +1,10 @@
+package cache
+
+import (
+	"context"
+)
+
+type PubSub[T any] interface {
+	Publish(channel string, message T) error
+	Subscribe(ctx context.Context, channel string) (<-chan T, error)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"log"
+)
+
+type RedisPubSub[T any] struct {
+	client *redis.Client
+	ctx    context.Context
+}
+
+func NewRedisPubSub[T any](client *redis.Client, ctx context.Context) *RedisPubSub[T] {
+	return &RedisPubSub[T]{client: client, ctx: ctx}
+}
+
+func (p *RedisPubSub[T]) Publish(channel string, message T) error {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return p.client.Publish(p.ctx, channel, jsonData).Err()
+}
+
+// Subscribe to a channel. The returned channel is closed when the context is cancelled.
+func (p *RedisPubSub[T]) Subscribe(ctx context.Context, channel string) (<-chan T, error) {
+	sub := p.client.Subscribe(ctx, channel)
+
+	// Wait for confirmation that the subscription was created
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		defer func() {
+			if err := sub.Close(); err != nil {
+				log.Printf("Failed to close subscription to %s: %v", channel, err)
+			}
+		}()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var obj T
+				if err := json.Unmarshal([]byte(msg.Payload), &obj); err != nil {
+					log.Printf("Failed to decode message on %s: %v", channel, err)
+					continue
+				}
+				select {
+				case out <- obj:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
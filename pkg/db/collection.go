@@ -10,13 +10,18 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// duplicateKeyCode is the code of the error returned when inserting a document with an ID that already exists.
+const duplicateKeyCode = 11000
+
 type CollectionI[T any] interface {
 	FindOne(ctx context.Context, filter bson.M) (T, bool, error)
 	Find(ctx context.Context, filter bson.M) ([]T, error)
 	FindOneAndUpdate(ctx context.Context, filter bson.M, update bson.M) (T, error)
 	FindOneAndReplace(ctx context.Context, filter bson.M, replacement T) (T, error)
 	UpdateOne(ctx context.Context, t T, id string) error
+	ConditionalUpdateOne(ctx context.Context, t T, filter bson.M) (bool, error)
 	Upsert(ctx context.Context, t T, id string) error
+	InsertMany(ctx context.Context, ts []T) error
 	Aggregate(ctx context.Context, pipeline interface{}) (*mongo.Cursor, error)
 	DeleteOne(ctx context.Context, id string) error
 }
@@ -106,6 +111,17 @@ func (c *Collection[T]) UpdateOne(ctx context.Context, t T, id string) error {
 	return err
 }
 
+// ConditionalUpdateOne updates the document matching the filter.
+// Returns false if no document matched, e.g. because it was changed by someone else since it was read.
+func (c *Collection[T]) ConditionalUpdateOne(ctx context.Context, t T, filter bson.M) (bool, error) {
+	res, err := c.Col.UpdateOne(ctx, filter, bson.M{"$set": t})
+	if err != nil {
+		return false, err
+	}
+
+	return res.MatchedCount > 0, nil
+}
+
 func (c *Collection[T]) Upsert(ctx context.Context, t T, id string) error {
 
 	// Create filter
@@ -138,6 +154,46 @@ func (c *Collection[T]) Upsert(ctx context.Context, t T, id string) error {
 	return nil
 }
 
+// InsertMany inserts the documents unordered, so one that already exists doesn't stop the rest from being inserted.
+func (c *Collection[T]) InsertMany(ctx context.Context, ts []T) error {
+	docs := make([]interface{}, len(ts))
+	for i, t := range ts {
+		docs[i] = t
+	}
+
+	_, err := c.Col.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// IsDuplicateKey checks whether the only documents that couldn't be inserted were ones that already exist.
+func IsDuplicateKey(err error) bool {
+	var writeErrors mongo.WriteErrors
+	var writeConcernErr *mongo.WriteConcernError
+	var bulkErr mongo.BulkWriteException
+	var writeErr mongo.WriteException
+	switch {
+	case errors.As(err, &bulkErr):
+		for _, e := range bulkErr.WriteErrors {
+			writeErrors = append(writeErrors, e.WriteError)
+		}
+		writeConcernErr = bulkErr.WriteConcernError
+	case errors.As(err, &writeErr):
+		writeErrors = writeErr.WriteErrors
+		writeConcernErr = writeErr.WriteConcernError
+	default:
+		return false
+	}
+	if writeConcernErr != nil || len(writeErrors) == 0 {
+		return false
+	}
+	for _, e := range writeErrors {
+		if !e.HasErrorCode(duplicateKeyCode) {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *Collection[T]) Aggregate(ctx context.Context, pipeline interface{}) (*mongo.Cursor, error) {
 	return c.Col.Aggregate(ctx, pipeline)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type MockCollection[T any] struct {
@@ -16,6 +17,23 @@ type MockCollection[T any] struct {
 	MockUpsertErr     *[]error
 	MockUpdateOneErr  *[]error
 	MockDeleteOneErr  *[]error
+	// MockConditionalUpdateOneConflict models another writer getting there first, i.e. the filter not matching
+	MockConditionalUpdateOneConflict *[]bool
+	MockConditionalUpdateOneErr      *[]error
+	MockInsertManyErr                *[]error
+	// MockFindOneAndUpdateResult is the updated document, the zero value models the filter not matching
+	MockFindOneAndUpdateResult *[]T
+	// MockAggregateResult are the documents returned by each aggregation, as the pipeline isn't run
+	MockAggregateResult *[][]interface{}
+	MockAggregateErr    *[]error
+	// FindOneAndUpdateCalls records the updates made with FindOneAndUpdate
+	FindOneAndUpdateCalls []bson.M
+	// ConditionalUpdateOneCalls records the documents saved with ConditionalUpdateOne
+	ConditionalUpdateOneCalls []T
+	// InsertManyCalls records the documents inserted with InsertMany
+	InsertManyCalls [][]T
+	// AggregateCalls records the pipelines run with Aggregate
+	AggregateCalls []interface{}
 }
 
 func (m *MockCollection[T]) FindOne(ctx context.Context, filter bson.M) (T, bool, error) {
@@ -91,8 +109,51 @@ func (m *MockCollection[T]) UpdateOne(ctx context.Context, t T, id string) error
 	return err
 }
 
+func (m *MockCollection[T]) ConditionalUpdateOne(ctx context.Context, t T, filter bson.M) (bool, error) {
+	// Get the first element of the conflict array and remove it from the array, return false if the array is empty
+	var conflict bool
+	if m.MockConditionalUpdateOneConflict != nil && len(*m.MockConditionalUpdateOneConflict) > 0 {
+		conflict = (*m.MockConditionalUpdateOneConflict)[0]
+		*m.MockConditionalUpdateOneConflict = (*m.MockConditionalUpdateOneConflict)[1:]
+	}
+
+	// Get the first element of the error array and remove it from the array, return nil if the array is empty
+	var err error
+	if m.MockConditionalUpdateOneErr != nil && len(*m.MockConditionalUpdateOneErr) > 0 {
+		err = (*m.MockConditionalUpdateOneErr)[0]
+		*m.MockConditionalUpdateOneErr = (*m.MockConditionalUpdateOneErr)[1:]
+	}
+	if err != nil {
+		return false, err
+	}
+	if !conflict {
+		m.ConditionalUpdateOneCalls = append(m.ConditionalUpdateOneCalls, t)
+	}
+
+	return !conflict, nil
+}
+
+func (m *MockCollection[T]) InsertMany(ctx context.Context, ts []T) error {
+	// Get the first element of the error array and remove it from the array, return nil if the array is empty
+	var err error
+	if m.MockInsertManyErr != nil && len(*m.MockInsertManyErr) > 0 {
+		err = (*m.MockInsertManyErr)[0]
+		*m.MockInsertManyErr = (*m.MockInsertManyErr)[1:]
+	}
+	if err == nil {
+		m.InsertManyCalls = append(m.InsertManyCalls, ts)
+	}
+
+	return err
+}
+
 func (m *MockCollection[T]) FindOneAndUpdate(ctx context.Context, filter bson.M, update bson.M) (T, error) {
+	m.FindOneAndUpdateCalls = append(m.FindOneAndUpdateCalls, update)
 	var result T
+	if m.MockFindOneAndUpdateResult != nil && len(*m.MockFindOneAndUpdateResult) > 0 {
+		result = (*m.MockFindOneAndUpdateResult)[0]
+		*m.MockFindOneAndUpdateResult = (*m.MockFindOneAndUpdateResult)[1:]
+	}
 	return result, nil
 }
 
@@ -113,3 +174,23 @@ func (m *MockCollection[T]) DeleteOne(ctx context.Context, id string) error {
 
 	return err
 }
+
+func (m *MockCollection[T]) Aggregate(ctx context.Context, pipeline interface{}) (*mongo.Cursor, error) {
+	m.AggregateCalls = append(m.AggregateCalls, pipeline)
+
+	var err error
+	if m.MockAggregateErr != nil && len(*m.MockAggregateErr) > 0 {
+		err = (*m.MockAggregateErr)[0]
+		*m.MockAggregateErr = (*m.MockAggregateErr)[1:]
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]interface{}, 0)
+	if m.MockAggregateResult != nil && len(*m.MockAggregateResult) > 0 {
+		documents = (*m.MockAggregateResult)[0]
+		*m.MockAggregateResult = (*m.MockAggregateResult)[1:]
+	}
+	return mongo.NewCursorFromDocuments(documents, nil, nil)
+}
@@ -0,0 +1,402 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ISMCTS is a bot that uses information set Monte Carlo tree search.
+// Each iteration deals the cards the bot can't see at random, consistent with what it has seen, and plays out the rest
+// of the round. The move that does best across all the deals is chosen.
+type ISMCTS struct {
+	// Iterations is the maximum number of deals to simulate per move, there is no limit if it is 0.
+	Iterations int
+	// TimeLimit is the maximum time to spend on a move, there is no limit if it is 0.
+	TimeLimit time.Duration
+	// Exploration weighs trying moves that haven't been simulated much against the moves that have done best so far.
+	Exploration float64
+}
+
+// DefaultISMCTS is the budget used by MonteCarlo bots unless it is configured.
+var DefaultISMCTS = ISMCTS{Iterations: 2000, TimeLimit: 500 * time.Millisecond, Exploration: 0.7}
+
+// botMove is a move that can be made at any stage of a round.
+type botMove struct {
+	Call  Call
+	Suit  Suit
+	Cards []CardName
+	Card  CardName
+}
+
+func (m botMove) key() string {
+	return fmt.Sprint(m.Call, m.Suit, m.Cards, m.Card)
+}
+
+// searchNode is a move in the search tree along with how well it has done.
+type searchNode struct {
+	move     botMove
+	key      string
+	actor    string
+	visits   int
+	avail    int
+	reward   float64
+	children []*searchNode
+}
+
+func (n *searchNode) child(key string) *searchNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s ISMCTS) Call(state State) Call {
+	return s.search(state).Call
+}
+
+func (s ISMCTS) SelectSuit(state State) (Suit, []CardName) {
+	move := s.search(state)
+	return move.Suit, move.Cards
+}
+
+func (s ISMCTS) Buy(state State) []CardName {
+	return s.search(state).Cards
+}
+
+func (s ISMCTS) Play(state State) CardName {
+	return s.search(state).Card
+}
+
+// search finds the best move for the player within the budget.
+func (s ISMCTS) search(state State) botMove {
+	if state.Moves == nil {
+		return botMove{}
+	}
+	moves := candidates(state.Round.Status, state.Round.Suit, *state.Moves)
+	if len(moves) == 0 {
+		return botMove{}
+	}
+	if len(moves) == 1 {
+		return moves[0]
+	}
+
+	iterations := s.Iterations
+	if iterations == 0 && s.TimeLimit == 0 {
+		iterations = DefaultISMCTS.Iterations
+	}
+
+	rng := newRand(newSeed())
+	root := &searchNode{}
+	start := time.Now()
+	for i := 0; iterations == 0 || i < iterations; i++ {
+		if i > 0 && s.TimeLimit > 0 && time.Since(start) >= s.TimeLimit {
+			break
+		}
+		g := determinize(state, rng)
+		s.iterate(root, &g, rng)
+	}
+
+	// The most simulated move is the most reliable
+	best := moves[0]
+	visits := -1
+	for _, c := range root.children {
+		if c.visits > visits {
+			best = c.move
+			visits = c.visits
+		}
+	}
+	return best
+}
+
+// iterate simulates one deal to the end of the round, updating the tree with the result.
+func (s ISMCTS) iterate(root *searchNode, g *Game, rng *rand.Rand) {
+	round := g.CurrentRound.Number
+	before := make(map[string]int)
+	for _, p := range g.Players {
+		before[p.ID] = p.Score
+	}
+
+	// Follow the best moves down the tree until a move that hasn't been tried is found
+	var path []*searchNode
+	node := root
+	for !roundOver(g, round) {
+		actor := g.CurrentRound.CurrentHand.CurrentPlayerID
+		moves := legalBotMoves(g, actor)
+		if len(moves) == 0 {
+			return
+		}
+
+		// Only the moves that are possible in this deal are considered
+		var untried []botMove
+		var available []*searchNode
+		for _, m := range moves {
+			c := node.child(m.key())
+			if c == nil {
+				untried = append(untried, m)
+				continue
+			}
+			c.avail++
+			available = append(available, c)
+		}
+
+		expanded := len(untried) > 0
+		if expanded {
+			m := untried[rng.Intn(len(untried))]
+			node.children = append(node.children, &searchNode{move: m, key: m.key(), actor: actor, avail: 1})
+			node = node.children[len(node.children)-1]
+		} else {
+			node = s.selectChild(available)
+		}
+		if err := g.applyBotMove(actor, node.move); err != nil {
+			return
+		}
+		path = append(path, node)
+		if expanded {
+			break
+		}
+	}
+
+	// Play out the rest of the round
+	for !roundOver(g, round) {
+		actor := g.CurrentRound.CurrentHand.CurrentPlayerID
+		if err := g.applyBotMove(actor, rolloutMove(g, actor, rng)); err != nil {
+			return
+		}
+	}
+
+	// Each move is rewarded by how well the round went for the player who made it
+	for _, n := range path {
+		p, _ := g.Me(n.actor)
+		n.visits++
+		n.reward += math.Max(-1, math.Min(1, float64(p.Score-before[n.actor])/60))
+	}
+}
+
+// selectChild picks the move with the best upper confidence bound.
+func (s ISMCTS) selectChild(children []*searchNode) *searchNode {
+	var best *searchNode
+	bestScore := math.Inf(-1)
+	for _, c := range children {
+		if c.visits == 0 {
+			return c
+		}
+		score := c.reward/float64(c.visits) + s.Exploration*math.Sqrt(math.Log(float64(c.avail))/float64(c.visits))
+		if score > bestScore {
+			best = c
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// roundOver checks if the round being simulated has finished.
+func roundOver(g *Game, round int) bool {
+	return g.Status != Active || g.CurrentRound.Number != round
+}
+
+// candidates returns the moves worth considering from the legal moves.
+// There are too many ways to choose cards to keep, so only the strongest cards are considered.
+func candidates(status RoundStatus, suit Suit, moves Moves) []botMove {
+	var result []botMove
+	switch status {
+	case Calling:
+		for _, call := range moves.Calls {
+			result = append(result, botMove{Call: call})
+		}
+	case Called:
+		if moves.Keep == nil {
+			return nil
+		}
+		for _, s := range moves.Suits {
+			result = append(result, botMove{Suit: s, Cards: keepBest(moves.Keep.From, s, moves.Keep)})
+		}
+	case Buying:
+		if moves.Keep == nil {
+			return nil
+		}
+		// Try keeping from the minimum up to all the trumps
+		sorted := byStrength(moves.Keep.From, suit)
+		for n := moves.Keep.Min; n <= len(keepBest(moves.Keep.From, suit, moves.Keep)); n++ {
+			result = append(result, botMove{Cards: append([]CardName{}, sorted[:n]...)})
+		}
+	case Playing:
+		for _, card := range moves.Cards {
+			result = append(result, botMove{Card: card})
+		}
+	}
+	return result
+}
+
+// legalBotMoves returns the moves worth considering for the player.
+func legalBotMoves(g *Game, playerID string) []botMove {
+	moves, err := g.LegalMoves(playerID)
+	if err != nil {
+		return nil
+	}
+	return candidates(g.CurrentRound.Status, g.CurrentRound.Suit, moves)
+}
+
+// rolloutMove picks a move when playing out a round. The rule based bot makes the decisions other than which card to
+// play, as random calls and discards make for unrealistic rounds.
+func rolloutMove(g *Game, playerID string, rng *rand.Rand) botMove {
+	state := g.GetState(playerID)
+	if state.Moves == nil {
+		return botMove{}
+	}
+	switch g.CurrentRound.Status {
+	case Calling:
+		return botMove{Call: ruleBased{}.Call(state)}
+	case Called:
+		suit, cards := ruleBased{}.SelectSuit(state)
+		return botMove{Suit: suit, Cards: cards}
+	case Buying:
+		return botMove{Cards: ruleBased{}.Buy(state)}
+	}
+	if len(state.Moves.Cards) == 0 {
+		return botMove{}
+	}
+	return botMove{Card: state.Moves.Cards[rng.Intn(len(state.Moves.Cards))]}
+}
+
+// applyBotMove makes the move for the player.
+func (g *Game) applyBotMove(playerID string, m botMove) error {
+	switch g.CurrentRound.Status {
+	case Calling:
+		return g.Call(playerID, m.Call)
+	case Called:
+		return g.SelectSuit(playerID, m.Suit, m.Cards)
+	case Buying:
+		return g.Buy(playerID, m.Cards)
+	case Playing:
+		return g.Play(playerID, m.Card)
+	}
+	return fmt.Errorf("invalid round status")
+}
+
+// determinize deals the cards the player can't see at random, creating a game that is consistent with everything they
+// have seen. Only the player's own cards are used, everyone else's are ignored.
+func determinize(state State, rng *rand.Rand) Game {
+	round := state.Round
+	round.CurrentHand.PlayedCards = append([]PlayedCard{}, round.CurrentHand.PlayedCards...)
+	round.CompletedHands = append([]Hand{}, round.CompletedHands...)
+	hands := append(append([]Hand{}, round.CompletedHands...), round.CurrentHand)
+
+	// Find the cards the player hasn't seen
+	seen := make(map[CardName]bool)
+	for _, card := range state.Cards {
+		seen[card] = true
+	}
+	for _, hand := range hands {
+		for _, pc := range hand.PlayedCards {
+			seen[pc.Card] = true
+		}
+	}
+	unseen := make([]CardName, 0)
+	for _, card := range ShuffleCards(NewDeck(), rng) {
+		if !seen[card] {
+			unseen = append(unseen, card)
+		}
+	}
+
+	// Deal everyone else the cards they could be holding
+	voids := findVoids(hands, round.Suit)
+	players := make([]Player, len(state.Players))
+	for i, p := range state.Players {
+		if p.ID == state.Me.ID {
+			p.Cards = append([]CardName{}, state.Cards...)
+		} else {
+			played := 0
+			for _, hand := range hands {
+				for _, pc := range hand.PlayedCards {
+					if pc.PlayerID == p.ID {
+						played++
+					}
+				}
+			}
+			p.Cards, unseen = deal(unseen, 5-played, func(card CardName) bool {
+				return canHold(voids[p.ID], card, round.Suit)
+			})
+		}
+		players[i] = p
+	}
+
+	// The dummy is only in play until the goer picks it up
+	var dummy []CardName
+	if round.Status == Calling || (round.Status == Called && round.GoerID != state.Me.ID) {
+		dummy, unseen = deal(unseen, 5, nil)
+	}
+
+	rules := state.Rules
+	return Game{
+		ID:           state.ID,
+		Revision:     state.Revision,
+		Status:       state.Status,
+		Rules:        &rules,
+		Players:      players,
+		Dummy:        dummy,
+		Deck:         unseen,
+		CurrentRound: round,
+		quiet:        true,
+	}
+}
+
+// findVoids works out which suits each player can't have from the cards they played when they didn't follow suit.
+// A player that didn't follow trumps could still be holding trumps they're allowed to renege.
+func findVoids(hands []Hand, suit Suit) map[string]map[Suit]bool {
+	voids := make(map[string]map[Suit]bool)
+	for _, hand := range hands {
+		if hand.LeadOut == "" || len(hand.PlayedCards) == 0 {
+			continue
+		}
+		lead := hand.LeadOut.Card().Suit
+		if lead == Wild {
+			lead = suit
+		}
+		for _, pc := range hand.PlayedCards[1:] {
+			played := pc.Card.Card().Suit
+			if played == lead || (lead != suit && isTrump(pc.Card, suit)) || (lead == suit && played == Wild) {
+				continue
+			}
+			if voids[pc.PlayerID] == nil {
+				voids[pc.PlayerID] = make(map[Suit]bool)
+			}
+			voids[pc.PlayerID][lead] = true
+		}
+	}
+	return voids
+}
+
+// canHold checks if a player could be holding a card given the suits they are known not to have.
+func canHold(voids map[Suit]bool, card CardName, suit Suit) bool {
+	c := card.Card()
+	if isTrump(card, suit) {
+		return !voids[suit] || c.Renegable
+	}
+	return !voids[c.Suit]
+}
+
+// deal takes n cards from the pile, preferring cards that are allowed. If there aren't enough allowed cards the rest
+// are made up from the cards that aren't.
+func deal(pile []CardName, n int, allowed func(CardName) bool) ([]CardName, []CardName) {
+	if n <= 0 {
+		return nil, pile
+	}
+	hand := make([]CardName, 0, n)
+	rest := make([]CardName, 0, len(pile))
+	for _, card := range pile {
+		if len(hand) < n && (allowed == nil || allowed(card)) {
+			hand = append(hand, card)
+		} else {
+			rest = append(rest, card)
+		}
+	}
+	for len(hand) < n && len(rest) > 0 {
+		hand = append(hand, rest[0])
+		rest = rest[1:]
+	}
+	return hand, rest
+}
@@ -0,0 +1,144 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestISMCTS_playBot(t *testing.T) {
+	defer SetStrategy(MonteCarlo, DefaultISMCTS)
+	SetStrategy(MonteCarlo, ISMCTS{Iterations: 10, Exploration: 0.7})
+
+	tests := []struct {
+		name string
+		bots []BotType
+		seed int64
+	}{
+		{name: "Two bots", bots: []BotType{MonteCarlo, MonteCarlo}, seed: 1},
+		{name: "Against the rule based bot", bots: []BotType{MonteCarlo, RuleBased, RuleBased}, seed: 2},
+		{name: "Doubles", bots: []BotType{MonteCarlo, RuleBased, MonteCarlo, RuleBased, MonteCarlo, RuleBased}, seed: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			playerIDs, botIDs, err := addBots(nil, test.bots)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			g, err := NewGameWithSeed(playerIDs, "test", playerIDs[0], test.seed)
+			if err != nil {
+				t.Fatalf("failed to create game: %v", err)
+			}
+			for i, p := range g.Players {
+				g.Players[i].Bot = botIDs[p.ID]
+			}
+			g.quiet = true
+
+			g, _ = playBotsToEnd(t, g)
+			if g.Status != Completed {
+				t.Errorf("expected the game to be completed, got %s", g.Status)
+			}
+		})
+	}
+}
+
+func TestISMCTS_determinize(t *testing.T) {
+	game := PlayingGame_RoundStart_FirstCardPlayed()
+	// Player 1 didn't follow clubs in an earlier hand
+	game.CurrentRound.CompletedHands = []Hand{{
+		LeadOut:     TWO_CLUBS,
+		PlayedCards: []PlayedCard{{PlayerID: "2", Card: TWO_CLUBS}, {PlayerID: "1", Card: TWO_DIAMONDS}},
+	}}
+	game.Players[1].Cards = game.Players[1].Cards[:4]
+	rules, _ := NewRules(ShortGame)
+	game.Rules = &rules
+	state := game.GetState("2")
+	rng := newRand(1)
+
+	for i := 0; i < 100; i++ {
+		g := determinize(state, rng)
+
+		if g.rules().Preset != ShortGame {
+			t.Fatalf("expected the game's rules to be kept, got %s", g.rules().Preset)
+		}
+		me, _ := g.Me("2")
+		if !compare(me.Cards, state.Cards) {
+			t.Fatalf("expected my cards to be unchanged, got %v", me.Cards)
+		}
+		other, _ := g.Me("1")
+		if len(other.Cards) != 3 {
+			t.Fatalf("expected the other player to have 3 cards, got %d", len(other.Cards))
+		}
+		for _, card := range other.Cards {
+			if card.Card().Suit == Clubs && !card.Card().Renegable {
+				t.Fatalf("expected the other player not to have clubs, got %s", card)
+			}
+		}
+		if len(g.Dummy) != 0 {
+			t.Fatalf("expected no dummy once playing, got %v", g.Dummy)
+		}
+
+		// Every card is accounted for exactly once
+		all := append(append(append([]CardName{}, me.Cards...), other.Cards...), g.Deck...)
+		all = append(all, ACE_CLUBS, TWO_CLUBS, TWO_DIAMONDS)
+		if len(all) != len(NewDeck()) || !containsAllUnique(NewDeck(), all) {
+			t.Fatalf("expected every card to be dealt once, got %d cards", len(all))
+		}
+	}
+}
+
+func TestISMCTS_moves(t *testing.T) {
+	s := ISMCTS{Iterations: 20, Exploration: 0.7}
+	tests := []struct {
+		name     string
+		game     Game
+		playerID string
+	}{
+		{name: "Calling", game: TwoPlayerGame(), playerID: "2"},
+		{name: "Selecting suit", game: CalledGameThreePlayers(), playerID: "PlayerCalled"},
+		{name: "Buying", game: BuyingGame("1"), playerID: "2"},
+		{name: "Playing", game: PlayingGame_RoundStart_FirstCardPlayed(), playerID: "2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.game.quiet = true
+			state := test.game.GetState(test.playerID)
+
+			var err error
+			switch state.Round.Status {
+			case Calling:
+				err = test.game.Call(test.playerID, s.Call(state))
+			case Called:
+				suit, cards := s.SelectSuit(state)
+				err = test.game.SelectSuit(test.playerID, suit, cards)
+			case Buying:
+				err = test.game.Buy(test.playerID, s.Buy(state))
+			case Playing:
+				err = test.game.Play(test.playerID, s.Play(state))
+			}
+			if err != nil {
+				t.Errorf("expected a legal move, got %v", err)
+			}
+		})
+	}
+}
+
+func TestISMCTS_noMoves(t *testing.T) {
+	s := ISMCTS{Iterations: 20, Exploration: 0.7}
+	tests := []struct {
+		name  string
+		state State
+	}{
+		{name: "No moves", state: State{Round: Round{Status: Playing}}},
+		{name: "No cards to play", state: State{Round: Round{Status: Playing}, Moves: &Moves{}}},
+		{name: "No cards to keep", state: State{Round: Round{Status: Buying}, Moves: &Moves{}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if move := s.search(test.state); move.key() != (botMove{}).key() {
+				t.Errorf("expected no move, got %+v", move)
+			}
+		})
+	}
+}
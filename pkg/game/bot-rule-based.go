@@ -0,0 +1,143 @@
+package game
+
+import (
+	"sort"
+)
+
+// ruleBased is a simple bot that calls on the strength of its trumps, keeps its best trumps and plays the cheapest
+// card that wins the hand.
+type ruleBased struct{}
+
+// isTrump checks if a card is a trump for the given suit.
+func isTrump(card CardName, suit Suit) bool {
+	s := card.Card().Suit
+	return s == suit || s == Wild
+}
+
+// rank orders cards from weakest to strongest for the given trump suit.
+func rank(card CardName, suit Suit) int {
+	c := card.Card()
+	if isTrump(card, suit) {
+		return c.Value
+	}
+	return c.ColdValue
+}
+
+// strength is how good a set of cards is if the given suit is trumps.
+func strength(cards []CardName, suit Suit) int {
+	total := 0
+	for _, card := range cards {
+		if isTrump(card, suit) {
+			total += card.Card().Value - 100
+		}
+	}
+	return total
+}
+
+// bestSuit returns the suit the cards are strongest in.
+func bestSuit(cards []CardName, suits []Suit) (Suit, int) {
+	var best Suit
+	bestStrength := -1
+	for _, suit := range suits {
+		s := strength(cards, suit)
+		if s > bestStrength {
+			best = suit
+			bestStrength = s
+		}
+	}
+	return best, bestStrength
+}
+
+// byStrength sorts cards from strongest to weakest, trumps first.
+func byStrength(cards []CardName, suit Suit) []CardName {
+	sorted := append([]CardName{}, cards...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := isTrump(sorted[i], suit), isTrump(sorted[j], suit)
+		if ti != tj {
+			return ti
+		}
+		return rank(sorted[i], suit) > rank(sorted[j], suit)
+	})
+	return sorted
+}
+
+// keepBest keeps the strongest cards, all trumps up to the max and at least the min.
+func keepBest(cards []CardName, suit Suit, keep *Keep) []CardName {
+	kept := make([]CardName, 0)
+	for _, card := range byStrength(cards, suit) {
+		if len(kept) == keep.Max || (!isTrump(card, suit) && len(kept) >= keep.Min) {
+			break
+		}
+		kept = append(kept, card)
+	}
+	return kept
+}
+
+func (ruleBased) Call(state State) Call {
+	if state.Moves == nil {
+		return Pass
+	}
+
+	// Decide how much the hand is worth
+	_, s := bestSuit(state.Cards, []Suit{Clubs, Diamonds, Hearts, Spades})
+	var want Call
+	switch {
+	case s >= 55:
+		want = TwentyFive
+	case s >= 45:
+		want = Twenty
+	case s >= 30:
+		want = Fifteen
+	}
+
+	// Make the lowest call that is worth it, 10 is never worth calling
+	for _, call := range state.Moves.Calls {
+		if call > Ten && call <= want {
+			return call
+		}
+	}
+	return Pass
+}
+
+func (ruleBased) SelectSuit(state State) (Suit, []CardName) {
+	if state.Moves == nil || state.Moves.Keep == nil || len(state.Moves.Suits) == 0 {
+		return "", nil
+	}
+	suit, _ := bestSuit(state.Moves.Keep.From, state.Moves.Suits)
+	return suit, keepBest(state.Moves.Keep.From, suit, state.Moves.Keep)
+}
+
+func (ruleBased) Buy(state State) []CardName {
+	if state.Moves == nil || state.Moves.Keep == nil {
+		return nil
+	}
+	return keepBest(state.Moves.Keep.From, state.Round.Suit, state.Moves.Keep)
+}
+
+func (ruleBased) Play(state State) CardName {
+	if state.Moves == nil || len(state.Moves.Cards) == 0 {
+		return ""
+	}
+	suit := state.Round.Suit
+	cards := append([]CardName{}, state.Moves.Cards...)
+	sort.SliceStable(cards, func(i, j int) bool {
+		return rank(cards[i], suit) < rank(cards[j], suit)
+	})
+
+	// Lead out with the best card
+	hand := state.Round.CurrentHand
+	if hand.LeadOut == "" {
+		return cards[len(cards)-1]
+	}
+
+	// Otherwise play the cheapest card that wins the hand, or the cheapest card if none do
+	for _, card := range cards {
+		played := hand
+		played.PlayedCards = append(append([]PlayedCard{}, hand.PlayedCards...), PlayedCard{PlayerID: state.Me.ID, Card: card})
+		winner, err := findWinningCard(played, suit)
+		if err == nil && winner.PlayerID == state.Me.ID {
+			return card
+		}
+	}
+	return cards[0]
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"cards-110-api/pkg/api"
+	"fmt"
+	"strings"
+)
+
+type BotType string
+
+const (
+	RuleBased  BotType = "RULE_BASED"
+	MonteCarlo BotType = "MONTE_CARLO"
+)
+
+// Strategy decides the moves for a bot. Each method is given the bot's view of the game, which includes its legal moves.
+type Strategy interface {
+	Call(state State) Call
+	SelectSuit(state State) (Suit, []CardName)
+	Buy(state State) []CardName
+	Play(state State) CardName
+}
+
+// strategies are the strategies used for each type of bot.
+var strategies = map[BotType]Strategy{
+	RuleBased:  ruleBased{},
+	MonteCarlo: DefaultISMCTS,
+}
+
+// SetStrategy replaces the strategy used by a type of bot, e.g. to change how long it can think for.
+// It must only be called at start up, before any games are played.
+func SetStrategy(bot BotType, strategy Strategy) {
+	strategies[bot] = strategy
+}
+
+// botID returns the player ID for the nth bot of a given type in a game.
+func botID(bot BotType, n int) string {
+	return fmt.Sprintf("bot|%s-%d", strings.ToLower(string(bot)), n)
+}
+
+// addBots adds the bots to the players of a game.
+func addBots(playerIDs []string, bots []BotType) ([]string, map[string]BotType, error) {
+	botIDs := make(map[string]BotType)
+	for i, bot := range bots {
+		if _, ok := strategies[bot]; !ok {
+			return nil, nil, api.Errorf(api.InvalidRequest, "unknown bot type %s", bot)
+		}
+		id := botID(bot, i+1)
+		botIDs[id] = bot
+		playerIDs = append(playerIDs, id)
+	}
+	return playerIDs, botIDs, nil
+}
+
+// botsTurn checks if it's a bot's go.
+func (g *Game) botsTurn() bool {
+	if g.Status != Active {
+		return false
+	}
+	player, err := g.Me(g.CurrentRound.CurrentHand.CurrentPlayerID)
+	return err == nil && player.Bot != ""
+}
+
+// decideBotMove works out the move for the bot whose go it is, if it is a bot's go.
+// The move is returned rather than made so that a bot can think about its move without holding up the game.
+func (g *Game) decideBotMove() (func(g *Game) error, bool, error) {
+	if !g.botsTurn() {
+		return nil, false, nil
+	}
+	player, err := g.Me(g.CurrentRound.CurrentHand.CurrentPlayerID)
+	if err != nil {
+		return nil, false, err
+	}
+	strategy, ok := strategies[player.Bot]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown bot type %s", player.Bot)
+	}
+	state := g.GetState(player.ID)
+	if state.Moves == nil {
+		return nil, false, fmt.Errorf("bot %s has no legal moves", player.ID)
+	}
+
+	var move func(g *Game) error
+	switch g.CurrentRound.Status {
+	case Calling:
+		call := strategy.Call(state)
+		move = func(g *Game) error { return g.Call(player.ID, call) }
+	case Called:
+		suit, cards := strategy.SelectSuit(state)
+		move = func(g *Game) error { return g.SelectSuit(player.ID, suit, cards) }
+	case Buying:
+		cards := strategy.Buy(state)
+		move = func(g *Game) error { return g.Buy(player.ID, cards) }
+	case Playing:
+		card := strategy.Play(state)
+		move = func(g *Game) error { return g.Play(player.ID, card) }
+	default:
+		return nil, false, fmt.Errorf("invalid round status")
+	}
+
+	// An illegal move by a bot is a fault with the bot, so the move's error code isn't passed on
+	return func(g *Game) error {
+		err := move(g)
+		if err != nil {
+			return fmt.Errorf("bot %s failed to make a move: %v", player.ID, err)
+		}
+		return nil
+	}, true, nil
+}
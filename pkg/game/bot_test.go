@@ -0,0 +1,269 @@
+package game
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/cache"
+	"cards-110-api/pkg/db"
+	"context"
+	"reflect"
+	"testing"
+)
+
+// playBotsToEnd has the bot workers take every go in a game of bots until the game is over, returning the game and
+// its history.
+func playBotsToEnd(t *testing.T, g Game) (Game, []Event) {
+	snapshot, _ := g.snapshot()
+	g.record(Event{Type: EventGameCreated, Game: &snapshot})
+	events := g.takeEvents()
+
+	col := &db.MockCollection[Game]{
+		MockFindOneResult: &[]Game{},
+		MockFindOneExists: &[]bool{},
+		MockFindOneErr:    &[]error{},
+	}
+	history := &db.MockCollection[Event]{}
+	s := &Service{Col: col, History: history, Cache: &cache.MockCache[State]{MockSetErr: &[]error{}}}
+
+	// A game can't last anywhere near this many moves
+	for moves := 0; g.botsTurn(); moves++ {
+		if moves == 1000 {
+			t.Fatalf("expected the game to be over after %d moves", moves)
+		}
+		// The game is read once to decide the move and again to make it
+		*col.MockFindOneResult = []Game{g, g}
+		*col.MockFindOneExists = []bool{true, true}
+		err := s.playBot(context.Background(), g.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		g = col.ConditionalUpdateOneCalls[len(col.ConditionalUpdateOneCalls)-1]
+	}
+
+	for _, saved := range history.InsertManyCalls {
+		events = append(events, saved...)
+	}
+	return g, events
+}
+
+func TestBot_playBot(t *testing.T) {
+	tests := []struct {
+		name    string
+		numBots int
+		seed    int64
+	}{
+		{name: "Two bots", numBots: 2, seed: 1},
+		{name: "Three bots", numBots: 3, seed: 2},
+		{name: "Four bots", numBots: 4, seed: 3},
+		{name: "Five bots", numBots: 5, seed: 4},
+		{name: "Six bots", numBots: 6, seed: 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bots := make([]BotType, test.numBots)
+			for i := range bots {
+				bots[i] = RuleBased
+			}
+			playerIDs, botIDs, err := addBots(nil, bots)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			g, err := NewGameWithSeed(playerIDs, "test", playerIDs[0], test.seed)
+			if err != nil {
+				t.Fatalf("failed to create game: %v", err)
+			}
+			for i, p := range g.Players {
+				g.Players[i].Bot = botIDs[p.ID]
+			}
+
+			// With no humans the bots should play the game to the end
+			g, events := playBotsToEnd(t, g)
+			if g.Status != Completed {
+				t.Fatalf("expected the game to be completed, got %s", g.Status)
+			}
+
+			// Every move the bots made should be in the history
+			replayed, err := Replay(events)
+			if err != nil {
+				t.Fatalf("failed to replay game: %v", err)
+			}
+			if replayed.Revision != g.Revision {
+				t.Errorf("expected replayed revision %d, got %d", g.Revision, replayed.Revision)
+			}
+		})
+	}
+}
+
+func TestRuleBased_Call(t *testing.T) {
+	tests := []struct {
+		name     string
+		cards    []CardName
+		calls    []Call
+		expected Call
+	}{
+		{
+			name:     "Strong hand, calls as little as possible",
+			cards:    []CardName{FIVE_HEARTS, JACK_HEARTS, JOKER, ACE_HEARTS, KING_HEARTS},
+			calls:    []Call{Pass, Fifteen, Twenty, TwentyFive, Jink},
+			expected: Fifteen,
+		},
+		{
+			name:     "Strong hand, outbids the previous call",
+			cards:    []CardName{FIVE_HEARTS, JACK_HEARTS, JOKER, ACE_HEARTS, KING_HEARTS},
+			calls:    []Call{Pass, Twenty, TwentyFive, Jink},
+			expected: Twenty,
+		},
+		{
+			name:     "Weak hand",
+			cards:    []CardName{TWO_CLUBS, THREE_DIAMONDS, FOUR_SPADES, SIX_HEARTS, SEVEN_CLUBS},
+			calls:    []Call{Pass, Fifteen, Twenty, TwentyFive, Jink},
+			expected: Pass,
+		},
+		{
+			name:     "Call too high",
+			cards:    []CardName{FIVE_CLUBS, JACK_CLUBS, TWO_DIAMONDS, THREE_DIAMONDS, FOUR_SPADES},
+			calls:    []Call{Pass, TwentyFive, Jink},
+			expected: Pass,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := State{Cards: test.cards, Moves: &Moves{Calls: test.calls}}
+			call := ruleBased{}.Call(state)
+			if call != test.expected {
+				t.Errorf("expected call %d, got %d", test.expected, call)
+			}
+		})
+	}
+}
+
+func TestRuleBased_SelectSuit(t *testing.T) {
+	state := State{Moves: &Moves{
+		Suits: []Suit{Clubs, Diamonds, Hearts, Spades},
+		Keep: &Keep{
+			Min:  2,
+			Max:  5,
+			From: []CardName{TWO_CLUBS, FIVE_SPADES, JACK_SPADES, KING_HEARTS, SIX_DIAMONDS, JOKER, ACE_SPADES, TEN_CLUBS},
+		},
+	}}
+
+	suit, cards := ruleBased{}.SelectSuit(state)
+	if suit != Spades {
+		t.Errorf("expected suit %s, got %s", Spades, suit)
+	}
+	expected := []CardName{FIVE_SPADES, JACK_SPADES, JOKER, ACE_SPADES}
+	if !reflect.DeepEqual(cards, expected) {
+		t.Errorf("expected cards %v, got %v", expected, cards)
+	}
+}
+
+func TestRuleBased_Play(t *testing.T) {
+	tests := []struct {
+		name     string
+		hand     Hand
+		cards    []CardName
+		expected CardName
+	}{
+		{
+			name:     "Leads out with the best card",
+			cards:    []CardName{TWO_CLUBS, FIVE_HEARTS, KING_SPADES},
+			expected: FIVE_HEARTS,
+		},
+		{
+			name: "Plays the cheapest card that wins",
+			hand: Hand{
+				LeadOut:     KING_HEARTS,
+				PlayedCards: []PlayedCard{{PlayerID: "1", Card: KING_HEARTS}},
+			},
+			cards:    []CardName{FIVE_HEARTS, JACK_HEARTS, ACE_HEARTS},
+			expected: ACE_HEARTS,
+		},
+		{
+			name: "Plays the cheapest card when it can't win",
+			hand: Hand{
+				LeadOut:     FIVE_HEARTS,
+				PlayedCards: []PlayedCard{{PlayerID: "1", Card: FIVE_HEARTS}},
+			},
+			cards:    []CardName{JACK_HEARTS, TWO_HEARTS},
+			expected: TWO_HEARTS,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := State{
+				Me:    Player{ID: "2"},
+				Round: Round{Suit: Hearts, CurrentHand: test.hand},
+				Moves: &Moves{Cards: test.cards},
+			}
+			card := ruleBased{}.Play(state)
+			if card != test.expected {
+				t.Errorf("expected card %s, got %s", test.expected, card)
+			}
+		})
+	}
+}
+
+func TestRuleBased_NoMoves(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves *Moves
+	}{
+		{name: "No moves", moves: nil},
+		{name: "Empty moves", moves: &Moves{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := State{Me: Player{ID: "2"}, Moves: test.moves}
+			if call := (ruleBased{}).Call(state); call != Pass {
+				t.Errorf("expected a pass, got %d", call)
+			}
+			if suit, cards := (ruleBased{}).SelectSuit(state); suit != "" || cards != nil {
+				t.Errorf("expected no suit, got %s %v", suit, cards)
+			}
+			if cards := (ruleBased{}).Buy(state); cards != nil {
+				t.Errorf("expected no cards, got %v", cards)
+			}
+			if card := (ruleBased{}).Play(state); card != "" {
+				t.Errorf("expected no card, got %s", card)
+			}
+		})
+	}
+}
+
+// illegal is a strategy that only makes illegal moves.
+type illegal struct{}
+
+func (illegal) Call(State) Call                     { return Pass }
+func (illegal) SelectSuit(State) (Suit, []CardName) { return "", nil }
+func (illegal) Buy(State) []CardName                { return nil }
+func (illegal) Play(State) CardName                 { return "" }
+
+func TestBot_playBotIllegalMove(t *testing.T) {
+	const bot BotType = "ILLEGAL"
+	SetStrategy(bot, illegal{})
+	defer delete(strategies, bot)
+
+	game := PlayingGame_RoundStart_FirstCardPlayed()
+	for i := range game.Players {
+		game.Players[i].Bot = bot
+	}
+	s := &Service{
+		Col: &db.MockCollection[Game]{
+			MockFindOneResult: &[]Game{game, game},
+			MockFindOneExists: &[]bool{true, true},
+			MockFindOneErr:    &[]error{nil, nil},
+		},
+	}
+
+	// The bot's mistake is the server's fault, not the request's
+	err := s.playBot(context.Background(), game.ID)
+	if err == nil {
+		t.Fatalf("expected an error, got nil")
+	}
+	if code := api.CodeOf(err); code != api.Internal {
+		t.Errorf("expected an internal error, got %s (%v)", code, err)
+	}
+}
@@ -1,13 +1,15 @@
 package game
 
 import (
+	"cards-110-api/pkg/api"
 	"fmt"
 	"math/rand"
 )
 
-func ShuffleCards(cards []CardName) []CardName {
+// ShuffleCards shuffles the cards using the given source of randomness.
+func ShuffleCards(cards []CardName, rng *rand.Rand) []CardName {
 	shuffled := make([]CardName, len(cards))
-	perm := rand.Perm(len(cards))
+	perm := rng.Perm(len(cards))
 
 	for i, v := range perm {
 		shuffled[v] = cards[i]
@@ -223,7 +225,7 @@ func ParseCardName(s string) (CardName, error) {
 	case "JOKER":
 		return JOKER, nil
 	default:
-		return EMPTY_CARD, fmt.Errorf("invalid card name")
+		return EMPTY_CARD, api.Errorf(api.InvalidRequest, "invalid card name")
 	}
 }
 
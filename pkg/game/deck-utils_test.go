@@ -1,6 +1,9 @@
 package game
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestDeck_ShuffleCards(t *testing.T) {
 	tests := []struct {
@@ -23,7 +26,7 @@ func TestDeck_ShuffleCards(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			shuffled := ShuffleCards(test.cards)
+			shuffled := ShuffleCards(test.cards, newRand(newSeed()))
 			if len(shuffled) != len(test.cards) {
 				t.Errorf("expected %d cards, got %d", len(test.cards), len(shuffled))
 			}
@@ -49,6 +52,19 @@ func TestDeck_ShuffleCards(t *testing.T) {
 	}
 }
 
+func TestDeck_ShuffleCardsWithSeed(t *testing.T) {
+	first := ShuffleCards(NewDeck(), newRand(42))
+	second := ShuffleCards(NewDeck(), newRand(42))
+	other := ShuffleCards(NewDeck(), newRand(43))
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the same seed to produce the same order, got %v and %v", first, second)
+	}
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("expected different seeds to produce different orders, got %v", first)
+	}
+}
+
 func TestDeck_DealCards(t *testing.T) {
 	tests := []struct {
 		name        string
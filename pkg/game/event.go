@@ -0,0 +1,225 @@
+package game
+
+import (
+	"cards-110-api/pkg/api"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventGameCreated    EventType = "GAME_CREATED"
+	EventCalled                   = "CALLED"
+	EventSuitSelected             = "SUIT_SELECTED"
+	EventBought                   = "BOUGHT"
+	EventCardPlayed               = "CARD_PLAYED"
+	EventRoundCompleted           = "ROUND_COMPLETED"
+	EventGameCompleted            = "GAME_COMPLETED"
+)
+
+// ErrNotInHistory is returned when a game never reached the point being replayed to.
+var ErrNotInHistory = api.Errorf(api.NotFound, "the game never reached that point")
+
+// Point identifies a point in the history of a game.
+// If Round is set the point is the end of the given hand in that round, with hand 0 being the start of the round.
+// Otherwise, the point is the given revision.
+type Point struct {
+	Revision int
+	Round    int
+	Hand     int
+}
+
+// reached checks whether a game has got as far as the point.
+func (p Point) reached(g *Game) bool {
+	if p.Round == 0 {
+		return g.Revision >= p.Revision
+	}
+	if g.CurrentRound.Number != p.Round {
+		return g.CurrentRound.Number > p.Round
+	}
+	return len(g.CurrentRound.CompletedHands) >= p.Hand
+}
+
+// Deal is the cards dealt at the start of a round. Hands are in the same order as the players in the game.
+type Deal struct {
+	Hands [][]CardName `bson:"hands" json:"hands"`
+	Dummy []CardName   `bson:"dummy" json:"dummy"`
+	Deck  []CardName   `bson:"deck" json:"deck"`
+}
+
+// Event is an entry in the append-only log of everything that has happened in a game.
+// Revision is the revision of the game the event produced. A single move can produce several events,
+// e.g. the last card of a round produces EventCardPlayed then EventRoundCompleted, these are ordered by Sequence.
+type Event struct {
+	ID        string     `bson:"_id,omitempty" json:"id"`
+	GameID    string     `bson:"gameId" json:"gameId"`
+	Revision  int        `bson:"revision" json:"revision"`
+	Sequence  int        `bson:"sequence" json:"sequence"`
+	Type      EventType  `bson:"type" json:"type"`
+	Timestamp time.Time  `bson:"timestamp" json:"timestamp"`
+	PlayerID  string     `bson:"playerId,omitempty" json:"playerId,omitempty"`
+	Call      Call       `bson:"call,omitempty" json:"call,omitempty"`
+	Suit      Suit       `bson:"suit,omitempty" json:"suit,omitempty"`
+	Cards     []CardName `bson:"cards,omitempty" json:"cards,omitempty"`
+	Card      CardName   `bson:"card,omitempty" json:"card,omitempty"`
+	Round     int        `bson:"round,omitempty" json:"round,omitempty"`
+	Game      *Game      `bson:"game,omitempty" json:"-"`
+	Deal      *Deal      `bson:"deal,omitempty" json:"-"`
+}
+
+// record adds an event to the list of events produced by the current move.
+func (g *Game) record(event Event) {
+	event.GameID = g.ID
+	event.Timestamp = g.now()
+	g.events = append(g.events, event)
+}
+
+// now returns the time to use for anything that happens in the current move.
+func (g *Game) now() time.Time {
+	if g.clock != nil {
+		return g.clock()
+	}
+	return time.Now()
+}
+
+// recordCreated starts the game's history with the game as it was created.
+func (g *Game) recordCreated() error {
+	snapshot, err := g.snapshot()
+	if err != nil {
+		return err
+	}
+	g.record(Event{Type: EventGameCreated, Game: &snapshot})
+	return nil
+}
+
+// currentDeal returns the cards as they were dealt for the current round.
+func (g *Game) currentDeal() *Deal {
+	hands := make([][]CardName, len(g.Players))
+	for i, p := range g.Players {
+		hands[i] = append([]CardName{}, p.Cards...)
+	}
+	return &Deal{
+		Hands: hands,
+		Dummy: append([]CardName{}, g.Dummy...),
+		Deck:  append([]CardName{}, g.Deck...),
+	}
+}
+
+// snapshot returns a deep copy of the game as it would be persisted.
+func (g *Game) snapshot() (Game, error) {
+	var copied Game
+	data, err := bson.Marshal(g)
+	if err != nil {
+		return Game{}, err
+	}
+	err = bson.Unmarshal(data, &copied)
+	return copied, err
+}
+
+// sealEvents stamps the events produced since the last move with the current revision.
+// It must be called after each move when several moves are made before the events are taken.
+func (g *Game) sealEvents() {
+	for i := g.sealed; i < len(g.events); i++ {
+		g.events[i].Revision = g.Revision
+		g.events[i].Sequence = i - g.sealed
+		g.events[i].ID = fmt.Sprintf("%s-%d-%d", g.ID, g.Revision, i-g.sealed)
+	}
+	g.sealed = len(g.events)
+}
+
+// takeEvents returns the events produced since they were last taken, stamped with the revision they produced.
+func (g *Game) takeEvents() []Event {
+	g.sealEvents()
+	events := g.events
+	g.events = nil
+	g.sealed = 0
+	return events
+}
+
+// Replay rebuilds a game from its event log. The events must be in order and start with the EventGameCreated event.
+func Replay(events []Event) (Game, error) {
+	return replayUntil(events, func(g *Game) bool { return false })
+}
+
+// ReplayTo rebuilds a game from its event log as it stood at the given point.
+func ReplayTo(events []Event, at Point) (Game, error) {
+	game, err := replayUntil(events, at.reached)
+	if err != nil {
+		return Game{}, err
+	}
+	if !at.reached(&game) {
+		return Game{}, ErrNotInHistory
+	}
+	return game, nil
+}
+
+// replayUntil rebuilds a game from its event log, stopping at the first revision at which stop returns true.
+// If stop never returns true the game is returned as it is after the last event.
+func replayUntil(events []Event, stop func(g *Game) bool) (Game, error) {
+	if len(events) == 0 || events[0].Type != EventGameCreated || events[0].Game == nil {
+		return Game{}, fmt.Errorf("history must start with the game being created")
+	}
+
+	var game Game
+	for i, event := range events {
+		timestamp := event.Timestamp
+		game.clock = func() time.Time { return timestamp }
+
+		var err error
+		switch event.Type {
+		case EventGameCreated:
+			game, err = event.Game.snapshot()
+		case EventCalled:
+			err = game.Call(event.PlayerID, event.Call)
+		case EventSuitSelected:
+			err = game.SelectSuit(event.PlayerID, event.Suit, event.Cards)
+		case EventBought:
+			err = game.Buy(event.PlayerID, event.Cards)
+		case EventCardPlayed:
+			err = game.Play(event.PlayerID, event.Card)
+		case EventRoundCompleted:
+			// Re-deal the cards exactly as they were dealt at the time
+			err = game.applyDeal(event.Deal)
+		case EventGameCompleted:
+			if game.Status != Completed {
+				err = fmt.Errorf("game not complete")
+			}
+		default:
+			err = fmt.Errorf("unknown event type %s", event.Type)
+		}
+		// A move in the history that can't be replayed is a fault with the history rather than the request, so the
+		// move's error code isn't passed on
+		if err != nil {
+			return Game{}, fmt.Errorf("failed to replay %s at revision %d: %v", event.Type, event.Revision, err)
+		}
+		if game.Revision != event.Revision {
+			return Game{}, fmt.Errorf("history is incomplete, expected revision %d but got %d", event.Revision, game.Revision)
+		}
+
+		// Only stop once all the events of a revision have been applied
+		lastOfRevision := i == len(events)-1 || events[i+1].Revision != event.Revision
+		if lastOfRevision && stop(&game) {
+			break
+		}
+	}
+
+	game.clock = nil
+	game.events = nil
+	game.sealed = 0
+	return game, nil
+}
+
+// applyDeal replaces the cards of the current round with the given deal.
+func (g *Game) applyDeal(deal *Deal) error {
+	if deal == nil || len(deal.Hands) != len(g.Players) {
+		return fmt.Errorf("invalid deal")
+	}
+	for i := range g.Players {
+		g.Players[i].Cards = append([]CardName{}, deal.Hands[i]...)
+	}
+	g.Dummy = append([]CardName{}, deal.Dummy...)
+	g.Deck = append([]CardName{}, deal.Deck...)
+	return nil
+}
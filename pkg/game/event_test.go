@@ -0,0 +1,246 @@
+package game
+
+import (
+	"cards-110-api/pkg/api"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// playMove makes a valid move for the current player, recording the events in the history.
+func playMove(t *testing.T, g *Game, now time.Time, history *[]Event) {
+	g.clock = func() time.Time { return now }
+	playerID := g.CurrentRound.CurrentHand.CurrentPlayerID
+	state := g.GetState(playerID)
+
+	var err error
+	switch g.CurrentRound.Status {
+	case Calling:
+		// The first player calls every second round, everyone else passes
+		call := Pass
+		if g.CurrentRound.Number%2 == 0 && !state.IamDealer && state.MaxCall == Pass && state.Me.Score >= -30 {
+			call = Fifteen
+		}
+		err = g.Call(playerID, call)
+	case Called:
+		err = g.SelectSuit(playerID, Hearts, state.Cards[:5])
+	case Buying:
+		minKeep, _ := g.MinKeep()
+		err = g.Buy(playerID, state.Cards[:minKeep])
+	case Playing:
+		for _, card := range state.Cards {
+			if g.CurrentRound.CurrentHand.LeadOut == "" || isFollowing(card, state.Cards, g.CurrentRound.CurrentHand, g.CurrentRound.Suit) {
+				err = g.Play(playerID, card)
+				break
+			}
+		}
+	}
+	if err != nil {
+		t.Fatalf("failed to make a move in round %d: %v", g.CurrentRound.Number, err)
+	}
+
+	*history = append(*history, g.takeEvents()...)
+}
+
+// recordGame plays a game for up to the given number of moves, returning the game and its history.
+func recordGame(t *testing.T, playerIDs []string, moves int) (Game, []Event) {
+	g, err := NewGame(playerIDs, "test", "1")
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+	snapshot, err := g.snapshot()
+	if err != nil {
+		t.Fatalf("failed to snapshot game: %v", err)
+	}
+	g.record(Event{Type: EventGameCreated, Game: &snapshot})
+	history := g.takeEvents()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < moves && g.Status == Active; i++ {
+		now = now.Add(time.Minute)
+		playMove(t, &g, now, &history)
+	}
+	g.clock = nil
+	return g, history
+}
+
+func TestEvent_Replay(t *testing.T) {
+	tests := []struct {
+		name              string
+		playerIDs         []string
+		moves             int
+		expectedCompleted bool
+	}{
+		{
+			name:      "Two players, game in progress",
+			playerIDs: []string{"1", "2"},
+			moves:     40,
+		},
+		{
+			name:              "Three players, until the game is over",
+			playerIDs:         []string{"1", "2", "3"},
+			moves:             10000,
+			expectedCompleted: true,
+		},
+		{
+			name:              "Six players, until the game is over",
+			playerIDs:         []string{"1", "2", "3", "4", "5", "6"},
+			moves:             10000,
+			expectedCompleted: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g, history := recordGame(t, test.playerIDs, test.moves)
+			if test.expectedCompleted && g.Status != Completed {
+				t.Fatalf("expected game to be completed, got %s", g.Status)
+			}
+
+			replayed, err := Replay(history)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			expected, _ := g.snapshot()
+			actual, _ := replayed.snapshot()
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected replayed game to match\nexpected: %+v\nactual:   %+v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestEvent_ReplayInvalidHistory(t *testing.T) {
+	g := TwoPlayerGame()
+	snapshot, _ := g.snapshot()
+
+	tests := []struct {
+		name    string
+		history []Event
+	}{
+		{
+			name: "Empty history",
+		},
+		{
+			name:    "Doesn't start with the game being created",
+			history: []Event{{Type: EventCalled, PlayerID: "2", Call: Jink, Revision: 1}},
+		},
+		{
+			name: "Missing revision",
+			history: []Event{
+				{Type: EventGameCreated, Game: &snapshot},
+				{Type: EventCalled, PlayerID: "2", Call: Jink, Revision: 2},
+			},
+		},
+		{
+			name: "Invalid move",
+			history: []Event{
+				{Type: EventGameCreated, Game: &snapshot},
+				{Type: EventCalled, PlayerID: "1", Call: Jink, Revision: 1},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Replay(test.history)
+			if err == nil {
+				t.Fatalf("expected an error, got nil")
+			}
+			if code := api.CodeOf(err); code != api.Internal {
+				t.Errorf("expected an internal error, got %s (%v)", code, err)
+			}
+		})
+	}
+}
+
+func TestEvent_ReplayTo(t *testing.T) {
+	g, history := recordGame(t, []string{"1", "2", "3"}, 10000)
+
+	tests := []struct {
+		name           string
+		at             Point
+		expectedRound  int
+		expectedHands  int
+		expectedRev    int
+		expectingError bool
+	}{
+		{
+			name:          "Start of the game",
+			at:            Point{Revision: 0},
+			expectedRound: 1,
+			expectedRev:   0,
+		},
+		{
+			name:          "Revision",
+			at:            Point{Revision: 10},
+			expectedRound: 2,
+			expectedHands: 0,
+			expectedRev:   10,
+		},
+		{
+			name:          "Start of a round",
+			at:            Point{Round: 2},
+			expectedRound: 2,
+			expectedHands: 0,
+			expectedRev:   3,
+		},
+		{
+			name:          "Middle of a round",
+			at:            Point{Round: 2, Hand: 3},
+			expectedRound: 2,
+			expectedHands: 3,
+			expectedRev:   19,
+		},
+		{
+			name:          "End of a round",
+			at:            Point{Round: 2, Hand: 5},
+			expectedRound: 3,
+			expectedHands: 0,
+			expectedRev:   25,
+		},
+		{
+			name:          "End of the game",
+			at:            Point{Revision: g.Revision},
+			expectedRound: g.CurrentRound.Number,
+			expectedHands: 5,
+			expectedRev:   g.Revision,
+		},
+		{
+			name:           "Revision after the end of the game",
+			at:             Point{Revision: g.Revision + 1},
+			expectingError: true,
+		},
+		{
+			name:           "Round after the end of the game",
+			at:             Point{Round: g.CurrentRound.Number + 1},
+			expectingError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replayed, err := ReplayTo(history, test.at)
+			if test.expectingError {
+				if !errors.Is(err, ErrNotInHistory) {
+					t.Errorf("expected ErrNotInHistory, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if replayed.Revision != test.expectedRev {
+				t.Errorf("expected revision %d, got %d", test.expectedRev, replayed.Revision)
+			}
+			if replayed.CurrentRound.Number != test.expectedRound {
+				t.Errorf("expected round %d, got %d", test.expectedRound, replayed.CurrentRound.Number)
+			}
+			if len(replayed.CurrentRound.CompletedHands) != test.expectedHands {
+				t.Errorf("expected %d completed hands, got %d", test.expectedHands, len(replayed.CurrentRound.CompletedHands))
+			}
+		})
+	}
+}
@@ -4,21 +4,66 @@ import (
 	"cards-110-api/pkg/api"
 	"cards-110-api/pkg/auth"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type Handler struct {
 	S ServiceI
 }
 
+const (
+	// Time allowed to write a message to the client.
+	writeWait = 10 * time.Second
+	// Time allowed to read the next pong message from the client.
+	pongWait = 60 * time.Second
+	// Send pings to the client with this period. Must be less than pongWait.
+	pingPeriod = (pongWait * 9) / 10
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// The connection is authenticated with a bearer token rather than a cookie so any origin is allowed.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
 type CreateGameRequest struct {
+	PlayerIDs     []string    `json:"players"`
+	Bots          []BotType   `json:"bots"`
+	Rules         RulesPreset `json:"rules"`
+	TurnTimeLimit int         `json:"turnTimeLimit"`
+	Name          string      `json:"name"`
+}
+
+type CreateLobbyRequest struct {
+	Name          string      `json:"name"`
+	Rules         RulesPreset `json:"rules"`
+	TurnTimeLimit int         `json:"turnTimeLimit"`
+	Invited       []string    `json:"invited"`
+}
+
+// CreatedLobby is the lobby along with the code players can use to join it, which is only given to the admin.
+type CreatedLobby struct {
+	Game
+	JoinCode string `json:"joinCode"`
+}
+
+type InviteRequest struct {
 	PlayerIDs []string `json:"players"`
-	Name      string   `json:"name"`
+}
+
+type StartGameRequest struct {
+	Bots []BotType `json:"bots"`
 }
 
 // Create @Summary Create a new game
-// @Description Creates a new game with the given name and players
+// @Description Creates a new game with the given name and players. Any bots are seated alongside the players and make their moves automatically.
+// @Description The rules are one of HOUSE, CLASSIC or SHORT, house rules are used if none are given.
+// @Description If a turn time limit is given, a player who doesn't take their go within that many seconds has it taken for them.
 // @Tags Game
 // @ID create-game
 // @Accept json
@@ -42,14 +87,14 @@ func (h *Handler) Create(c *gin.Context) {
 	// Get the request body
 	var req CreateGameRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
 		return
 	}
 
 	// Create the game
-	game, err := h.S.Create(ctx, req.PlayerIDs, req.Name, id)
+	game, err := h.S.Create(ctx, req.PlayerIDs, req.Bots, req.Rules, req.TurnTimeLimit, req.Name, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 
@@ -65,6 +110,7 @@ func (h *Handler) Create(c *gin.Context) {
 // @Security Bearer
 // @Success 200 {object} Game
 // @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /game/{gameId} [get]
 func (h *Handler) Get(c *gin.Context) {
@@ -83,11 +129,11 @@ func (h *Handler) Get(c *gin.Context) {
 	// Get the game from the database
 	game, has, err := h.S.Get(ctx, gameId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 	if !has {
-		c.JSON(http.StatusNotFound, api.ErrorResponse{Message: "Game not found"})
+		api.WriteError(c, ErrNotFound)
 		return
 	}
 
@@ -105,6 +151,7 @@ func (h *Handler) Get(c *gin.Context) {
 // @Success 200 {object} State
 // @Success 204 "No Content"
 // @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /game/{gameId}/state [get]
 func (h *Handler) GetState(c *gin.Context) {
@@ -126,11 +173,11 @@ func (h *Handler) GetState(c *gin.Context) {
 	// Get the game from the database
 	state, has, err := h.S.GetState(ctx, gameId, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 	if !has {
-		c.JSON(http.StatusNotFound, api.ErrorResponse{Message: "Game not found"})
+		api.WriteError(c, ErrNotFound)
 		return
 	}
 
@@ -140,7 +187,7 @@ func (h *Handler) GetState(c *gin.Context) {
 		// If the revision is less than or equal to the current revision, return no content
 		rev, err := strconv.Atoi(revision)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: "Invalid revision"})
+			api.WriteError(c, api.Errorf(api.InvalidRequest, "Invalid revision"))
 			return
 		}
 		if state.Revision <= rev {
@@ -152,6 +199,189 @@ func (h *Handler) GetState(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, state)
 }
 
+// Replay @Summary Replay a game
+// @Description Returns the game as it stood at the given revision, or at the end of the given hand of a round (hand 0 is the start of the round).
+// @Description Once a game is completed it is returned in full, including everyone's cards. For active games only the current user's state is returned.
+// @Tags Game
+// @ID replay-game
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Param revision query int false "Revision"
+// @Param round query int false "Round number"
+// @Param hand query int false "Hand number, only with a round"
+// @Security Bearer
+// @Success 200 {object} Replayed
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /game/{gameId}/replay [get]
+func (h *Handler) Replay(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the game ID from the request
+	gameId := c.Param("gameId")
+
+	// Get the point to replay to from the request
+	var at Point
+	for param, value := range map[string]*int{"revision": &at.Revision, "round": &at.Round, "hand": &at.Hand} {
+		query, exists := c.GetQuery(param)
+		if !exists {
+			continue
+		}
+		v, err := strconv.Atoi(query)
+		if err != nil || v < 0 {
+			api.WriteError(c, api.Errorf(api.InvalidRequest, "Invalid %s", param))
+			return
+		}
+		*value = v
+	}
+	_, hasRevision := c.GetQuery("revision")
+	if hasRevision == (at.Round > 0) {
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "Provide either a revision or a round"))
+		return
+	}
+	_, hasHand := c.GetQuery("hand")
+	if hasRevision && hasHand {
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "A hand can only be given with a round"))
+		return
+	}
+	if at.Hand > 5 {
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "Invalid hand"))
+		return
+	}
+
+	// Replay the game
+	replayed, has, err := h.S.Replay(ctx, gameId, id, at)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	if !has {
+		api.WriteError(c, ErrNotFound)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, replayed)
+}
+
+// Subscribe @Summary Subscribe to the state of a game
+// @Description Upgrades the connection to a WebSocket and pushes the state of the game for the current user every time the game is revised.
+// @Description The access token can be passed in the access_token query parameter as browsers can't set headers on a WebSocket handshake.
+// @Tags Game
+// @ID subscribe-game-state
+// @Produce json
+// @Param gameId path string true "Game ID"
+// @Param access_token query string false "Access token"
+// @Security Bearer
+// @Success 101 {object} State
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /game/{gameId}/ws [get]
+func (h *Handler) Subscribe(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the game ID from the request
+	gameId := c.Param("gameId")
+
+	// Listen for revisions before getting the initial state so none are missed
+	events, unsubscribe := h.S.Subscribe(gameId)
+	defer unsubscribe()
+
+	// Make sure the game exists before upgrading the connection
+	state, has, err := h.S.GetState(ctx, gameId, id)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	if !has {
+		api.WriteError(c, ErrNotFound)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		log.Printf("Failed to upgrade connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Read from the connection so control messages are processed and we notice when the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Send the initial state
+	_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err := conn.WriteJSON(state); err != nil {
+		return
+	}
+	revision := state.Revision
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Revision <= revision {
+				continue
+			}
+			state, has, err := h.S.GetState(ctx, gameId, id)
+			if err != nil || !has {
+				log.Printf("Failed to get state for game %s: %v", gameId, err)
+				return
+			}
+			if state.Revision <= revision {
+				continue
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(state); err != nil {
+				return
+			}
+			revision = state.Revision
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
 // GetAll @Summary Get all games
 // @Description Returns all games
 // @Tags Game
@@ -175,21 +405,311 @@ func (h *Handler) GetAll(c *gin.Context) {
 	// Get all games from the database
 	games, err := h.S.GetAll(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 
 	c.IndentedJSON(http.StatusOK, games)
 }
 
+// CreateLobby @Summary Create a lobby
+// @Description Creates an open table for a game. Players can be invited or join with the join code, the game is dealt once the admin starts it.
+// @Description The rules are one of HOUSE, CLASSIC or SHORT, house rules are used if none are given.
+// @Description If a turn time limit is given, a player who doesn't take their go within that many seconds has it taken for them.
+// @Tags Lobby
+// @ID create-lobby
+// @Accept json
+// @Produce json
+// @Param lobby body CreateLobbyRequest true "Lobby"
+// @Security Bearer
+// @Success 200 {object} CreatedLobby
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /lobby [put]
+func (h *Handler) CreateLobby(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the request body
+	var req CreateLobbyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
+		return
+	}
+
+	// Create the lobby
+	game, err := h.S.CreateLobby(ctx, req.Name, req.Rules, req.TurnTimeLimit, id, req.Invited)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, CreatedLobby{Game: game, JoinCode: game.JoinCode})
+}
+
+// Invite @Summary Invite players
+// @Description Invites players to the lobby of the game with the given ID. Only the admin can invite players.
+// @Tags Lobby
+// @ID invite
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param gameId path string true "Game ID"
+// @Param players body InviteRequest true "Players"
+// @Success 200 {object} State
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /game/{gameId}/invite [put]
+func (h *Handler) Invite(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the game ID from the request
+	gameId := c.Param("gameId")
+
+	// Get the request body
+	var req InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
+		return
+	}
+
+	// Invite the players
+	game, err := h.S.Invite(ctx, gameId, id, req.PlayerIDs)
+
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	state := game.GetState(id)
+	c.IndentedJSON(http.StatusOK, state)
+}
+
+// Accept @Summary Accept an invitation
+// @Description Accepts the current user's invitation to the lobby of the game with the given ID
+// @Tags Lobby
+// @ID accept-invite
+// @Produce json
+// @Security Bearer
+// @Param gameId path string true "Game ID"
+// @Success 200 {object} State
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /game/{gameId}/accept [put]
+func (h *Handler) Accept(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the game ID from the request
+	gameId := c.Param("gameId")
+
+	// Accept the invitation
+	game, err := h.S.Accept(ctx, gameId, id)
+
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	state := game.GetState(id)
+	c.IndentedJSON(http.StatusOK, state)
+}
+
+// Decline @Summary Decline an invitation
+// @Description Declines the current user's invitation to the lobby of the game with the given ID
+// @Tags Lobby
+// @ID decline-invite
+// @Produce json
+// @Security Bearer
+// @Param gameId path string true "Game ID"
+// @Success 200 {object} State
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /game/{gameId}/decline [put]
+func (h *Handler) Decline(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the game ID from the request
+	gameId := c.Param("gameId")
+
+	// Decline the invitation
+	game, err := h.S.Decline(ctx, gameId, id)
+
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	state := game.GetState(id)
+	c.IndentedJSON(http.StatusOK, state)
+}
+
+// Leave @Summary Leave a lobby
+// @Description Removes the current user from the lobby of the game with the given ID
+// @Tags Lobby
+// @ID leave-lobby
+// @Produce json
+// @Security Bearer
+// @Param gameId path string true "Game ID"
+// @Success 200 {object} State
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /game/{gameId}/leave [put]
+func (h *Handler) Leave(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the game ID from the request
+	gameId := c.Param("gameId")
+
+	// Leave the lobby
+	game, err := h.S.Leave(ctx, gameId, id)
+
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	state := game.GetState(id)
+	c.IndentedJSON(http.StatusOK, state)
+}
+
+// Start @Summary Start a game
+// @Description Deals the game with the players that have joined the lobby, filling any remaining seats with bots. Only the admin can start the game.
+// @Tags Lobby
+// @ID start-game
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param gameId path string true "Game ID"
+// @Param bots body StartGameRequest true "Bots"
+// @Success 200 {object} State
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /game/{gameId}/start [put]
+func (h *Handler) Start(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the game ID from the request
+	gameId := c.Param("gameId")
+
+	// Get the request body
+	var req StartGameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
+		return
+	}
+
+	// Start the game
+	game, err := h.S.Start(ctx, gameId, id, req.Bots)
+
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	state := game.GetState(id)
+	c.IndentedJSON(http.StatusOK, state)
+}
+
+// Join @Summary Join a lobby
+// @Description Joins the lobby with the given join code, no invitation is needed
+// @Tags Lobby
+// @ID join-lobby
+// @Produce json
+// @Security Bearer
+// @Param code path string true "Join code"
+// @Success 200 {object} State
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /lobby/join/{code} [put]
+func (h *Handler) Join(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the join code from the request
+	code := c.Param("code")
+
+	// Join the lobby
+	game, err := h.S.Join(ctx, code, id)
+
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	state := game.GetState(id)
+	c.IndentedJSON(http.StatusOK, state)
+}
+
 // Delete @Summary Delete a game
-// @Description Deletes a game with the given ID
+// @Description Deletes a game with the given ID. Games that are part of a match or tournament can't be deleted.
 // @Tags Game
 // @ID delete-game
 // @Security Bearer
 // @Param gameId path string true "Game ID"
 // @Success 200
 // @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /game/{gameId} [delete]
 func (h *Handler) Delete(c *gin.Context) {
@@ -209,13 +729,51 @@ func (h *Handler) Delete(c *gin.Context) {
 	err := h.S.Delete(ctx, gameId, id)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
 
+// Rematch @Summary Rematch a completed game
+// @Description Creates a new game for the same table once the game with the given ID is over. Everyone keeps their seat, the rules stay the same and the first deal moves on to the next player.
+// @Description The games are linked by previousGameId and nextGameId so the series can be followed. Only the admin can create the rematch.
+// @Tags Game
+// @ID rematch-game
+// @Produce json
+// @Security Bearer
+// @Param gameId path string true "Game ID"
+// @Success 200 {object} Game
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /game/{gameId}/rematch [put]
+func (h *Handler) Rematch(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the game ID from the request
+	gameId := c.Param("gameId")
+
+	// Create the rematch
+	game, err := h.S.Rematch(ctx, gameId, id)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, game)
+}
+
 // Call @Summary Make a call
 // @Description Makes a call for the current user in the game with the given ID
 // @Tags Game
@@ -226,6 +784,9 @@ func (h *Handler) Delete(c *gin.Context) {
 // @Param call query int true "Call"
 // @Success 200 {object} State
 // @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /game/{gameId}/call [put]
 func (h *Handler) Call(c *gin.Context) {
@@ -244,13 +805,13 @@ func (h *Handler) Call(c *gin.Context) {
 	// Get the call from the request
 	ca, exists := c.GetQuery("call")
 	if !exists {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: "Missing call"})
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "Missing call"))
 		return
 	}
 	// Check if is a valid call
 	call, err := ParseCall(ca)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
 		return
 	}
 
@@ -258,7 +819,7 @@ func (h *Handler) Call(c *gin.Context) {
 	game, err := h.S.Call(ctx, gameId, id, call)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 	state := game.GetState(id)
@@ -280,6 +841,9 @@ type SelectSuitRequest struct {
 // @Para body SelectSuitRequest true "Select Suit Request"
 // @Success 200 {object} State
 // @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /game/{gameId}/suit [put]
 func (h *Handler) SelectSuit(c *gin.Context) {
@@ -298,7 +862,7 @@ func (h *Handler) SelectSuit(c *gin.Context) {
 	// Get the request body
 	var req SelectSuitRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
 		return
 	}
 
@@ -306,7 +870,7 @@ func (h *Handler) SelectSuit(c *gin.Context) {
 	game, err := h.S.SelectSuit(ctx, gameId, id, req.Suit, req.Cards)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 	state := game.GetState(id)
@@ -327,6 +891,9 @@ type BuyRequest struct {
 // @Para body BuyRequest true "Buy Request"
 // @Success 200 {object} State
 // @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /game/{gameId}/buy [put]
 func (h *Handler) Buy(c *gin.Context) {
@@ -345,7 +912,7 @@ func (h *Handler) Buy(c *gin.Context) {
 	// Get the request body
 	var req BuyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
 		return
 	}
 
@@ -353,7 +920,7 @@ func (h *Handler) Buy(c *gin.Context) {
 	game, err := h.S.Buy(ctx, gameId, id, req.Cards)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 	state := game.GetState(id)
@@ -362,6 +929,7 @@ func (h *Handler) Buy(c *gin.Context) {
 
 // Play @Summary Play a card
 // @Description When in the Playing state, the current player can play a card
+// @Description If the card doesn't follow suit the error details give the suit led, the trump suit and the cards that could have been played
 // @Tags Game
 // @ID play
 // @Produce json
@@ -370,6 +938,9 @@ func (h *Handler) Buy(c *gin.Context) {
 // @Param card query string true "Card"
 // @Success 200 {object} State
 // @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /game/{gameId}/play [put]
 func (h *Handler) Play(c *gin.Context) {
@@ -388,13 +959,13 @@ func (h *Handler) Play(c *gin.Context) {
 	// Get the card from the request
 	card, exists := c.GetQuery("card")
 	if !exists {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: "Missing card"})
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "Missing card"))
 		return
 	}
 	// Check if is a valid card
 	cn, err := ParseCardName(card)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
 		return
 	}
 
@@ -402,7 +973,7 @@ func (h *Handler) Play(c *gin.Context) {
 	game, err := h.S.Play(ctx, gameId, id, cn)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 	state := game.GetState(id)
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"cards-110-api/pkg/cache"
+	"context"
+	"sync"
+)
+
+// RevisedChannel is the channel revisions are published on so every instance of the API can forward them.
+const RevisedChannel = "game-revised"
+
+// Revised is the event published whenever a game moves to a new revision.
+type Revised struct {
+	GameID   string `json:"gameId"`
+	Revision int    `json:"revision"`
+}
+
+// Hub keeps track of the listeners interested in each game and notifies them when the game is revised.
+type Hub struct {
+	mu        sync.RWMutex
+	listeners map[string]map[chan Revised]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{listeners: make(map[string]map[chan Revised]struct{})}
+}
+
+// Subscribe registers a listener for a game.
+// The returned function must be called to remove the listener once it is no longer needed.
+func (h *Hub) Subscribe(gameID string) (<-chan Revised, func()) {
+	ch := make(chan Revised, 1)
+
+	h.mu.Lock()
+	if h.listeners[gameID] == nil {
+		h.listeners[gameID] = make(map[chan Revised]struct{})
+	}
+	h.listeners[gameID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.listeners[gameID][ch]; !ok {
+			return
+		}
+		delete(h.listeners[gameID], ch)
+		if len(h.listeners[gameID]) == 0 {
+			delete(h.listeners, gameID)
+		}
+		close(ch)
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish notifies all listeners of a game that it has been revised.
+// A listener that already has an event pending is skipped, it will pick up the latest state when it handles that event.
+func (h *Hub) Publish(event Revised) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.listeners[event.GameID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Listen forwards revisions published by any instance to the local listeners until the context is cancelled.
+func (h *Hub) Listen(ctx context.Context, events cache.PubSub[Revised]) error {
+	ch, err := events.Subscribe(ctx, RevisedChannel)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for event := range ch {
+			h.Publish(event)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"cards-110-api/pkg/cache"
+	"cards-110-api/pkg/db"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHub_Publish(t *testing.T) {
+	tests := []struct {
+		name          string
+		subscribeTo   string
+		publish       []Revised
+		expectedEvent bool
+		expected      Revised
+	}{
+		{
+			name:          "listener receives event for its game",
+			subscribeTo:   "1",
+			publish:       []Revised{{GameID: "1", Revision: 2}},
+			expectedEvent: true,
+			expected:      Revised{GameID: "1", Revision: 2},
+		},
+		{
+			name:        "listener ignores other games",
+			subscribeTo: "1",
+			publish:     []Revised{{GameID: "2", Revision: 2}},
+		},
+		{
+			name:          "publishing doesn't block when an event is already pending",
+			subscribeTo:   "1",
+			publish:       []Revised{{GameID: "1", Revision: 2}, {GameID: "1", Revision: 3}},
+			expectedEvent: true,
+			expected:      Revised{GameID: "1", Revision: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hub := NewHub()
+			events, unsubscribe := hub.Subscribe(test.subscribeTo)
+			defer unsubscribe()
+
+			for _, event := range test.publish {
+				hub.Publish(event)
+			}
+
+			select {
+			case event := <-events:
+				if !test.expectedEvent {
+					t.Errorf("expected no event, got %v", event)
+				}
+				if event != test.expected {
+					t.Errorf("expected event %v, got %v", test.expected, event)
+				}
+			default:
+				if test.expectedEvent {
+					t.Errorf("expected event %v, got none", test.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestHub_Unsubscribe(t *testing.T) {
+	hub := NewHub()
+	events, unsubscribe := hub.Subscribe("1")
+
+	unsubscribe()
+	// Calling it twice should be safe
+	unsubscribe()
+
+	if _, ok := <-events; ok {
+		t.Errorf("expected channel to be closed")
+	}
+	if len(hub.listeners) != 0 {
+		t.Errorf("expected no listeners, got %d", len(hub.listeners))
+	}
+
+	// Publishing with no listeners should be a no-op
+	hub.Publish(Revised{GameID: "1", Revision: 1})
+}
+
+func TestGameService_Subscribe(t *testing.T) {
+	ctx := context.Background()
+
+	mockCol := &db.MockCollection[Game]{
+		MockFindOneResult: &[]Game{TwoPlayerGame()},
+		MockFindOneExists: &[]bool{true},
+		MockFindOneErr:    &[]error{nil},
+	}
+	mockCache := &cache.MockCache[State]{
+		MockSetErr: &[]error{nil},
+	}
+	ds := &Service{
+		Col:   mockCol,
+		Cache: mockCache,
+		Hub:   NewHub(),
+	}
+
+	events, unsubscribe := ds.Subscribe(TwoPlayerGame().ID)
+	defer unsubscribe()
+
+	game, err := ds.Call(ctx, TwoPlayerGame().ID, "2", Jink)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.GameID != game.ID || event.Revision != game.Revision {
+			t.Errorf("expected event for revision %d, got %v", game.Revision, event)
+		}
+	default:
+		t.Errorf("expected an event to be published")
+	}
+}
+
+func TestHub_Listen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := cache.NewMemoryPubSub[Revised]()
+
+	// Two instances of the API sharing the same broker
+	hubA := NewHub()
+	hubB := NewHub()
+	if err := hubA.Listen(ctx, broker); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := hubB.Listen(ctx, broker); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	eventsA, unsubscribeA := hubA.Subscribe("1")
+	defer unsubscribeA()
+	eventsB, unsubscribeB := hubB.Subscribe("1")
+	defer unsubscribeB()
+
+	// A move handled by instance A
+	ds := &Service{
+		Col: &db.MockCollection[Game]{
+			MockFindOneResult: &[]Game{TwoPlayerGame()},
+			MockFindOneExists: &[]bool{true},
+			MockFindOneErr:    &[]error{nil},
+		},
+		Cache:  &cache.MockCache[State]{MockSetErr: &[]error{nil}},
+		Hub:    hubA,
+		Events: broker,
+	}
+	game, err := ds.Call(ctx, TwoPlayerGame().ID, "2", Jink)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for name, events := range map[string]<-chan Revised{"A": eventsA, "B": eventsB} {
+		select {
+		case event := <-events:
+			if event.GameID != game.ID || event.Revision != game.Revision {
+				t.Errorf("instance %s: expected event for revision %d, got %v", name, game.Revision, event)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("instance %s: expected an event to be forwarded", name)
+		}
+	}
+}
@@ -1,18 +1,48 @@
 package game
 
 import (
+	"cards-110-api/pkg/api"
 	"fmt"
 	"log"
-	"time"
+	"slices"
+	"strings"
 )
 
+// logf logs what is happening in the game unless it is a simulation.
+func (g *Game) logf(format string, v ...any) {
+	if !g.quiet {
+		log.Printf(format, v...)
+	}
+}
+
 func (g *Game) Me(playerID string) (Player, error) {
 	for _, p := range g.Players {
 		if p.ID == playerID {
 			return p, nil
 		}
 	}
-	return Player{}, fmt.Errorf("player not found in game")
+	return Player{}, api.Errorf(api.NotFound, "player not found in game")
+}
+
+// GetFullState returns the state of the game without hiding anyone's cards.
+func (g *Game) GetFullState() FullState {
+	players := make([]FullPlayer, len(g.Players))
+	for i, p := range g.Players {
+		players[i] = FullPlayer{Player: p, Cards: p.Cards}
+	}
+
+	return FullState{
+		ID:        g.ID,
+		Revision:  g.Revision,
+		Name:      g.Name,
+		Status:    g.Status,
+		Seed:      g.Seed,
+		Players:   players,
+		Dummy:     g.Dummy,
+		Deck:      g.Deck,
+		Round:     g.CurrentRound,
+		Completed: g.Completed,
+	}
 }
 
 func (g *Game) GetState(playerID string) State {
@@ -30,65 +60,134 @@ func (g *Game) GetState(playerID string) State {
 		}
 	}
 
-	// 3. Get player
+	// 3. The seed can only be revealed once the game is over, it would give away everyone's cards
+	var seed int64
+	if g.Status == Completed {
+		seed = g.Seed
+	}
+
+	// 4. Get player
 	me, err := g.Me(playerID)
 
 	// If the player isn't in the game they are a spectator
 	if err != nil {
 		return State{
-			ID:           g.ID,
-			Revision:     g.Revision,
-			IamSpectator: true,
-			Status:       g.Status,
-			Round:        g.CurrentRound,
-			PrevRound:    prevRound,
-			MaxCall:      maxCall,
-			Players:      g.Players,
+			ID:             g.ID,
+			Revision:       g.Revision,
+			IamSpectator:   true,
+			Status:         g.Status,
+			Round:          g.CurrentRound,
+			PrevRound:      prevRound,
+			MaxCall:        maxCall,
+			Players:        g.Players,
+			Seed:           seed,
+			Rules:          g.rules(),
+			PreviousGameID: g.PreviousGameID,
+			NextGameID:     g.NextGameID,
 		}
 	}
 
-	// 4. Add dummy if applicable
+	// 5. Add dummy if applicable
 	iamGoer := g.CurrentRound.GoerID == playerID
 	if iamGoer && g.CurrentRound.Status == Called && g.Dummy != nil {
 		me.Cards = append(me.Cards, g.Dummy...)
 	}
 
-	// 5. Return player's game state
+	// 6. Add the moves the player can make if it is their go
+	var moves *Moves
+	if g.Status == Active && g.CurrentRound.CurrentHand.CurrentPlayerID == me.ID {
+		legalMoves, errM := g.LegalMoves(playerID)
+		if errM == nil {
+			moves = &legalMoves
+		}
+	}
+
+	// 7. Return player's game state
 	gameState := State{
-		ID:           g.ID,
-		Revision:     g.Revision,
-		Me:           me,
-		IamSpectator: false,
-		IsMyGo:       g.CurrentRound.CurrentHand.CurrentPlayerID == me.ID,
-		IamGoer:      iamGoer,
-		IamDealer:    g.CurrentRound.DealerID == me.ID,
-		IamAdmin:     g.AdminID == me.ID,
-		Cards:        me.Cards,
-		Status:       g.Status,
-		Round:        g.CurrentRound,
-		PrevRound:    prevRound,
-		MaxCall:      maxCall,
-		Players:      g.Players,
+		ID:             g.ID,
+		Revision:       g.Revision,
+		Me:             me,
+		IamSpectator:   false,
+		IsMyGo:         g.CurrentRound.CurrentHand.CurrentPlayerID == me.ID,
+		IamGoer:        iamGoer,
+		IamDealer:      g.CurrentRound.DealerID == me.ID,
+		IamAdmin:       g.AdminID == me.ID,
+		Cards:          me.Cards,
+		Status:         g.Status,
+		Round:          g.CurrentRound,
+		PrevRound:      prevRound,
+		MaxCall:        maxCall,
+		Players:        g.Players,
+		Seed:           seed,
+		Rules:          g.rules(),
+		Moves:          moves,
+		PreviousGameID: g.PreviousGameID,
+		NextGameID:     g.NextGameID,
+	}
+	if gameState.IamAdmin && g.Status == Lobby {
+		gameState.JoinCode = g.JoinCode
 	}
 
 	return gameState
 }
 
+// LegalMoves returns the moves the player can make. It is an error to ask for the moves of a player when it isn't their go.
+func (g *Game) LegalMoves(playerID string) (Moves, error) {
+	// Validate the caller
+	err := g.validateCaller(playerID, g.CurrentRound.Status)
+	if err != nil {
+		return Moves{}, err
+	}
+	me, err := g.Me(playerID)
+	if err != nil {
+		return Moves{}, err
+	}
+	cards := append([]CardName{}, me.Cards...)
+
+	switch g.CurrentRound.Status {
+	case Calling:
+		calls := make([]Call, 0)
+		for _, call := range []Call{Pass, Ten, Fifteen, Twenty, TwentyFive, Jink} {
+			if g.validateCall(playerID, call) == nil {
+				calls = append(calls, call)
+			}
+		}
+		return Moves{Calls: calls}, nil
+	case Called:
+		minKeep, errM := g.MinKeep()
+		if errM != nil {
+			return Moves{}, errM
+		}
+		// The goer chooses from their own cards and the dummy
+		return Moves{
+			Suits: []Suit{Clubs, Diamonds, Hearts, Spades},
+			Keep:  &Keep{Min: minKeep, Max: 5, From: append(cards, g.Dummy...)},
+		}, nil
+	case Buying:
+		minKeep, errM := g.MinKeep()
+		if errM != nil {
+			return Moves{}, errM
+		}
+		return Moves{Keep: &Keep{Min: minKeep, Max: 5, From: cards}}, nil
+	case Playing:
+		return Moves{Cards: playableCards(cards, g.CurrentRound.CurrentHand, g.CurrentRound.Suit)}, nil
+	}
+	return Moves{}, fmt.Errorf("invalid round status")
+}
+
 // MinKeep returns the minimum number of cards that must be kept by a player
 func (g *Game) MinKeep() (int, error) {
-	switch len(g.Players) {
-	case 2:
-		return 0, nil
-	case 3:
-		return 0, nil
-	case 4:
-		return 0, nil
-	case 5:
-		return 1, nil
-	case 6:
-		return 2, nil
-	}
-	return 0, fmt.Errorf("invalid number of players")
+	minKeep := g.rules().MinKeep
+	if len(g.Players) < 2 || len(g.Players) >= len(minKeep) {
+		return 0, fmt.Errorf("invalid number of players")
+	}
+	return minKeep[len(g.Players)], nil
+}
+
+// setCurrentPlayer passes the go to a player, their time to take it starts now.
+func (g *Game) setCurrentPlayer(playerID string) {
+	g.CurrentRound.CurrentHand.CurrentPlayerID = playerID
+	g.CurrentRound.CurrentHand.Timestamp = g.now()
 }
 
 func (g *Game) completeHand() error {
@@ -98,16 +197,16 @@ func (g *Game) completeHand() error {
 		return err
 	}
 
-	log.Printf("Winning card: %s", winningCard.Card)
-	log.Printf("Current hand: %v", g.CurrentRound.CurrentHand)
-	log.Printf("Current suit: %s", g.CurrentRound.Suit)
+	g.logf("Winning card: %s", winningCard.Card)
+	g.logf("Current hand: %v", g.CurrentRound.CurrentHand)
+	g.logf("Current suit: %s", g.CurrentRound.Suit)
 
 	// 2. Add the hand to the completed hands
 	g.CurrentRound.CompletedHands = append(g.CurrentRound.CompletedHands, g.CurrentRound.CurrentHand)
 
 	// 3. Set the next hand
 	g.CurrentRound.CurrentHand = Hand{
-		Timestamp:       time.Now(),
+		Timestamp:       g.now(),
 		CurrentPlayerID: winningCard.PlayerID,
 		PlayedCards:     make([]PlayedCard, 0),
 	}
@@ -134,13 +233,19 @@ func (g *Game) completeRound() error {
 		return err2
 	}
 	nextHand := Hand{
-		Timestamp:       time.Now(),
+		Timestamp:       g.now(),
 		CurrentPlayerID: nextPlayer.ID,
 		PlayedCards:     make([]PlayedCard, 0),
 	}
+	// Games created before deals were seeded need a seed of their own
+	if g.Seed == 0 {
+		g.Seed = newSeed()
+	}
+	completed := g.CurrentRound.Number
 	g.CurrentRound = Round{
-		Timestamp:      time.Now(),
+		Timestamp:      g.now(),
 		Number:         g.CurrentRound.Number + 1,
+		Seed:           roundSeed(g.Seed, g.CurrentRound.Number+1),
 		DealerID:       nextDealer.ID,
 		Status:         Calling,
 		CurrentHand:    nextHand,
@@ -154,7 +259,7 @@ func (g *Game) completeRound() error {
 	}
 
 	// 5. Deal cards
-	deck, dummy, hands, err3 := DealCards(ShuffleCards(NewDeck()), len(g.Players))
+	deck, dummy, hands, err3 := DealCards(ShuffleCards(NewDeck(), newRand(g.CurrentRound.Seed)), len(g.Players))
 	if err3 != nil {
 		return err3
 	}
@@ -165,11 +270,13 @@ func (g *Game) completeRound() error {
 	g.Dummy = dummy
 	g.Deck = deck
 
+	g.record(Event{Type: EventRoundCompleted, Round: completed, Deal: g.currentDeal()})
+
 	return nil
 }
 
 func (g *Game) completeGame() error {
-	winningTeam, err := findWinningTeam(g.Players, g.CurrentRound)
+	winningTeam, err := findWinningTeam(g.Players, g.CurrentRound, g.rules().Target)
 	if err != nil {
 		return err
 	}
@@ -181,10 +288,31 @@ func (g *Game) completeGame() error {
 	}
 	g.Status = Completed
 
+	g.record(Event{Type: EventGameCompleted, Round: g.CurrentRound.Number})
+
 	return nil
 }
 
+// applyScores scores the round and records how each player's score changed.
 func (g *Game) applyScores() error {
+	before := make([]int, len(g.Players))
+	for i, p := range g.Players {
+		before[i] = p.Score
+	}
+
+	err := g.scoreRound()
+	if err != nil {
+		return err
+	}
+
+	g.CurrentRound.Scores = make([]RoundScore, len(g.Players))
+	for i, p := range g.Players {
+		g.CurrentRound.Scores[i] = RoundScore{PlayerID: p.ID, Points: p.Score - before[i], Total: p.Score}
+	}
+	return nil
+}
+
+func (g *Game) scoreRound() error {
 	// 1. Find winning card for each hand
 	winningCards, err := findWinningCardsForRound(g.CurrentRound)
 	if err != nil {
@@ -201,10 +329,10 @@ func (g *Game) applyScores() error {
 		if errT != nil {
 			return errT
 		}
-		// The successful team gets 60 points
+		// The successful team gets the points for a jink
 		for i, p := range g.Players {
 			if p.TeamID == teamId {
-				g.Players[i].Score += 60
+				g.Players[i].Score += g.rules().JinkPoints
 			}
 		}
 		return nil
@@ -243,7 +371,7 @@ func (g *Game) applyScores() error {
 
 func (g *Game) isGameOver() bool {
 	for _, p := range g.Players {
-		if p.Score >= 110 {
+		if p.Score >= g.rules().Target {
 			return true
 		}
 	}
@@ -253,22 +381,22 @@ func (g *Game) isGameOver() bool {
 func (g *Game) validateCaller(playerID string, desiredStatus RoundStatus) error {
 	// Check the game is active
 	if g.Status != Active {
-		return fmt.Errorf("game not active")
+		return api.Errorf(api.WrongPhase, "game not active")
 	}
 
 	// Check current round is calling
 	if g.CurrentRound.Status != desiredStatus {
-		return fmt.Errorf("round not calling")
+		return api.Errorf(api.WrongPhase, "round not %s", strings.ToLower(string(desiredStatus)))
 	}
 
 	// Ensure the playerID is valid
 	if playerID == "" {
-		return fmt.Errorf("invalid player ID")
+		return api.Errorf(api.Forbidden, "invalid player ID")
 	}
 
 	// Check the player is the current player
 	if g.CurrentRound.CurrentHand.CurrentPlayerID != playerID {
-		return fmt.Errorf("not current player")
+		return api.Errorf(api.NotYourTurn, "not current player")
 	}
 
 	// Check that the player has a valid teamID
@@ -283,40 +411,57 @@ func (g *Game) validateCaller(playerID string, desiredStatus RoundStatus) error
 	return nil
 }
 
-func (g *Game) Call(playerID string, call Call) error {
-	// Validate the caller
-	err := g.validateCaller(playerID, Calling)
+// validateCall checks the player is allowed to make the call. It doesn't check that it is the player's go.
+func (g *Game) validateCall(playerID string, call Call) error {
+	player, err := findPlayer(playerID, g.Players)
 	if err != nil {
 		return err
 	}
 
-	// If they are in the bunker (score < -30) they can only pass
-	state := g.GetState(playerID)
-	if state.Me.Score < -30 && call != Pass {
-		return fmt.Errorf("player in bunker")
+	// If they are in the bunker (score < -30 in house rules) they can only pass
+	rules := g.rules()
+	if player.Score < rules.Bunker && call != Pass {
+		return api.Errorf(api.IllegalMove, "player in bunker")
 	}
 
 	// Check the call is valid i.e. > all previous calls or a pass
 	// The dealer can take a call of greater than 10
 	if call != Pass {
 		callForComparison := call
-		if state.IamDealer {
+		if g.CurrentRound.DealerID == playerID {
 			callForComparison++
 		}
 		for _, p := range g.Players {
 			if p.Call >= callForComparison {
-				return fmt.Errorf("invalid call")
+				return api.Errorf(api.IllegalMove, "invalid call")
 			}
 		}
 	}
 
-	// Validate 10 call
+	// Validate 10 call, in house rules it is only allowed in doubles
 	if call == Ten {
-		if len(g.Players) != 6 {
-			return fmt.Errorf("can only call 10 in doubles")
+		if !slices.Contains(rules.TenCallPlayers, len(g.Players)) {
+			return api.Errorf(api.IllegalMove, "can't call 10 with %d players", len(g.Players))
 		}
 	}
 
+	return nil
+}
+
+func (g *Game) Call(playerID string, call Call) error {
+	// Validate the caller
+	err := g.validateCaller(playerID, Calling)
+	if err != nil {
+		return err
+	}
+
+	// Validate the call
+	err = g.validateCall(playerID, call)
+	if err != nil {
+		return err
+	}
+	state := g.GetState(playerID)
+
 	// Set the player's call
 	for i, p := range g.Players {
 		if p.ID == playerID {
@@ -324,18 +469,20 @@ func (g *Game) Call(playerID string, call Call) error {
 			break
 		}
 	}
+	g.CurrentRound.Calls = append(g.CurrentRound.Calls, PlayerCall{PlayerID: playerID, Call: call})
+	g.record(Event{Type: EventCalled, PlayerID: playerID, Call: call})
 
 	// Set next player/round status
 	if call == Jink {
-		log.Printf("Jink called by %s", playerID)
+		g.logf("Jink called by %s", playerID)
 		if state.IamDealer {
 			// If the dealer calls Jink, calling is complete
 			g.CurrentRound.Status = Called
 			g.CurrentRound.GoerID = playerID
-			g.CurrentRound.CurrentHand.CurrentPlayerID = playerID
+			g.setCurrentPlayer(playerID)
 		} else {
 			// If any other player calls Jink, jump to the dealer
-			g.CurrentRound.CurrentHand.CurrentPlayerID = g.CurrentRound.DealerID
+			g.setCurrentPlayer(g.CurrentRound.DealerID)
 		}
 	} else if state.IamDealer {
 		// Get the highest calls
@@ -347,7 +494,7 @@ func (g *Game) Call(playerID string, call Call) error {
 		}
 
 		if topCall <= Ten {
-			log.Printf("No one called. Starting new round...")
+			g.logf("No one called. Starting new round...")
 			err = g.completeRound()
 			g.Revision++
 			return err
@@ -378,35 +525,35 @@ func (g *Game) Call(playerID string, call Call) error {
 		}
 
 		if takenPlayer.ID != "" {
-			log.Printf("Dealer seeing call by %s", takenPlayer.ID)
+			g.logf("Dealer seeing call by %s", takenPlayer.ID)
 			g.CurrentRound.DealerSeeing = true
-			g.CurrentRound.CurrentHand.CurrentPlayerID = takenPlayer.ID
+			g.setCurrentPlayer(takenPlayer.ID)
 		} else {
-			log.Printf("Call successful. %s is goer", caller.ID)
+			g.logf("Call successful. %s is goer", caller.ID)
 			g.CurrentRound.Status = Called
 			g.CurrentRound.GoerID = caller.ID
-			g.CurrentRound.CurrentHand.CurrentPlayerID = caller.ID
+			g.setCurrentPlayer(caller.ID)
 		}
 
 	} else if g.CurrentRound.DealerSeeing {
-		log.Printf("%s was taken by the dealer.", playerID)
+		g.logf("%s was taken by the dealer.", playerID)
 		if call == Pass {
-			log.Printf("%s is letting the dealer go.", playerID)
+			g.logf("%s is letting the dealer go.", playerID)
 			g.CurrentRound.Status = Called
 			g.CurrentRound.GoerID = g.CurrentRound.DealerID
-			g.CurrentRound.CurrentHand.CurrentPlayerID = g.CurrentRound.DealerID
+			g.setCurrentPlayer(g.CurrentRound.DealerID)
 		} else {
-			log.Printf("%s has raised the call.", playerID)
-			g.CurrentRound.CurrentHand.CurrentPlayerID = g.CurrentRound.DealerID
+			g.logf("%s has raised the call.", playerID)
+			g.setCurrentPlayer(g.CurrentRound.DealerID)
 			g.CurrentRound.DealerSeeing = false
 		}
 	} else {
-		log.Printf("Calling not complete. Next player...")
+		g.logf("Calling not complete. Next player...")
 		nextPlayer, err := nextPlayer(g.Players, playerID)
 		if err != nil {
 			return err
 		}
-		g.CurrentRound.CurrentHand.CurrentPlayerID = nextPlayer.ID
+		g.setCurrentPlayer(nextPlayer.ID)
 	}
 
 	// Increment revision
@@ -418,7 +565,7 @@ func (g *Game) Call(playerID string, call Call) error {
 func (g *Game) SelectSuit(playerID string, suit Suit, cards []CardName) error {
 	// Validate suit
 	if !suit.isValid() {
-		return fmt.Errorf("invalid suit")
+		return api.Errorf(api.IllegalMove, "invalid suit")
 	}
 
 	// Validate the caller
@@ -433,15 +580,17 @@ func (g *Game) SelectSuit(playerID string, suit Suit, cards []CardName) error {
 		return errM
 	}
 	if len(cards) > 5 || len(cards) < minKeep {
-		return fmt.Errorf("invalid number of cards selected")
+		return api.Errorf(api.IllegalMove, "invalid number of cards selected")
 	}
 
 	// Verify the cards are valid (must be either in the player's hand or the dummy's hand and must be unique
 	state := g.GetState(playerID)
 	if !containsAllUnique(state.Cards, cards) {
-		return fmt.Errorf("invalid card selected")
+		return api.Errorf(api.IllegalMove, "invalid card selected")
 	}
 
+	g.record(Event{Type: EventSuitSelected, PlayerID: playerID, Suit: suit, Cards: append([]CardName{}, cards...)})
+
 	// Update the round
 	g.CurrentRound.Status = Buying
 	g.CurrentRound.Suit = suit
@@ -462,7 +611,7 @@ func (g *Game) SelectSuit(playerID string, suit Suit, cards []CardName) error {
 	if errP != nil {
 		return errP
 	}
-	g.CurrentRound.CurrentHand.CurrentPlayerID = np.ID
+	g.setCurrentPlayer(np.ID)
 
 	// Increment revision
 	g.Revision++
@@ -483,15 +632,17 @@ func (g *Game) Buy(playerID string, cards []CardName) error {
 		return errM
 	}
 	if len(cards) > 5 || len(cards) < minKeep {
-		return fmt.Errorf("invalid number of cards selected")
+		return api.Errorf(api.IllegalMove, "invalid number of cards selected")
 	}
 
 	// Verify the cards are valid (must be either in the player's hand or the dummy's hand and must be unique
 	state := g.GetState(playerID)
 	if !containsAllUnique(state.Cards, cards) {
-		return fmt.Errorf("invalid card selected")
+		return api.Errorf(api.IllegalMove, "invalid card selected")
 	}
 
+	g.record(Event{Type: EventBought, PlayerID: playerID, Cards: append([]CardName{}, cards...)})
+
 	// Get cards from the deck so the player has 5 cards
 	deck, cards, errC := BuyCards(g.Deck, cards)
 	if errC != nil {
@@ -517,14 +668,14 @@ func (g *Game) Buy(playerID string, cards []CardName) error {
 		if errP != nil {
 			return errP
 		}
-		g.CurrentRound.CurrentHand.CurrentPlayerID = np.ID
+		g.setCurrentPlayer(np.ID)
 	} else {
 		// Set the next player
 		np, errN := nextPlayer(g.Players, playerID)
 		if errN != nil {
 			return errN
 		}
-		g.CurrentRound.CurrentHand.CurrentPlayerID = np.ID
+		g.setCurrentPlayer(np.ID)
 	}
 
 	// Increment revision
@@ -533,21 +684,42 @@ func (g *Game) Buy(playerID string, cards []CardName) error {
 	return nil
 }
 
+// AutoBuy buys for a player who has chosen to always keep their trumps.
+func (g *Game) AutoBuy(playerID string) error {
+	moves, err := g.LegalMoves(playerID)
+	if err != nil {
+		return err
+	}
+	if moves.Keep == nil {
+		return api.Errorf(api.WrongPhase, "round not buying")
+	}
+	return g.Buy(playerID, keepBest(moves.Keep.From, g.CurrentRound.Suit, moves.Keep))
+}
+
+// forcedCard returns the card a player has to play if it is the only card they can play.
+func (g *Game) forcedCard(playerID string) (CardName, bool) {
+	moves, err := g.LegalMoves(playerID)
+	if err != nil || len(moves.Cards) != 1 {
+		return "", false
+	}
+	return moves.Cards[0], true
+}
+
 func (g *Game) Play(id string, card CardName) error {
 	// Verify the at the round is in the playing state
 	if g.CurrentRound.Status != Playing {
-		return fmt.Errorf("round must be in the playing state to play a card")
+		return api.Errorf(api.WrongPhase, "round must be in the playing state to play a card")
 	}
 
 	// Verify that is the player's go
 	if g.CurrentRound.CurrentHand.CurrentPlayerID != id {
-		return fmt.Errorf("only the current player can play a card")
+		return api.Errorf(api.NotYourTurn, "only the current player can play a card")
 	}
 
 	// Verify the card is valid
 	state := g.GetState(id)
 	if !contains(state.Cards, card) {
-		return fmt.Errorf("invalid card selected")
+		return api.Errorf(api.IllegalMove, "invalid card selected")
 	}
 
 	// Check that they are following suit
@@ -556,7 +728,8 @@ func (g *Game) Play(id string, card CardName) error {
 		g.CurrentRound.CurrentHand.LeadOut = card
 	} else {
 		if !isFollowing(card, state.Cards, g.CurrentRound.CurrentHand, g.CurrentRound.Suit) {
-			return fmt.Errorf("must follow suit")
+			violation := followSuitViolation(state.Cards, g.CurrentRound.CurrentHand, g.CurrentRound.Suit)
+			return api.ErrorWithDetails(api.IllegalMove, violation, "must follow suit")
 		}
 	}
 
@@ -574,6 +747,8 @@ func (g *Game) Play(id string, card CardName) error {
 		}
 	}
 
+	g.record(Event{Type: EventCardPlayed, PlayerID: id, Card: card})
+
 	// Add the card to the played cards
 	pc := PlayedCard{
 		PlayerID: id,
@@ -588,7 +763,7 @@ func (g *Game) Play(id string, card CardName) error {
 		if err != nil {
 			return err
 		}
-		g.CurrentRound.CurrentHand.CurrentPlayerID = np.ID
+		g.setCurrentPlayer(np.ID)
 	} else {
 
 		err := g.completeHand()
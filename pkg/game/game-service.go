@@ -1,17 +1,53 @@
 package game
 
 import (
+	"cards-110-api/pkg/api"
 	"cards-110-api/pkg/cache"
 	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/settings"
 	"context"
 	"errors"
+	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 	"log"
+	"sort"
 	"time"
 )
 
+// ErrNotFound is returned when a game doesn't exist.
+var ErrNotFound = api.Errorf(api.NotFound, "game not found")
+
+// ErrConflict is returned when a game couldn't be saved because it kept being updated by other requests.
+var ErrConflict = api.Errorf(api.Conflict, "game was updated by another player, please try again")
+
+// ErrNoHistory is returned when replaying a game that has no history, e.g. one created before history was recorded.
+var ErrNoHistory = api.Errorf(api.NotFound, "no history found for game")
+
+// ErrHistoryIncomplete is returned when replaying a game whose history couldn't all be saved.
+var ErrHistoryIncomplete = api.Errorf(api.Internal, "history is incomplete for game")
+
+// maxUpdateAttempts is the number of times a move is attempted before giving up because of concurrent updates.
+const maxUpdateAttempts = 3
+
+// botQueueSize is the number of games that can be waiting for a bot to take its go.
+// Any more are left for the turn timer to pick up.
+const botQueueSize = 100
+
+// botStallTime is how long a bot can go without taking its go before the turn timer makes the move for it, e.g.
+// because the queue was full or the server restarted.
+const botStallTime = 30 * time.Second
+
+// errBotMoveStale is returned when a game changes while a bot is deciding its move.
+var errBotMoveStale = errors.New("game changed while the bot was deciding its move")
+
+// completedTimeout is how long the hooks have to handle the completion of a game.
+const completedTimeout = time.Minute
+
+// maxHistoryAttempts is the number of times the events of a move are saved before the game's history is given up on.
+const maxHistoryAttempts = 3
+
 type ServiceI interface {
-	Create(ctx context.Context, playerIDs []string, name string, adminID string) (Game, error)
+	Create(ctx context.Context, playerIDs []string, bots []BotType, rules RulesPreset, turnTimeLimit int, name string, adminID string) (Game, error)
 	Get(ctx context.Context, gameId string) (Game, bool, error)
 	GetState(ctx context.Context, gameId string, playerId string) (State, bool, error)
 	GetAll(ctx context.Context) ([]Game, error)
@@ -20,11 +56,61 @@ type ServiceI interface {
 	SelectSuit(ctx context.Context, gameId string, playerId string, suit Suit, cards []CardName) (Game, error)
 	Buy(ctx context.Context, gameId string, playerId string, cards []CardName) (Game, error)
 	Play(ctx context.Context, gameId string, playerId string, card CardName) (Game, error)
+	Subscribe(gameId string) (<-chan Revised, func())
+	GetHistory(ctx context.Context, gameId string) ([]Event, error)
+	Replay(ctx context.Context, gameId string, playerId string, at Point) (Replayed, bool, error)
+	CreateLobby(ctx context.Context, name string, rules RulesPreset, turnTimeLimit int, adminID string, invited []string) (Game, error)
+	Invite(ctx context.Context, gameId string, adminId string, playerIDs []string) (Game, error)
+	Accept(ctx context.Context, gameId string, playerId string) (Game, error)
+	Decline(ctx context.Context, gameId string, playerId string) (Game, error)
+	Join(ctx context.Context, code string, playerId string) (Game, error)
+	Leave(ctx context.Context, gameId string, playerId string) (Game, error)
+	Start(ctx context.Context, gameId string, adminId string, bots []BotType) (Game, error)
+	Rematch(ctx context.Context, gameId string, adminId string) (Game, error)
 }
 
+// CompletedHook is called once a game has been saved as completed.
+type CompletedHook func(ctx context.Context, game Game) error
+
+// DeleteGuard is called before a game is deleted, returning an error if something depends on the game.
+type DeleteGuard func(ctx context.Context, game Game) error
+
 type Service struct {
-	Col   db.CollectionI[Game]
-	Cache cache.Cache[State]
+	Col      db.CollectionI[Game]
+	History  db.CollectionI[Event]
+	Cache    cache.Cache[State]
+	Hub      *Hub
+	Events   cache.PubSub[Revised]
+	Settings settings.ServiceI
+
+	completedHooks []CompletedHook
+	deleteGuards   []DeleteGuard
+	// bots is the queue of games where it's a bot's go, see RunBots
+	bots chan string
+}
+
+// OnCompleted registers a hook to be called whenever a game is completed.
+func (s *Service) OnCompleted(hook CompletedHook) {
+	s.completedHooks = append(s.completedHooks, hook)
+}
+
+// OnDelete registers a guard to be checked whenever a game is about to be deleted.
+func (s *Service) OnDelete(guard DeleteGuard) {
+	s.deleteGuards = append(s.deleteGuards, guard)
+}
+
+// completed lets the hooks know a game has been completed.
+// The game has already been saved at this point so failures are logged rather than returned. The hooks aren't cancelled
+// along with the request that completed the game, e.g. if the player goes away, as nothing else would run them.
+func (s *Service) completed(ctx context.Context, game Game) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), completedTimeout)
+	defer cancel()
+	for _, hook := range s.completedHooks {
+		err := hook(ctx, game)
+		if err != nil {
+			log.Printf("Failed to handle the completion of game %s: %v", game.ID, err)
+		}
+	}
 }
 
 func getCacheKey(gameId string, playerId string) string {
@@ -43,8 +129,232 @@ func (s *Service) updateStateCache(game Game) error {
 	return nil
 }
 
+// notify lets any listeners know the game has been revised.
+// When a broker is configured the revision is published to every instance, otherwise only local listeners are notified.
+func (s *Service) notify(game Game) {
+	event := Revised{GameID: game.ID, Revision: game.Revision}
+	if s.Events != nil {
+		err := s.Events.Publish(RevisedChannel, event)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to publish revision %d of game %s: %v", event.Revision, event.GameID, err)
+	}
+	if s.Hub != nil {
+		s.Hub.Publish(event)
+	}
+}
+
+// Subscribe to revisions of a game.
+func (s *Service) Subscribe(gameId string) (<-chan Revised, func()) {
+	return s.Hub.Subscribe(gameId)
+}
+
+// update applies a move to the latest revision of a game and saves it.
+// The save only succeeds if no one else has saved a new revision since the game was read.
+// If they have, the move is applied again to the new revision. ErrConflict is returned if the game still can't be
+// saved after maxUpdateAttempts.
+func (s *Service) update(ctx context.Context, gameId string, move func(game *Game) error) (Game, error) {
+	for attempt := 1; attempt <= maxUpdateAttempts; attempt++ {
+		// Get the game from the database.
+		game, has, err := s.Get(ctx, gameId)
+		if err != nil {
+			return Game{}, err
+		}
+		if !has {
+			return Game{}, ErrNotFound
+		}
+
+		// Make the move. Everything that happens in the move shares the same timestamp.
+		now := time.Now()
+		game.clock = func() time.Time { return now }
+		revision := game.Revision
+		status := game.Status
+		err = move(&game)
+		if err != nil {
+			return Game{}, err
+		}
+
+		// Let any bots or players who have chosen to buy automatically take their go.
+		err = s.playAutomatically(ctx, &game)
+		if err != nil {
+			return Game{}, err
+		}
+		events := game.takeEvents()
+
+		// Save the game to the database if it hasn't been changed in the meantime.
+		saved, err := s.Col.ConditionalUpdateOne(ctx, game, bson.M{"_id": game.ID, "revision": revision})
+		if err != nil {
+			return Game{}, err
+		}
+		if !saved {
+			log.Printf("Game %s was updated concurrently at revision %d (attempt %d)", gameId, revision, attempt)
+			continue
+		}
+
+		// Append what happened to the game's history.
+		s.saveHistory(ctx, events)
+
+		// Update the state cache for all players in the game.
+		// The move has already been saved, so a failure is logged rather than stopping the rest of the move.
+		errC := s.updateStateCache(game)
+		if errC != nil {
+			log.Printf("Failed to update the state cache for game %s: %v", game.ID, errC)
+		}
+
+		// Notify any listeners of the new revision.
+		s.notify(game)
+
+		// Let the bots know if it's their go.
+		s.queueBots(game)
+
+		if status != Completed && game.Status == Completed {
+			s.completed(ctx, game)
+		}
+
+		return game, nil
+	}
+
+	return Game{}, ErrConflict
+}
+
+// playAutomatically makes the moves for players who buy automatically and for players who play forced cards
+// automatically, until someone has to make a move themselves. Bots take their go separately, see RunBots.
+func (s *Service) playAutomatically(ctx context.Context, game *Game) error {
+	checked := make(map[string]settings.Settings)
+	for {
+		if game.Status != Active || (game.CurrentRound.Status != Buying && game.CurrentRound.Status != Playing) {
+			return nil
+		}
+		if game.botsTurn() {
+			return nil
+		}
+		// Keep the events of the previous move separate from this one
+		game.sealEvents()
+
+		// Check the player's settings once per move
+		var err error
+		playerID := game.CurrentRound.CurrentHand.CurrentPlayerID
+		playerSettings, ok := checked[playerID]
+		if !ok {
+			playerSettings, err = s.playerSettings(ctx, playerID)
+			if err != nil {
+				return err
+			}
+			checked[playerID] = playerSettings
+		}
+
+		switch game.CurrentRound.Status {
+		case Buying:
+			// The goer has already chosen their cards along with the suit
+			if !playerSettings.AutoBuy || playerID == game.CurrentRound.GoerID {
+				return nil
+			}
+			err = game.AutoBuy(playerID)
+		case Playing:
+			card, forced := game.forcedCard(playerID)
+			if !playerSettings.AutoPlay || !forced {
+				return nil
+			}
+			err = game.Play(playerID, card)
+		}
+		// The move was chosen for the player rather than by the request, so the move's error code isn't passed on
+		if err != nil {
+			return fmt.Errorf("failed to take the go of player %s automatically: %v", playerID, err)
+		}
+	}
+}
+
+// playerSettings gets a player's settings. Players who haven't saved any settings get the defaults.
+func (s *Service) playerSettings(ctx context.Context, playerID string) (settings.Settings, error) {
+	if s.Settings == nil {
+		return settings.Settings{ID: playerID}, nil
+	}
+	playerSettings, has, err := s.Settings.Get(ctx, playerID)
+	if err != nil {
+		return settings.Settings{}, err
+	}
+	if !has {
+		return settings.Default(playerID), nil
+	}
+	return playerSettings, nil
+}
+
+// saveHistory appends the events produced by a move to the game's history.
+// The move has already been saved at this point so failures are retried rather than returned. Events have fixed IDs,
+// so any saved by an earlier attempt are skipped. If the events still can't be saved the game's history is marked as
+// incomplete, so that it is never replayed with a gap in it.
+func (s *Service) saveHistory(ctx context.Context, events []Event) {
+	if s.History == nil || len(events) == 0 {
+		return
+	}
+	gameID := events[0].GameID
+	for attempt := 1; attempt <= maxHistoryAttempts; attempt++ {
+		err := s.History.InsertMany(ctx, events)
+		if err == nil || db.IsDuplicateKey(err) {
+			return
+		}
+		log.Printf("Failed to save history for game %s (attempt %d): %v", gameID, attempt, err)
+	}
+
+	_, err := s.Col.FindOneAndUpdate(ctx, bson.M{"_id": gameID}, bson.M{"$set": bson.M{"historyIncomplete": true}})
+	if err != nil {
+		log.Printf("Failed to mark the history of game %s as incomplete: %v", gameID, err)
+	}
+}
+
+// GetHistory Get the events that make up the history of a game, in the order they happened.
+func (s *Service) GetHistory(ctx context.Context, gameId string) ([]Event, error) {
+	events, err := s.History.Find(ctx, bson.M{"gameId": gameId})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Revision != events[j].Revision {
+			return events[i].Revision < events[j].Revision
+		}
+		return events[i].Sequence < events[j].Sequence
+	})
+
+	return events, nil
+}
+
+// Replay Get a game as it stood at a point in its history.
+// Once the game is over everything is revealed, until then the player only gets to see their own cards.
+func (s *Service) Replay(ctx context.Context, gameId string, playerId string, at Point) (Replayed, bool, error) {
+	// Get the game from the database.
+	game, has, err := s.Get(ctx, gameId)
+	if err != nil || !has {
+		return Replayed{}, has, err
+	}
+	if game.HistoryIncomplete {
+		return Replayed{}, true, ErrHistoryIncomplete
+	}
+
+	// Rebuild the game from its history.
+	events, err := s.GetHistory(ctx, gameId)
+	if err != nil {
+		return Replayed{}, true, err
+	}
+	if len(events) == 0 {
+		return Replayed{}, true, ErrNoHistory
+	}
+	replayed, err := ReplayTo(events, at)
+	if err != nil {
+		return Replayed{}, true, err
+	}
+
+	if game.Status == Completed {
+		full := replayed.GetFullState()
+		return Replayed{Game: &full}, true, nil
+	}
+	state := replayed.GetState(playerId)
+	return Replayed{State: &state}, true, nil
+}
+
 // Create a new game.
-func (s *Service) Create(ctx context.Context, playerIDs []string, name string, adminID string) (Game, error) {
+func (s *Service) Create(ctx context.Context, playerIDs []string, bots []BotType, preset RulesPreset, turnTimeLimit int, name string, adminID string) (Game, error) {
 	log.Printf("Creating new game (%s)", name)
 
 	// Check for duplicate player IDs.
@@ -53,24 +363,58 @@ func (s *Service) Create(ctx context.Context, playerIDs []string, name string, a
 		uniquePlayerIDs[id] = true
 	}
 	if len(uniquePlayerIDs) != len(playerIDs) {
-		return Game{}, errors.New("duplicate player IDs")
+		return Game{}, api.Errorf(api.InvalidRequest, "duplicate player IDs")
+	}
+
+	// Get the rules the game will be played with.
+	rules, err := NewRules(preset)
+	if err != nil {
+		return Game{}, err
+	}
+	rules, err = rules.WithTurnTimeLimit(turnTimeLimit)
+	if err != nil {
+		return Game{}, err
 	}
 
 	// Create a new game.
-	game, err := NewGame(playerIDs, name, adminID)
+	game, err := newGame(playerIDs, bots, rules, name, adminID)
 	if err != nil {
 		return Game{}, err
 	}
 
-	// Save the game to the database.
-	err = s.Col.Upsert(ctx, game, game.ID)
+	err = s.insert(ctx, &game)
 	if err != nil {
 		return Game{}, err
 	}
 
+	// A bot may have the first go.
+	s.queueBots(game)
+
 	return game, nil
 }
 
+// insert saves a newly dealt game along with the start of its history.
+func (s *Service) insert(ctx context.Context, game *Game) error {
+	// Start the game's history with the game as it was created.
+	now := time.Now()
+	game.clock = func() time.Time { return now }
+	err := game.recordCreated()
+	if err != nil {
+		return err
+	}
+	events := game.takeEvents()
+
+	// Save the game to the database.
+	err = s.Col.Upsert(ctx, *game, game.ID)
+	if err != nil {
+		return err
+	}
+
+	s.saveHistory(ctx, events)
+
+	return nil
+}
+
 // Get a game by ID.
 func (s *Service) Get(ctx context.Context, gameId string) (Game, bool, error) {
 	return s.Col.FindOne(ctx, bson.M{"_id": gameId})
@@ -113,17 +457,25 @@ func (s *Service) Delete(ctx context.Context, gameId string, adminId string) err
 		return err
 	}
 	if !has {
-		return errors.New("game not found")
+		return ErrNotFound
 	}
 
 	// Check correct admin
 	if game.AdminID != adminId {
-		return errors.New("not admin")
+		return api.Errorf(api.Forbidden, "not admin")
+	}
+
+	// Can only remove a game that hasn't been completed
+	if game.Status != Active && game.Status != Lobby {
+		return api.Errorf(api.WrongPhase, "can only delete games that are in an active state or in the lobby")
 	}
 
-	// Can only remove a game that is in an active state
-	if game.Status != Active {
-		return errors.New("can only delete games that are in an active state")
+	// Check nothing depends on the game
+	for _, guard := range s.deleteGuards {
+		err = guard(ctx, game)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Delete the game from the database.
@@ -132,128 +484,274 @@ func (s *Service) Delete(ctx context.Context, gameId string, adminId string) err
 
 // Call make a call
 func (s *Service) Call(ctx context.Context, gameId string, playerID string, call Call) (Game, error) {
-	// Get the game from the database.
-	game, has, err := s.Get(ctx, gameId)
+	return s.update(ctx, gameId, func(game *Game) error {
+		return game.Call(playerID, call)
+	})
+}
+
+// SelectSuit select a suit
+func (s *Service) SelectSuit(ctx context.Context, gameId string, playerID string, suit Suit, cards []CardName) (Game, error) {
+	return s.update(ctx, gameId, func(game *Game) error {
+		return game.SelectSuit(playerID, suit, cards)
+	})
+}
+
+// Buy cards
+func (s *Service) Buy(ctx context.Context, gameId string, playerID string, cards []CardName) (Game, error) {
+	return s.update(ctx, gameId, func(game *Game) error {
+		return game.Buy(playerID, cards)
+	})
+}
+
+// Play a card
+func (s *Service) Play(ctx context.Context, gameId string, playerID string, card CardName) (Game, error) {
+	return s.update(ctx, gameId, func(game *Game) error {
+		return game.Play(playerID, card)
+	})
+}
+
+// CreateLobby create an open table that players can be invited to or join with a code before the game is started.
+func (s *Service) CreateLobby(ctx context.Context, name string, preset RulesPreset, turnTimeLimit int, adminID string, invited []string) (Game, error) {
+	log.Printf("Creating new lobby (%s)", name)
+
+	// Get the rules the game will be played with.
+	rules, err := NewRules(preset)
 	if err != nil {
 		return Game{}, err
 	}
-	if !has {
-		return Game{}, errors.New("game not found")
-	}
-
-	// Make the call.
-	err = game.Call(playerID, call)
+	rules, err = rules.WithTurnTimeLimit(turnTimeLimit)
 	if err != nil {
 		return Game{}, err
 	}
 
-	// Save the game to the database.
-	err = s.Col.UpdateOne(ctx, game, game.ID)
+	// Save the lobby to the database.
+	lobby := NewLobby(name, adminID, rules, invited)
+	err = s.Col.Upsert(ctx, lobby, lobby.ID)
 	if err != nil {
 		return Game{}, err
 	}
 
-	// Update the state cache for all players in the game.
-	errC := s.updateStateCache(game)
-	if errC != nil {
-		return Game{}, errC
-	}
+	return lobby, nil
+}
 
-	return game, nil
+// Invite players to a lobby
+func (s *Service) Invite(ctx context.Context, gameId string, adminID string, playerIDs []string) (Game, error) {
+	return s.update(ctx, gameId, func(game *Game) error {
+		return game.Invite(adminID, playerIDs)
+	})
 }
 
-// SelectSuit select a suit
-func (s *Service) SelectSuit(ctx context.Context, gameId string, playerID string, suit Suit, cards []CardName) (Game, error) {
-	// Get the game from the database.
-	game, has, err := s.Get(ctx, gameId)
+// Accept an invitation to a lobby
+func (s *Service) Accept(ctx context.Context, gameId string, playerID string) (Game, error) {
+	return s.update(ctx, gameId, func(game *Game) error {
+		return game.Accept(playerID)
+	})
+}
+
+// Decline an invitation to a lobby
+func (s *Service) Decline(ctx context.Context, gameId string, playerID string) (Game, error) {
+	return s.update(ctx, gameId, func(game *Game) error {
+		return game.Decline(playerID)
+	})
+}
+
+// Join a lobby with its join code
+func (s *Service) Join(ctx context.Context, code string, playerID string) (Game, error) {
+	// Find the lobby with the code.
+	lobby, has, err := s.Col.FindOne(ctx, bson.M{"joinCode": code, "status": Lobby})
 	if err != nil {
 		return Game{}, err
 	}
 	if !has {
-		return Game{}, errors.New("game not found")
+		return Game{}, ErrNotFound
 	}
 
-	// Select the suit.
-	err = game.SelectSuit(playerID, suit, cards)
-	if err != nil {
-		return Game{}, err
-	}
-
-	// Save the game to the database.
-	err = s.Col.UpdateOne(ctx, game, game.ID)
-	if err != nil {
-		return Game{}, err
-	}
+	return s.update(ctx, lobby.ID, func(game *Game) error {
+		return game.Join(playerID, code)
+	})
+}
 
-	// Update the state cache for all players in the game.
-	errC := s.updateStateCache(game)
-	if errC != nil {
-		return Game{}, errC
-	}
+// Leave a lobby
+func (s *Service) Leave(ctx context.Context, gameId string, playerID string) (Game, error) {
+	return s.update(ctx, gameId, func(game *Game) error {
+		return game.Leave(playerID)
+	})
+}
 
-	return game, nil
+// Start a game with the players in the lobby
+func (s *Service) Start(ctx context.Context, gameId string, adminID string, bots []BotType) (Game, error) {
+	return s.update(ctx, gameId, func(game *Game) error {
+		return game.Start(adminID, bots)
+	})
 }
 
-// Buy cards
-func (s *Service) Buy(ctx context.Context, gameId string, playerID string, cards []CardName) (Game, error) {
+// Rematch create a new game for the same table once a game is over.
+// The rematch is saved before the completed game is linked to it, if the link can't be made the rematch is deleted so
+// that only one rematch is ever linked.
+func (s *Service) Rematch(ctx context.Context, gameId string, adminID string) (Game, error) {
 	// Get the game from the database.
 	game, has, err := s.Get(ctx, gameId)
 	if err != nil {
 		return Game{}, err
 	}
 	if !has {
-		return Game{}, errors.New("game not found")
+		return Game{}, ErrNotFound
 	}
 
-	// Buy the cards.
-	err = game.Buy(playerID, cards)
+	rematch, err := game.Rematch(adminID)
+	if err != nil {
+		return Game{}, err
+	}
+	err = s.insert(ctx, &rematch)
 	if err != nil {
 		return Game{}, err
 	}
 
-	// Save the game to the database.
-	err = s.Col.UpdateOne(ctx, game, game.ID)
+	// Link the completed game to the rematch.
+	_, err = s.update(ctx, gameId, func(game *Game) error {
+		return game.linkRematch(rematch.ID)
+	})
 	if err != nil {
+		errD := s.Col.DeleteOne(ctx, rematch.ID)
+		if errD != nil {
+			log.Printf("Failed to delete rematch %s of game %s: %v", rematch.ID, gameId, errD)
+		}
 		return Game{}, err
 	}
 
-	// Update the state cache for all players in the game.
-	errC := s.updateStateCache(game)
-	if errC != nil {
-		return Game{}, errC
+	// A bot may have the first go.
+	s.queueBots(rematch)
+
+	return rematch, nil
+}
+
+// RunBots starts the workers that make the moves for bots, which run until the context is cancelled.
+// Bots think about their moves in the background, so the request that gave them their go isn't held up while they do.
+// It must be called at start up, before any games are played.
+func (s *Service) RunBots(ctx context.Context, workers int) {
+	s.bots = make(chan string, botQueueSize)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case gameID := <-s.bots:
+					err := s.playBot(ctx, gameID)
+					if err != nil {
+						log.Printf("Failed to make the move for a bot in game %s: %v", gameID, err)
+					}
+				}
+			}
+		}()
 	}
+}
 
-	return game, nil
+// queueBots lets the bot workers know if it's a bot's go in a game.
+// If the queue is full, or the workers aren't running, the bot is left for the turn timer.
+func (s *Service) queueBots(game Game) {
+	if s.bots == nil || !game.botsTurn() {
+		return
+	}
+	select {
+	case s.bots <- game.ID:
+	default:
+		log.Printf("Too many games waiting for bots, game %s is left for the turn timer", game.ID)
+	}
 }
 
-// Play a card
-func (s *Service) Play(ctx context.Context, gameId string, playerID string, card CardName) (Game, error) {
-	// Get the game from the database.
+// playBot makes the move for the bot whose go it is in a game.
+// The bot decides its move before the game is updated so the search isn't repeated if the update has to be retried. If
+// the game has changed in the meantime the move is dropped, whoever changed it will have queued the bot again.
+func (s *Service) playBot(ctx context.Context, gameId string) error {
 	game, has, err := s.Get(ctx, gameId)
 	if err != nil {
-		return Game{}, err
+		return err
 	}
 	if !has {
-		return Game{}, errors.New("game not found")
+		return ErrNotFound
+	}
+
+	move, ok, err := game.decideBotMove()
+	if err != nil || !ok {
+		return err
 	}
 
-	// Play the card.
-	err = game.Play(playerID, card)
+	_, err = s.update(ctx, gameId, func(latest *Game) error {
+		if latest.Revision != game.Revision {
+			return errBotMoveStale
+		}
+		return move(latest)
+	})
+	if errors.Is(err, errBotMoveStale) {
+		return nil
+	}
+	return err
+}
+
+// PlayStalledBots makes the moves for any bots that have been waiting too long to take their go.
+func (s *Service) PlayStalledBots(ctx context.Context) error {
+	games, err := s.Col.Find(ctx, bson.M{"status": Active, "players.bot": bson.M{"$exists": true}})
 	if err != nil {
-		return Game{}, err
+		return err
 	}
 
-	// Save the game to the database.
-	err = s.Col.UpdateOne(ctx, game, game.ID)
+	stalled := time.Now().Add(-botStallTime)
+	for _, game := range games {
+		if !game.botsTurn() || game.CurrentRound.CurrentHand.Timestamp.After(stalled) {
+			continue
+		}
+		err = s.playBot(ctx, game.ID)
+		if err != nil {
+			log.Printf("Failed to make the move for a stalled bot in game %s: %v", game.ID, err)
+		}
+	}
+	return nil
+}
+
+// ExpireTurns takes the go of any player who has run out of time.
+// Deadlines are worked out from the saved games so nothing is lost if the server restarts.
+func (s *Service) ExpireTurns(ctx context.Context) error {
+	games, err := s.Col.Find(ctx, bson.M{"status": Active, "rules.turnTimeLimit": bson.M{"$gt": 0}})
 	if err != nil {
-		return Game{}, err
+		return err
 	}
 
-	// Update the state cache for all players in the game.
-	errC := s.updateStateCache(game)
-	if errC != nil {
-		return Game{}, errC
+	now := time.Now()
+	for _, game := range games {
+		if !game.turnExpired(now) {
+			continue
+		}
+
+		// The player may have taken their go since, so the deadline is checked again against the latest revision.
+		_, err = s.update(ctx, game.ID, func(game *Game) error {
+			return game.timeout()
+		})
+		if err != nil && !errors.Is(err, errTurnNotExpired) {
+			log.Printf("Failed to take the go of a player who ran out of time in game %s: %v", game.ID, err)
+		}
 	}
+	return nil
+}
 
-	return game, nil
+// RunTurnTimer checks for players who have run out of time, and bots that have stalled, at the given interval until the
+// context is cancelled.
+func (s *Service) RunTurnTimer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.ExpireTurns(ctx)
+			if err != nil {
+				log.Printf("Failed to check for expired turns: %v", err)
+			}
+			err = s.PlayStalledBots(ctx)
+			if err != nil {
+				log.Printf("Failed to check for stalled bots: %v", err)
+			}
+		}
+	}
 }
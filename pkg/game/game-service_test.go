@@ -1,12 +1,17 @@
 package game
 
 import (
+	"cards-110-api/pkg/api"
 	"cards-110-api/pkg/cache"
 	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/settings"
 	"context"
 	"errors"
 	"reflect"
 	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestGameService_Create(t *testing.T) {
@@ -15,15 +20,68 @@ func TestGameService_Create(t *testing.T) {
 	tests := []struct {
 		name            string
 		inputPlayerIDs  []string
+		inputBots       []BotType
+		inputRules      RulesPreset
+		inputTimeLimit  int
 		inputAdminID    string
 		mockUpsertError *[]error
 		expectingError  bool
+		expectedRules   RulesPreset
 	}{
 		{
 			name:            "simple create",
 			inputPlayerIDs:  []string{"1", "2"},
 			inputAdminID:    "1",
 			mockUpsertError: &[]error{nil},
+			expectedRules:   HouseRules,
+		},
+		{
+			name:            "create with rules",
+			inputPlayerIDs:  []string{"1", "2"},
+			inputRules:      ShortGame,
+			inputAdminID:    "1",
+			mockUpsertError: &[]error{nil},
+			expectedRules:   ShortGame,
+		},
+		{
+			name:            "unknown rules",
+			inputPlayerIDs:  []string{"1", "2"},
+			inputRules:      "CALVINBALL",
+			inputAdminID:    "1",
+			mockUpsertError: &[]error{nil},
+			expectingError:  true,
+		},
+		{
+			name:            "create with a turn time limit",
+			inputPlayerIDs:  []string{"1", "2"},
+			inputTimeLimit:  60,
+			inputAdminID:    "1",
+			mockUpsertError: &[]error{nil},
+			expectedRules:   HouseRules,
+		},
+		{
+			name:            "turn time limit too short",
+			inputPlayerIDs:  []string{"1", "2"},
+			inputTimeLimit:  1,
+			inputAdminID:    "1",
+			mockUpsertError: &[]error{nil},
+			expectingError:  true,
+		},
+		{
+			name:            "create with bots",
+			inputPlayerIDs:  []string{"1"},
+			inputBots:       []BotType{RuleBased, RuleBased},
+			inputAdminID:    "1",
+			mockUpsertError: &[]error{nil},
+			expectedRules:   HouseRules,
+		},
+		{
+			name:            "unknown bot type",
+			inputPlayerIDs:  []string{"1"},
+			inputBots:       []BotType{"GENIUS"},
+			inputAdminID:    "1",
+			mockUpsertError: &[]error{nil},
+			expectingError:  true,
 		},
 		{
 			name: "duplicate player IDs",
@@ -63,7 +121,7 @@ func TestGameService_Create(t *testing.T) {
 				Col: mockCol,
 			}
 
-			result, err := ds.Create(ctx, test.inputPlayerIDs, test.name, test.inputAdminID)
+			result, err := ds.Create(ctx, test.inputPlayerIDs, test.inputBots, test.inputRules, test.inputTimeLimit, test.name, test.inputAdminID)
 
 			if test.expectingError {
 				if err == nil {
@@ -76,8 +134,14 @@ func TestGameService_Create(t *testing.T) {
 				if result.AdminID != test.inputAdminID {
 					t.Errorf("expected admin id %s, got %s", test.inputAdminID, result.AdminID)
 				}
-				if len(result.Players) != len(test.inputPlayerIDs) {
-					t.Errorf("expected %d players, got %d", len(test.inputPlayerIDs), len(result.Players))
+				if result.Rules == nil || result.Rules.Preset != test.expectedRules {
+					t.Errorf("expected rules %s, got %v", test.expectedRules, result.Rules)
+				}
+				if result.Rules != nil && result.Rules.TurnTimeLimit != test.inputTimeLimit {
+					t.Errorf("expected a turn time limit of %d, got %d", test.inputTimeLimit, result.Rules.TurnTimeLimit)
+				}
+				if len(result.Players) != len(test.inputPlayerIDs)+len(test.inputBots) {
+					t.Errorf("expected %d players, got %d", len(test.inputPlayerIDs)+len(test.inputBots), len(result.Players))
 				}
 				// Check that the players are in the game
 				for _, playerID := range test.inputPlayerIDs {
@@ -498,6 +562,7 @@ func TestGameService_Delete(t *testing.T) {
 		mockGetExists      *[]bool
 		mockGetError       *[]error
 		mockDeleteOneError *[]error
+		guardError         error
 		expectingError     bool
 	}{
 		{
@@ -556,6 +621,17 @@ func TestGameService_Delete(t *testing.T) {
 			mockDeleteOneError: &[]error{nil},
 			expectingError:     true,
 		},
+		{
+			name:               "game in use",
+			gameToCancel:       TwoPlayerGame().ID,
+			adminID:            "1",
+			mockGetResult:      &[]Game{TwoPlayerGame()},
+			mockGetExists:      &[]bool{true},
+			mockGetError:       &[]error{nil},
+			mockDeleteOneError: &[]error{nil},
+			guardError:         api.Errorf(api.Conflict, "game is part of match 1"),
+			expectingError:     true,
+		},
 		{
 			name:         "Game completed",
 			gameToCancel: CompletedGame().ID,
@@ -582,12 +658,18 @@ func TestGameService_Delete(t *testing.T) {
 			ds := &Service{
 				Col: mockCol,
 			}
+			ds.OnDelete(func(ctx context.Context, game Game) error {
+				return test.guardError
+			})
 
 			err := ds.Delete(ctx, test.gameToCancel, test.adminID)
 
 			if test.expectingError && err == nil {
 				t.Errorf("expected error %v, got %v", test.expectingError, err)
 			}
+			if test.guardError != nil && len(*test.mockDeleteOneError) == 0 {
+				t.Errorf("expected the game not to be deleted")
+			}
 		})
 	}
 }
@@ -672,7 +754,7 @@ func TestGameService_Call(t *testing.T) {
 			expectingError: true,
 		},
 		{
-			name:               "error writing to cache should return an error",
+			name:               "error writing to cache should not fail the saved move",
 			gameID:             TwoPlayerGame().ID,
 			playerID:           "2",
 			call:               Jink,
@@ -681,18 +763,17 @@ func TestGameService_Call(t *testing.T) {
 			mockGetError:       &[]error{nil},
 			mockUpdateOneError: &[]error{nil},
 			mockSetCacheError:  &[]error{errors.New("failed to write to cache")},
-			expectedRevision:   2,
-			expectingError:     true,
+			expectedRevision:   3,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			mockCol := &db.MockCollection[Game]{
-				MockFindOneResult: test.mockGetResult,
-				MockFindOneExists: test.mockGetExists,
-				MockFindOneErr:    test.mockGetError,
-				MockUpdateOneErr:  test.mockUpdateOneError,
+				MockFindOneResult:           test.mockGetResult,
+				MockFindOneExists:           test.mockGetExists,
+				MockFindOneErr:              test.mockGetError,
+				MockConditionalUpdateOneErr: test.mockUpdateOneError,
 			}
 
 			mockCache := &cache.MockCache[State]{
@@ -817,7 +898,7 @@ func TestGameService_SelectSuit(t *testing.T) {
 			expectingError:     true,
 		},
 		{
-			name:               "error writing to cache should return an error",
+			name:               "error writing to cache should not fail the saved move",
 			gameID:             CalledGameFivePlayers().ID,
 			playerID:           "PlayerCalled",
 			suit:               Hearts,
@@ -828,7 +909,6 @@ func TestGameService_SelectSuit(t *testing.T) {
 			mockUpdateOneError: &[]error{nil},
 			mockSetCacheError:  &[]error{errors.New("failed to write to cache")},
 			expectedRevision:   1,
-			expectingError:     true,
 		},
 	}
 
@@ -836,10 +916,10 @@ func TestGameService_SelectSuit(t *testing.T) {
 
 		t.Run(test.name, func(t *testing.T) {
 			mockCol := &db.MockCollection[Game]{
-				MockFindOneResult: test.mockGetResult,
-				MockFindOneExists: test.mockGetExists,
-				MockFindOneErr:    test.mockGetError,
-				MockUpdateOneErr:  test.mockUpdateOneError,
+				MockFindOneResult:           test.mockGetResult,
+				MockFindOneExists:           test.mockGetExists,
+				MockFindOneErr:              test.mockGetError,
+				MockConditionalUpdateOneErr: test.mockUpdateOneError,
 			}
 
 			mockCache := &cache.MockCache[State]{
@@ -947,7 +1027,7 @@ func TestGameService_Buy(t *testing.T) {
 			expectingError:     true,
 		},
 		{
-			name:               "error writing to cache should return an error",
+			name:               "error writing to cache should not fail the saved move",
 			gameID:             "1",
 			playerID:           "2",
 			cards:              []CardName{SEVEN_HEARTS, EIGHT_HEARTS, NINE_HEARTS},
@@ -956,17 +1036,17 @@ func TestGameService_Buy(t *testing.T) {
 			mockGetError:       &[]error{nil},
 			mockUpdateOneError: &[]error{nil},
 			mockSetCacheError:  &[]error{errors.New("failed to write to cache")},
-			expectingError:     true,
+			expectedRevision:   1,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			mockCol := &db.MockCollection[Game]{
-				MockFindOneResult: test.mockGetResult,
-				MockFindOneExists: test.mockGetExists,
-				MockFindOneErr:    test.mockGetError,
-				MockUpdateOneErr:  test.mockUpdateOneError,
+				MockFindOneResult:           test.mockGetResult,
+				MockFindOneExists:           test.mockGetExists,
+				MockFindOneErr:              test.mockGetError,
+				MockConditionalUpdateOneErr: test.mockUpdateOneError,
 			}
 
 			mockCache := &cache.MockCache[State]{
@@ -1079,7 +1159,7 @@ func TestGameService_Play(t *testing.T) {
 			expectingError:     true,
 		},
 		{
-			name:               "error writing to cache should return an error",
+			name:               "error writing to cache should not fail the saved move",
 			gameID:             "1",
 			playerID:           "1",
 			card:               TWO_HEARTS,
@@ -1088,17 +1168,17 @@ func TestGameService_Play(t *testing.T) {
 			mockGetError:       &[]error{nil},
 			mockUpdateOneError: &[]error{nil},
 			mockSetCacheError:  &[]error{errors.New("failed to write to cache")},
-			expectingError:     true,
+			expectedRevision:   1,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			mockCol := &db.MockCollection[Game]{
-				MockFindOneResult: test.mockGetResult,
-				MockFindOneExists: test.mockGetExists,
-				MockFindOneErr:    test.mockGetError,
-				MockUpdateOneErr:  test.mockUpdateOneError,
+				MockFindOneResult:           test.mockGetResult,
+				MockFindOneExists:           test.mockGetExists,
+				MockFindOneErr:              test.mockGetError,
+				MockConditionalUpdateOneErr: test.mockUpdateOneError,
 			}
 
 			mockCache := &cache.MockCache[State]{
@@ -1143,3 +1223,974 @@ func TestGameService_Play(t *testing.T) {
 		})
 	}
 }
+
+func TestGameService_update(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name               string
+		mockGetResult      *[]Game
+		mockGetExists      *[]bool
+		mockGetError       *[]error
+		mockConflict       *[]bool
+		mockUpdateOneError *[]error
+		expectedError      error
+		expectingError     bool
+		expectedRevision   int
+	}{
+		{
+			name:             "no conflict",
+			mockGetResult:    &[]Game{PlayingGame_RoundStart("1")},
+			mockGetExists:    &[]bool{true},
+			mockGetError:     &[]error{nil},
+			mockConflict:     &[]bool{false},
+			expectedRevision: 1,
+		},
+		{
+			name:             "conflict is retried against the latest revision",
+			mockGetResult:    &[]Game{PlayingGame_RoundStart("1"), PlayingGame_RoundStart("1")},
+			mockGetExists:    &[]bool{true, true},
+			mockGetError:     &[]error{nil, nil},
+			mockConflict:     &[]bool{true, false},
+			expectedRevision: 1,
+		},
+		{
+			name:           "persistent conflict returns ErrConflict",
+			mockGetResult:  &[]Game{PlayingGame_RoundStart("1"), PlayingGame_RoundStart("1"), PlayingGame_RoundStart("1")},
+			mockGetExists:  &[]bool{true, true, true},
+			mockGetError:   &[]error{nil, nil, nil},
+			mockConflict:   &[]bool{true, true, true},
+			expectedError:  ErrConflict,
+			expectingError: true,
+		},
+		{
+			name:               "error updating is not retried",
+			mockGetResult:      &[]Game{PlayingGame_RoundStart("1")},
+			mockGetExists:      &[]bool{true},
+			mockGetError:       &[]error{nil},
+			mockUpdateOneError: &[]error{errors.New("something went wrong")},
+			expectingError:     true,
+		},
+		{
+			name:           "move no longer valid after a conflict",
+			mockGetResult:  &[]Game{PlayingGame_RoundStart("1"), PlayingGame_RoundStart_FirstCardPlayed()},
+			mockGetExists:  &[]bool{true, true},
+			mockGetError:   &[]error{nil, nil},
+			mockConflict:   &[]bool{true},
+			expectingError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCol := &db.MockCollection[Game]{
+				MockFindOneResult:                test.mockGetResult,
+				MockFindOneExists:                test.mockGetExists,
+				MockFindOneErr:                   test.mockGetError,
+				MockConditionalUpdateOneConflict: test.mockConflict,
+				MockConditionalUpdateOneErr:      test.mockUpdateOneError,
+			}
+
+			mockCache := &cache.MockCache[State]{
+				MockSetErr: &[]error{},
+			}
+
+			ds := &Service{
+				Col:   mockCol,
+				Cache: mockCache,
+			}
+
+			game, err := ds.Play(ctx, "1", "1", TWO_HEARTS)
+
+			if test.expectingError {
+				if err == nil {
+					t.Errorf("expected an error, got nil")
+				}
+				if test.expectedError != nil && !errors.Is(err, test.expectedError) {
+					t.Errorf("expected error %v, got %v", test.expectedError, err)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				if game.Revision != test.expectedRevision {
+					t.Errorf("expected revision %d, got %d", test.expectedRevision, game.Revision)
+				}
+			}
+		})
+	}
+}
+
+func TestGameService_Replay(t *testing.T) {
+	ctx := context.Background()
+
+	active, activeHistory := recordGame(t, []string{"1", "2", "3"}, 20)
+	completed, completedHistory := recordGame(t, []string{"1", "2", "3"}, 10000)
+	incomplete := active
+	incomplete.HistoryIncomplete = true
+
+	tests := []struct {
+		name             string
+		at               Point
+		mockGetResult    *[]Game
+		mockGetExists    *[]bool
+		mockHistory      *[][]Event
+		expectedExists   bool
+		expectedFull     bool
+		expectedError    error
+		expectingError   bool
+		expectedRevision int
+	}{
+		{
+			name:             "active game returns the player's state",
+			at:               Point{Revision: 10},
+			mockGetResult:    &[]Game{active},
+			mockGetExists:    &[]bool{true},
+			mockHistory:      &[][]Event{activeHistory},
+			expectedExists:   true,
+			expectedRevision: 10,
+		},
+		{
+			name:             "completed game returns everything",
+			at:               Point{Round: 2, Hand: 3},
+			mockGetResult:    &[]Game{completed},
+			mockGetExists:    &[]bool{true},
+			mockHistory:      &[][]Event{completedHistory},
+			expectedExists:   true,
+			expectedFull:     true,
+			expectedRevision: 19,
+		},
+		{
+			name:           "game not found",
+			mockGetResult:  &[]Game{{}},
+			mockGetExists:  &[]bool{false},
+			expectedExists: false,
+		},
+		{
+			name:           "no history",
+			mockGetResult:  &[]Game{active},
+			mockGetExists:  &[]bool{true},
+			mockHistory:    &[][]Event{{}},
+			expectedExists: true,
+			expectedError:  ErrNoHistory,
+			expectingError: true,
+		},
+		{
+			name:           "incomplete history",
+			mockGetResult:  &[]Game{incomplete},
+			mockGetExists:  &[]bool{true},
+			expectedExists: true,
+			expectedError:  ErrHistoryIncomplete,
+			expectingError: true,
+		},
+		{
+			name:           "point not reached",
+			at:             Point{Revision: active.Revision + 1},
+			mockGetResult:  &[]Game{active},
+			mockGetExists:  &[]bool{true},
+			mockHistory:    &[][]Event{activeHistory},
+			expectedExists: true,
+			expectedError:  ErrNotInHistory,
+			expectingError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ds := &Service{
+				Col: &db.MockCollection[Game]{
+					MockFindOneResult: test.mockGetResult,
+					MockFindOneExists: test.mockGetExists,
+					MockFindOneErr:    &[]error{nil},
+				},
+				History: &db.MockCollection[Event]{
+					MockFindResult: test.mockHistory,
+					MockFindErr:    &[]error{nil},
+				},
+			}
+
+			replayed, exists, err := ds.Replay(ctx, "1", "2", test.at)
+
+			if exists != test.expectedExists {
+				t.Errorf("expected exists %v, got %v", test.expectedExists, exists)
+			}
+			if test.expectingError {
+				if !errors.Is(err, test.expectedError) {
+					t.Errorf("expected error %v, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !exists {
+				return
+			}
+
+			if test.expectedFull {
+				if replayed.Game == nil || replayed.State != nil {
+					t.Fatalf("expected the full game only, got %+v", replayed)
+				}
+				if replayed.Game.Revision != test.expectedRevision {
+					t.Errorf("expected revision %d, got %d", test.expectedRevision, replayed.Game.Revision)
+				}
+				for _, p := range replayed.Game.Players {
+					if len(p.Cards) == 0 {
+						t.Errorf("expected cards for player %s", p.ID)
+					}
+				}
+			} else {
+				if replayed.State == nil || replayed.Game != nil {
+					t.Fatalf("expected the player's state only, got %+v", replayed)
+				}
+				if replayed.State.Revision != test.expectedRevision {
+					t.Errorf("expected revision %d, got %d", test.expectedRevision, replayed.State.Revision)
+				}
+				if replayed.State.Me.ID != "2" {
+					t.Errorf("expected state for player 2, got %s", replayed.State.Me.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGameService_saveHistory(t *testing.T) {
+	ctx := context.Background()
+	duplicate := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Code: 11000}}}}
+
+	tests := []struct {
+		name               string
+		mockInsertManyErr  *[]error
+		expectedAttempts   int
+		expectedIncomplete bool
+	}{
+		{
+			name:              "saved",
+			mockInsertManyErr: &[]error{nil},
+			expectedAttempts:  1,
+		},
+		{
+			name:              "retried after an error",
+			mockInsertManyErr: &[]error{errors.New("something went wrong"), nil},
+			expectedAttempts:  2,
+		},
+		{
+			name:              "already saved by an earlier attempt",
+			mockInsertManyErr: &[]error{errors.New("something went wrong"), duplicate},
+			expectedAttempts:  2,
+		},
+		{
+			name:               "marked as incomplete when it can't be saved",
+			mockInsertManyErr:  &[]error{errors.New("something went wrong"), errors.New("something went wrong"), errors.New("something went wrong"), nil},
+			expectedAttempts:   3,
+			expectedIncomplete: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCol := &db.MockCollection[Game]{}
+			attempts := len(*test.mockInsertManyErr)
+			ds := &Service{
+				Col:     mockCol,
+				History: &db.MockCollection[Event]{MockInsertManyErr: test.mockInsertManyErr},
+			}
+
+			ds.saveHistory(ctx, []Event{{ID: "1-1-0", GameID: "1", Revision: 1, Type: EventCardPlayed}})
+
+			if made := attempts - len(*test.mockInsertManyErr); made != test.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", test.expectedAttempts, made)
+			}
+			if incomplete := len(mockCol.FindOneAndUpdateCalls) > 0; incomplete != test.expectedIncomplete {
+				t.Errorf("expected history incomplete %v, got %v", test.expectedIncomplete, incomplete)
+			}
+		})
+	}
+}
+
+// botGame returns a game with only bots in it, so it's always a bot's go.
+func botGame(t *testing.T) Game {
+	playerIDs, botIDs, err := addBots(nil, []BotType{RuleBased, RuleBased})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	g, err := NewGameWithSeed(playerIDs, "test", playerIDs[0], 1)
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+	for i, p := range g.Players {
+		g.Players[i].Bot = botIDs[p.ID]
+	}
+	return g
+}
+
+func TestGameService_playBot(t *testing.T) {
+	ctx := context.Background()
+	bots := botGame(t)
+	moved := botGame(t)
+	moved.Revision++
+
+	tests := []struct {
+		name           string
+		mockGetResult  *[]Game
+		mockConflict   *[]bool
+		expectedSaves  int
+		expectedQueued bool
+	}{
+		{
+			name:           "bot takes its go",
+			mockGetResult:  &[]Game{bots, bots},
+			mockConflict:   &[]bool{false},
+			expectedSaves:  1,
+			expectedQueued: true,
+		},
+		{
+			name:          "move is dropped if the game changed while the bot was thinking",
+			mockGetResult: &[]Game{bots, moved},
+			mockConflict:  &[]bool{false},
+		},
+		{
+			name:          "human's go",
+			mockGetResult: &[]Game{TwoPlayerGame()},
+			mockConflict:  &[]bool{false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ds := &Service{
+				Col: &db.MockCollection[Game]{
+					MockFindOneResult:                test.mockGetResult,
+					MockFindOneExists:                &[]bool{true, true},
+					MockFindOneErr:                   &[]error{nil, nil},
+					MockConditionalUpdateOneConflict: test.mockConflict,
+				},
+				Cache: &cache.MockCache[State]{MockSetErr: &[]error{}},
+				bots:  make(chan string, 1),
+			}
+
+			err := ds.playBot(ctx, "1")
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if saves := 1 - len(*test.mockConflict); saves != test.expectedSaves {
+				t.Errorf("expected %d saves, got %d", test.expectedSaves, saves)
+			}
+			if queued := len(ds.bots) > 0; queued != test.expectedQueued {
+				t.Errorf("expected the bots to be queued %v, got %v", test.expectedQueued, queued)
+			}
+		})
+	}
+}
+
+func TestGameService_queueBots(t *testing.T) {
+	bots := botGame(t)
+
+	tests := []struct {
+		name           string
+		game           Game
+		queued         []string
+		expectedQueued []string
+	}{
+		{
+			name:           "bot's go",
+			game:           bots,
+			expectedQueued: []string{bots.ID},
+		},
+		{
+			name: "human's go",
+			game: TwoPlayerGame(),
+		},
+		{
+			name:           "queue full",
+			game:           bots,
+			queued:         []string{"2"},
+			expectedQueued: []string{"2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ds := &Service{bots: make(chan string, 1)}
+			for _, id := range test.queued {
+				ds.bots <- id
+			}
+
+			// A full queue must not hold up the move that queued the bot
+			ds.queueBots(test.game)
+
+			close(ds.bots)
+			var queued []string
+			for id := range ds.bots {
+				queued = append(queued, id)
+			}
+			if !reflect.DeepEqual(queued, test.expectedQueued) {
+				t.Errorf("expected %v to be queued, got %v", test.expectedQueued, queued)
+			}
+		})
+	}
+}
+
+func TestGameService_RunBots(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bots := botGame(t)
+	col := &db.MockCollection[Game]{
+		MockFindOneResult: &[]Game{bots, bots},
+		MockFindOneExists: &[]bool{true, true},
+		MockFindOneErr:    &[]error{nil, nil},
+	}
+	ds := &Service{
+		Col:   col,
+		Cache: &cache.MockCache[State]{MockSetErr: &[]error{}},
+		Hub:   NewHub(),
+	}
+	events, unsubscribe := ds.Subscribe(bots.ID)
+	defer unsubscribe()
+
+	ds.RunBots(ctx, 1)
+	ds.queueBots(bots)
+
+	select {
+	case event := <-events:
+		if event.Revision != bots.Revision+1 {
+			t.Errorf("expected the bot's move to be saved as revision %d, got %d", bots.Revision+1, event.Revision)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a worker to make the bot's move")
+	}
+}
+
+func TestGameService_PlayStalledBots(t *testing.T) {
+	ctx := context.Background()
+
+	stalled := botGame(t)
+	stalled.ID = "stalled"
+	stalled.CurrentRound.CurrentHand.Timestamp = time.Now().Add(-2 * botStallTime)
+	waiting := botGame(t)
+	waiting.ID = "waiting"
+	waiting.CurrentRound.CurrentHand.Timestamp = time.Now()
+	human := TwoPlayerGame()
+	human.CurrentRound.CurrentHand.Timestamp = time.Now().Add(-2 * botStallTime)
+
+	col := &db.MockCollection[Game]{
+		MockFindResult:    &[][]Game{{stalled, waiting, human}},
+		MockFindErr:       &[]error{nil},
+		MockFindOneResult: &[]Game{stalled, stalled},
+		MockFindOneExists: &[]bool{true, true},
+		MockFindOneErr:    &[]error{nil, nil},
+	}
+	ds := &Service{Col: col, Cache: &cache.MockCache[State]{MockSetErr: &[]error{}}}
+
+	err := ds.PlayStalledBots(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Only the bot that has been waiting too long is moved
+	if len(col.ConditionalUpdateOneCalls) != 1 || col.ConditionalUpdateOneCalls[0].ID != stalled.ID {
+		t.Errorf("expected only the stalled bot to take its go, got %d saves", len(col.ConditionalUpdateOneCalls))
+	}
+}
+
+func TestGameService_ExpireTurns(t *testing.T) {
+	ctx := context.Background()
+
+	expired := withTurnTimeLimit(TwoPlayerGame(), 30)
+	inTime := withTurnTimeLimit(TwoPlayerGame(), 30)
+	inTime.ID = "2"
+	inTime.CurrentRound.CurrentHand.Timestamp = time.Now()
+
+	tests := []struct {
+		name            string
+		mockFindResult  *[][]Game
+		mockFindError   *[]error
+		mockGetResult   *[]Game
+		expectedUpdates int
+		expectingError  bool
+	}{
+		{
+			name:            "only expired turns are taken",
+			mockFindResult:  &[][]Game{{expired, inTime}},
+			mockFindError:   &[]error{nil},
+			mockGetResult:   &[]Game{expired},
+			expectedUpdates: 1,
+		},
+		{
+			name:            "the player took their go in the meantime",
+			mockFindResult:  &[][]Game{{expired}},
+			mockFindError:   &[]error{nil},
+			mockGetResult:   &[]Game{inTime},
+			expectedUpdates: 0,
+		},
+		{
+			name:           "error finding games",
+			mockFindResult: &[][]Game{{}},
+			mockFindError:  &[]error{errors.New("something went wrong")},
+			mockGetResult:  &[]Game{},
+			expectingError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exists := make([]bool, len(*test.mockGetResult))
+			for i := range exists {
+				exists[i] = true
+			}
+			mockCol := &db.MockCollection[Game]{
+				MockFindResult:    test.mockFindResult,
+				MockFindErr:       test.mockFindError,
+				MockFindOneResult: test.mockGetResult,
+				MockFindOneExists: &exists,
+				MockFindOneErr:    &[]error{},
+			}
+			// The state is cached for both players each time a game is saved
+			cacheSets := make([]error, 2*test.expectedUpdates)
+			mockCache := &cache.MockCache[State]{
+				MockSetErr: &cacheSets,
+			}
+			ds := &Service{
+				Col:   mockCol,
+				Cache: mockCache,
+			}
+
+			err := ds.ExpireTurns(ctx)
+
+			if test.expectingError {
+				if err == nil {
+					t.Errorf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(*test.mockGetResult) != 0 {
+				t.Errorf("expected every expired game to be read again, %d left", len(*test.mockGetResult))
+			}
+			if len(cacheSets) != 0 {
+				t.Errorf("expected %d games to be saved", test.expectedUpdates)
+			}
+		})
+	}
+}
+
+func TestGameService_AutoBuy(t *testing.T) {
+	ctx := context.Background()
+
+	goerFirst := BuyingGame("1")
+	goerFirst.CurrentRound.GoerID = "2"
+
+	tests := []struct {
+		name             string
+		game             Game
+		mockSettings     *[]settings.Settings
+		mockExists       *[]bool
+		mockSettingsErr  *[]error
+		expectedPlayer   string
+		expectedStatus   RoundStatus
+		expectedRevision int
+		expectingError   bool
+	}{
+		{
+			name:             "next player buys automatically",
+			game:             BuyingGame("1"),
+			mockSettings:     &[]settings.Settings{{ID: "3", AutoBuy: true}},
+			mockExists:       &[]bool{true},
+			expectedPlayer:   "PlayerCalled",
+			expectedStatus:   Buying,
+			expectedRevision: 2,
+		},
+		{
+			name:             "players without settings buy automatically",
+			game:             BuyingGame("1"),
+			mockSettings:     &[]settings.Settings{{}},
+			mockExists:       &[]bool{false},
+			expectedPlayer:   "PlayerCalled",
+			expectedStatus:   Buying,
+			expectedRevision: 2,
+		},
+		{
+			name:             "auto buy turned off",
+			game:             BuyingGame("1"),
+			mockSettings:     &[]settings.Settings{{ID: "3", AutoBuy: false}},
+			mockExists:       &[]bool{true},
+			expectedPlayer:   "3",
+			expectedStatus:   Buying,
+			expectedRevision: 1,
+		},
+		{
+			name: "consecutive players buy automatically",
+			game: goerFirst,
+			mockSettings: &[]settings.Settings{
+				{ID: "3", AutoBuy: true},
+				{ID: "PlayerCalled", AutoBuy: true},
+				{ID: "1", AutoBuy: true},
+			},
+			mockExists:       &[]bool{true, true, true},
+			expectedPlayer:   "3",
+			expectedStatus:   Playing,
+			expectedRevision: 4,
+		},
+		{
+			name:            "error getting settings",
+			game:            BuyingGame("1"),
+			mockSettings:    &[]settings.Settings{{}},
+			mockExists:      &[]bool{false},
+			mockSettingsErr: &[]error{errors.New("something went wrong")},
+			expectingError:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCol := &db.MockCollection[Game]{
+				MockFindOneResult: &[]Game{test.game},
+				MockFindOneExists: &[]bool{true},
+				MockFindOneErr:    &[]error{nil},
+			}
+			mockCache := &cache.MockCache[State]{
+				MockSetErr: &[]error{},
+			}
+			mockSettings := &settings.MockService{
+				MockGetResult: test.mockSettings,
+				MockGetExists: test.mockExists,
+				MockGetErr:    test.mockSettingsErr,
+			}
+			ds := &Service{
+				Col:      mockCol,
+				Cache:    mockCache,
+				Settings: mockSettings,
+			}
+
+			game, err := ds.Buy(ctx, test.game.ID, "2", []CardName{})
+
+			if test.expectingError {
+				if err == nil {
+					t.Errorf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if game.CurrentRound.CurrentHand.CurrentPlayerID != test.expectedPlayer {
+				t.Errorf("expected player %s to be next, got %s", test.expectedPlayer, game.CurrentRound.CurrentHand.CurrentPlayerID)
+			}
+			if game.CurrentRound.Status != test.expectedStatus {
+				t.Errorf("expected round status %s, got %s", test.expectedStatus, game.CurrentRound.Status)
+			}
+			if game.Revision != test.expectedRevision {
+				t.Errorf("expected revision %d, got %d", test.expectedRevision, game.Revision)
+			}
+			// Everyone who bought has a full hand
+			for _, p := range game.Players {
+				if p.ID != test.expectedPlayer && len(p.Cards) != 5 {
+					t.Errorf("expected player %s to have 5 cards, got %d", p.ID, len(p.Cards))
+				}
+			}
+			if len(*test.mockSettings) != 0 {
+				t.Errorf("expected the settings of %d more players to be checked", len(*test.mockSettings))
+			}
+		})
+	}
+}
+
+func TestGameService_AutoPlay(t *testing.T) {
+	ctx := context.Background()
+
+	game := func(p2Cards []CardName) Game {
+		p1 := Player1()
+		p1.Cards = []CardName{TWO_CLUBS, ACE_SPADES, KING_SPADES}
+		p2 := Player2()
+		p2.Cards = p2Cards
+		return Game{
+			ID:       "1",
+			Name:     "Test Game",
+			Status:   Active,
+			Players:  []Player{p1, p2},
+			Revision: 10,
+			CurrentRound: Round{
+				DealerID: "2",
+				GoerID:   "1",
+				Status:   Playing,
+				Suit:     Hearts,
+				CurrentHand: Hand{
+					CurrentPlayerID: "1",
+					PlayedCards:     []PlayedCard{},
+				},
+				CompletedHands: []Hand{},
+				Number:         1,
+			},
+			AdminID: "1",
+		}
+	}
+
+	tests := []struct {
+		name             string
+		game             Game
+		mockSettings     *[]settings.Settings
+		expectedPlayed   CardName
+		expectedRevision int
+	}{
+		{
+			name:             "only one card can be played",
+			game:             game([]CardName{THREE_CLUBS, FIVE_DIAMONDS, SIX_DIAMONDS}),
+			mockSettings:     &[]settings.Settings{{ID: "2", AutoPlay: true}},
+			expectedPlayed:   THREE_CLUBS,
+			expectedRevision: 12,
+		},
+		{
+			name:             "auto play turned off",
+			game:             game([]CardName{THREE_CLUBS, FIVE_DIAMONDS, SIX_DIAMONDS}),
+			mockSettings:     &[]settings.Settings{{ID: "2", AutoPlay: false}},
+			expectedRevision: 11,
+		},
+		{
+			name:             "more than one card can be played",
+			game:             game([]CardName{THREE_CLUBS, FOUR_CLUBS, SIX_DIAMONDS}),
+			mockSettings:     &[]settings.Settings{{ID: "2", AutoPlay: true}},
+			expectedRevision: 11,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCol := &db.MockCollection[Game]{
+				MockFindOneResult: &[]Game{test.game},
+				MockFindOneExists: &[]bool{true},
+				MockFindOneErr:    &[]error{nil},
+			}
+			mockCache := &cache.MockCache[State]{
+				MockSetErr: &[]error{},
+			}
+			mockSettings := &settings.MockService{
+				MockGetResult: test.mockSettings,
+				MockGetExists: &[]bool{true},
+			}
+			ds := &Service{
+				Col:      mockCol,
+				Cache:    mockCache,
+				Settings: mockSettings,
+			}
+
+			result, err := ds.Play(ctx, test.game.ID, "1", TWO_CLUBS)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if result.Revision != test.expectedRevision {
+				t.Errorf("expected revision %d, got %d", test.expectedRevision, result.Revision)
+			}
+			if test.expectedPlayed == "" {
+				if result.CurrentRound.CurrentHand.CurrentPlayerID != "2" {
+					t.Errorf("expected player 2 to still have to play, got %s", result.CurrentRound.CurrentHand.CurrentPlayerID)
+				}
+				return
+			}
+			if len(result.CurrentRound.CompletedHands) != 1 {
+				t.Fatalf("expected the hand to be complete, got %d completed hands", len(result.CurrentRound.CompletedHands))
+			}
+			played := result.CurrentRound.CompletedHands[0].PlayedCards
+			if played[1].PlayerID != "2" || played[1].Card != test.expectedPlayed {
+				t.Errorf("expected player 2 to play %s, got %v", test.expectedPlayed, played[1])
+			}
+		})
+	}
+}
+
+func TestGameService_Rematch(t *testing.T) {
+	ctx := context.Background()
+	linked := GameWithCompletedRounds()
+	linked.NextGameID = "3"
+
+	tests := []struct {
+		name            string
+		adminID         string
+		mockGetResult   *[]Game
+		mockUpsertError *[]error
+		expectedCode    api.ErrorCode
+		expectingError  bool
+		expectedDeleted bool
+	}{
+		{
+			name:            "rematch",
+			adminID:         "1",
+			mockGetResult:   &[]Game{GameWithCompletedRounds(), GameWithCompletedRounds()},
+			mockUpsertError: &[]error{nil},
+		},
+		{
+			name:            "not admin",
+			adminID:         "2",
+			mockGetResult:   &[]Game{GameWithCompletedRounds()},
+			mockUpsertError: &[]error{nil},
+			expectedCode:    api.Forbidden,
+			expectingError:  true,
+		},
+		{
+			name:            "error saving the rematch",
+			adminID:         "1",
+			mockGetResult:   &[]Game{GameWithCompletedRounds()},
+			mockUpsertError: &[]error{errors.New("failed to upsert")},
+			expectedCode:    api.Internal,
+			expectingError:  true,
+		},
+		{
+			name:            "rematch created by someone else in the meantime",
+			adminID:         "1",
+			mockGetResult:   &[]Game{GameWithCompletedRounds(), linked},
+			mockUpsertError: &[]error{nil},
+			expectedCode:    api.Conflict,
+			expectingError:  true,
+			expectedDeleted: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCol := &db.MockCollection[Game]{
+				MockFindOneResult: test.mockGetResult,
+				MockFindOneExists: &[]bool{true, true},
+				MockFindOneErr:    &[]error{nil, nil},
+				MockUpsertErr:     test.mockUpsertError,
+				MockDeleteOneErr:  &[]error{nil},
+			}
+			mockCache := &cache.MockCache[State]{
+				MockSetErr: &[]error{},
+			}
+			ds := &Service{
+				Col:   mockCol,
+				Cache: mockCache,
+			}
+
+			rematch, err := ds.Rematch(ctx, "2", test.adminID)
+
+			if deleted := len(*mockCol.MockDeleteOneErr) == 0; deleted != test.expectedDeleted {
+				t.Errorf("expected the rematch to be deleted %v, got %v", test.expectedDeleted, deleted)
+			}
+			if test.expectingError {
+				if code := api.CodeOf(err); code != test.expectedCode {
+					t.Errorf("expected code %s, got %s (%v)", test.expectedCode, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if rematch.PreviousGameID != "2" || rematch.Status != Active {
+				t.Errorf("expected an active rematch of game 2, got %v", rematch)
+			}
+			if len(*test.mockUpsertError) != 0 {
+				t.Errorf("expected the rematch to be saved")
+			}
+		})
+	}
+}
+
+func TestGameService_OnCompleted(t *testing.T) {
+	ctx := context.Background()
+	// Random moves can leave both players passing forever, so record a few games until one is completed
+	var final Game
+	var history []Event
+	for attempt := 0; final.Status != Completed; attempt++ {
+		if attempt == 10 {
+			t.Fatalf("expected a game to be completed in %d attempts", attempt)
+		}
+		final, history = recordGame(t, []string{"1", "2"}, 10000)
+	}
+
+	// Find the last two cards played
+	var played []int
+	for i, event := range history {
+		if event.Type == EventCardPlayed {
+			played = append(played, i)
+		}
+	}
+
+	tests := []struct {
+		name              string
+		move              int
+		mockSetErr        *[]error
+		cancelled         bool
+		expectedCompleted int
+	}{
+		{
+			name:              "last card of the game",
+			move:              played[len(played)-1],
+			mockSetErr:        &[]error{},
+			expectedCompleted: 1,
+		},
+		{
+			name:              "game still in progress",
+			move:              played[len(played)-2],
+			mockSetErr:        &[]error{},
+			expectedCompleted: 0,
+		},
+		{
+			name:              "state cache fails",
+			move:              played[len(played)-1],
+			mockSetErr:        &[]error{errors.New("failed")},
+			expectedCompleted: 1,
+		},
+		{
+			name:              "request cancelled",
+			move:              played[len(played)-1],
+			mockSetErr:        &[]error{},
+			cancelled:         true,
+			expectedCompleted: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Replay the game to just before the move
+			game, err := replayUntil(history[:test.move], func(g *Game) bool { return false })
+			if err != nil {
+				t.Fatalf("failed to replay game: %v", err)
+			}
+			move := history[test.move]
+
+			mockCol := &db.MockCollection[Game]{
+				MockFindOneResult: &[]Game{game},
+				MockFindOneExists: &[]bool{true},
+				MockFindOneErr:    &[]error{nil},
+			}
+			mockCache := &cache.MockCache[State]{
+				MockSetErr: test.mockSetErr,
+			}
+			ds := &Service{
+				Col:   mockCol,
+				Cache: mockCache,
+			}
+			var completed []Game
+			ds.OnCompleted(func(ctx context.Context, game Game) error {
+				if ctx.Err() != nil {
+					t.Errorf("expected the hook's context not to be cancelled, got %v", ctx.Err())
+				}
+				completed = append(completed, game)
+				return nil
+			})
+
+			requestCtx, cancel := context.WithCancel(ctx)
+			if test.cancelled {
+				cancel()
+			}
+			defer cancel()
+
+			// The move has been saved, so it succeeds even if the cache can't be updated
+			_, err = ds.Play(requestCtx, game.ID, move.PlayerID, move.Card)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(completed) != test.expectedCompleted {
+				t.Fatalf("expected the hook to be called %d times, got %d", test.expectedCompleted, len(completed))
+			}
+			if test.expectedCompleted > 0 && completed[0].Status != Completed {
+				t.Errorf("expected the completed game to be passed to the hook, got %s", completed[0].Status)
+			}
+		})
+	}
+}
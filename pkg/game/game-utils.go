@@ -1,6 +1,7 @@
 package game
 
 import (
+	"cards-110-api/pkg/api"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -12,7 +13,7 @@ import (
 func validateNumberOfPlayers(playerIDs []string) error {
 	// Validate number of players is in the range 2-6
 	if len(playerIDs) < 2 || len(playerIDs) > 6 {
-		return fmt.Errorf("invalid number of players (%d)", len(playerIDs))
+		return api.Errorf(api.InvalidRequest, "invalid number of players (%d)", len(playerIDs))
 	}
 	return nil
 }
@@ -32,14 +33,14 @@ func ParseCall(c string) (Call, error) {
 	case "30":
 		return Jink, nil
 	default:
-		return 0, fmt.Errorf("invalid call")
+		return 0, api.Errorf(api.InvalidRequest, "invalid call")
 	}
 }
 
-// shuffle a slice of strings
-func shuffle(input []string) []string {
+// shuffle a slice of strings using the given source of randomness
+func shuffle(input []string, rng *rand.Rand) []string {
 	shuffled := make([]string, len(input))
-	perm := rand.Perm(len(input))
+	perm := rng.Perm(len(input))
 
 	for i, v := range perm {
 		shuffled[v] = input[i]
@@ -48,6 +49,25 @@ func shuffle(input []string) []string {
 	return shuffled
 }
 
+// newSeed returns a random seed for a new game.
+func newSeed() int64 {
+	return rand.Int63()
+}
+
+// newRand returns a source of randomness that always produces the same sequence for the same seed.
+func newRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// roundSeed derives the seed for a round from the game's seed so each round is dealt differently but reproducibly.
+func roundSeed(gameSeed int64, round int) int64 {
+	// splitmix64 finaliser, spreads consecutive round numbers across the whole range
+	z := uint64(gameSeed) + uint64(round)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return int64(z ^ (z >> 31))
+}
+
 // createPlayers Create the players for a game.
 // There can only be 2-6 players
 // If there are 6 players we play 3 teams of 2
@@ -95,7 +115,7 @@ func nextPlayer(players []Player, currentPlayerId string) (Player, error) {
 	return players[nextIndex], nil
 }
 
-func createFirstRound(players []Player, dealerID string) (Round, error) {
+func createFirstRound(players []Player, dealerID string, gameSeed int64) (Round, error) {
 	timestamp := time.Now()
 
 	// Create the current hand
@@ -113,6 +133,7 @@ func createFirstRound(players []Player, dealerID string) (Round, error) {
 	round := Round{
 		Timestamp:      timestamp,
 		Number:         1,
+		Seed:           roundSeed(gameSeed, 1),
 		DealerID:       dealerID,
 		Status:         Calling,
 		CurrentHand:    hand,
@@ -122,7 +143,18 @@ func createFirstRound(players []Player, dealerID string) (Round, error) {
 	return round, nil
 }
 
+// newGameID returns a random ID for a game.
+func newGameID() string {
+	return strconv.Itoa(rand.Intn(10000000))
+}
+
 func NewGame(playerIDs []string, name string, adminID string) (Game, error) {
+	return NewGameWithSeed(playerIDs, name, adminID, newSeed())
+}
+
+// NewGameWithSeed creates a new game where the seating and every deal are derived from the seed.
+// The same seed and the same sequence of moves will always produce the same game.
+func NewGameWithSeed(playerIDs []string, name string, adminID string, seed int64) (Game, error) {
 	// Validate number of players is in the range 2-6
 	err := validateNumberOfPlayers(playerIDs)
 	if err != nil {
@@ -138,11 +170,11 @@ func NewGame(playerIDs []string, name string, adminID string) (Game, error) {
 		}
 	}
 	if !adminFound {
-		return Game{}, errors.New("admin not found in players")
+		return Game{}, api.Errorf(api.InvalidRequest, "admin not found in players")
 	}
 
 	// Randomise the order of the players
-	shuffledPlayerIDs := shuffle(playerIDs)
+	shuffledPlayerIDs := shuffle(playerIDs, newRand(seed))
 
 	// Create the players
 	players, err := createPlayers(shuffledPlayerIDs)
@@ -150,12 +182,19 @@ func NewGame(playerIDs []string, name string, adminID string) (Game, error) {
 		return Game{}, err
 	}
 
-	// Assign a dealer and create the first round
-	dealer := playerIDs[0]
-	round, err := createFirstRound(players, dealer)
+	// Assign a dealer and deal the first round
+	return dealGame(players, playerIDs[0], name, adminID, seed)
+}
+
+// dealGame creates a game with the players in their seats and deals the first round.
+func dealGame(players []Player, dealerID string, name string, adminID string, seed int64) (Game, error) {
+	round, err := createFirstRound(players, dealerID, seed)
+	if err != nil {
+		return Game{}, err
+	}
 
 	// Deal the cards
-	deck, dummy, hands, err := DealCards(ShuffleCards(NewDeck()), len(players))
+	deck, dummy, hands, err := DealCards(ShuffleCards(NewDeck(), newRand(round.Seed)), len(players))
 	if err != nil {
 		return Game{}, err
 	}
@@ -166,11 +205,12 @@ func NewGame(playerIDs []string, name string, adminID string) (Game, error) {
 
 	// Create the game
 	game := Game{
-		ID:           strconv.Itoa(rand.Intn(10000000)),
+		ID:           newGameID(),
 		Timestamp:    time.Now(),
 		Name:         name,
 		Status:       Active,
 		AdminID:      adminID,
+		Seed:         seed,
 		Players:      players,
 		Dummy:        dummy,
 		CurrentRound: round,
@@ -180,6 +220,26 @@ func NewGame(playerIDs []string, name string, adminID string) (Game, error) {
 	return game, nil
 }
 
+// newGame creates a game for the players and bots, played with the given rules.
+func newGame(playerIDs []string, bots []BotType, rules Rules, name string, adminID string) (Game, error) {
+	// Fill the remaining seats with bots
+	playerIDs, botIDs, err := addBots(playerIDs, bots)
+	if err != nil {
+		return Game{}, err
+	}
+
+	game, err := NewGame(playerIDs, name, adminID)
+	if err != nil {
+		return Game{}, err
+	}
+	game.Rules = &rules
+	for i, p := range game.Players {
+		game.Players[i].Bot = botIDs[p.ID]
+	}
+
+	return game, nil
+}
+
 // containsAllUnique checks if targetSlice contains all unique elements of referenceSlice.
 func containsAllUnique(referenceSlice, targetSlice []CardName) bool {
 	if len(targetSlice) > len(referenceSlice) {
@@ -263,6 +323,48 @@ func isFollowing(myCard CardName, myCards []CardName, currentHand Hand, suit Sui
 		mySuit == leadOut.Suit
 }
 
+// playableCards returns the cards that can be played into the current hand.
+func playableCards(myCards []CardName, currentHand Hand, suit Suit) []CardName {
+	if currentHand.LeadOut == "" {
+		return myCards
+	}
+	cards := make([]CardName, 0)
+	for _, card := range myCards {
+		if isFollowing(card, myCards, currentHand, suit) {
+			cards = append(cards, card)
+		}
+	}
+	return cards
+}
+
+// followSuitViolation explains which cards could have been played into the current hand.
+// A wild card leading out is a trump lead so the led suit is the trump suit.
+func followSuitViolation(myCards []CardName, currentHand Hand, suit Suit) FollowSuitViolation {
+	leadOut := currentHand.LeadOut.Card()
+	ledSuit := leadOut.Suit
+	if ledSuit == Wild {
+		ledSuit = suit
+	}
+
+	// When trumps are led the player can hold back any renegable trump that beats the lead out, as long as they play
+	// one of their other trumps
+	var renegable []CardName
+	if ledSuit == suit {
+		for _, card := range myCards {
+			if isTrump(card, suit) && card.Card().Renegable && card.Card().Value > leadOut.Value {
+				renegable = append(renegable, card)
+			}
+		}
+	}
+
+	return FollowSuitViolation{
+		LedSuit:    ledSuit,
+		Trumps:     suit,
+		LegalCards: playableCards(myCards, currentHand, suit),
+		Renegable:  renegable,
+	}
+}
+
 // getActiveSuit Was a suit or wild card played? If not set the lead out card as the suit
 func getActiveSuit(hand Hand, suit Suit) (Suit, error) {
 	if suit == "" {
@@ -364,6 +466,20 @@ func findWinningCardsForRound(round Round) ([]PlayedCard, error) {
 	return winningCards, nil
 }
 
+// Tricks returns the winning card of each hand of a round that was played out, along with the best trump played if
+// there was one. The best trump is worth 10 points and every other trick is worth 5.
+func (r Round) Tricks() ([]PlayedCard, PlayedCard, bool, error) {
+	winningCards, err := findWinningCardsForRound(r)
+	if err != nil {
+		return nil, PlayedCard{}, false, err
+	}
+	bestCard, trumpPlayed, err := findBestTrump(winningCards, r.Suit)
+	if err != nil {
+		return nil, PlayedCard{}, false, err
+	}
+	return winningCards, bestCard, trumpPlayed, nil
+}
+
 func findPlayer(playerID string, players []Player) (Player, error) {
 	for _, player := range players {
 		if player.ID == playerID {
@@ -411,16 +527,16 @@ func checkForJink(winningCards []PlayedCard, players []Player, goerId string) (b
 	return true, nil
 }
 
-func findWinningTeam(players []Player, round Round) (string, error) {
-	// 1. If only one team >= 110 -> they are the winner
-	winningTeams := getTeamsOver110(players)
+func findWinningTeam(players []Player, round Round, target int) (string, error) {
+	// 1. If only one team >= target -> they are the winner
+	winningTeams := getTeamsOverTarget(players, target)
 	if len(winningTeams) == 1 {
 		for teamID := range winningTeams {
 			return teamID, nil
 		}
 	}
 
-	// 2. If more than one team >= 110 but one is the goer -> the goer is the winning team
+	// 2. If more than one team >= target but one is the goer -> the goer is the winning team
 	goerTeamID := ""
 	for _, player := range players {
 		if player.ID == round.GoerID {
@@ -432,21 +548,21 @@ func findWinningTeam(players []Player, round Round) (string, error) {
 		return goerTeamID, nil
 	}
 
-	// 3. Else first team >= 110 is the winner
-	return findFirstTeamToPass110(players, round)
+	// 3. Else first team >= target is the winner
+	return findFirstTeamToPassTarget(players, round, target)
 }
 
-func getTeamsOver110(players []Player) map[string]bool {
-	teamsOver110 := make(map[string]bool)
+func getTeamsOverTarget(players []Player, target int) map[string]bool {
+	teamsOverTarget := make(map[string]bool)
 	for _, player := range players {
-		if player.Score >= 110 {
-			teamsOver110[player.TeamID] = true
+		if player.Score >= target {
+			teamsOverTarget[player.TeamID] = true
 		}
 	}
-	return teamsOver110
+	return teamsOverTarget
 }
 
-func findFirstTeamToPass110(players []Player, round Round) (string, error) {
+func findFirstTeamToPassTarget(players []Player, round Round, target int) (string, error) {
 	winningCards, err := findWinningCardsForRound(round)
 	if err != nil {
 		return "", err
@@ -457,7 +573,7 @@ func findFirstTeamToPass110(players []Player, round Round) (string, error) {
 		return "", err
 	}
 
-	// Go backwards through the hands and find the first team to pass 110
+	// Go backwards through the hands and find the first team to pass the target
 	for i := len(winningCards) - 1; i >= 0; i-- {
 		card := winningCards[i]
 		if err != nil {
@@ -477,7 +593,7 @@ func findFirstTeamToPass110(players []Player, round Round) (string, error) {
 				}
 			}
 		}
-		winningTeams := getTeamsOver110(players)
+		winningTeams := getTeamsOverTarget(players, target)
 		if len(winningTeams) == 1 {
 			for t := range winningTeams {
 				return t, nil
@@ -1,6 +1,10 @@
 package game
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestGameUtils_validateNumberOfPlayers(t *testing.T) {
 	tests := []struct {
@@ -134,7 +138,7 @@ func TestGameUtils_shuffle(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			shuffled := shuffle(test.input)
+			shuffled := shuffle(test.input, newRand(newSeed()))
 
 			expectedSize := len(test.input)
 			if len(shuffled) != expectedSize {
@@ -1017,7 +1021,7 @@ func TestGameUtils_findWinningTeam(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, err := findWinningTeam(test.players, test.round)
+			result, err := findWinningTeam(test.players, test.round, 110)
 			if test.expectingError {
 				if err == nil {
 					t.Errorf("expected an error, got nil")
@@ -1034,7 +1038,7 @@ func TestGameUtils_findWinningTeam(t *testing.T) {
 	}
 }
 
-func TestGameUtils_getTeamsOver110(t *testing.T) {
+func TestGameUtils_getTeamsOverTarget(t *testing.T) {
 	tests := []struct {
 		name           string
 		players        []Player
@@ -1071,7 +1075,7 @@ func TestGameUtils_getTeamsOver110(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result := getTeamsOver110(test.players)
+			result := getTeamsOverTarget(test.players, 110)
 			if len(result) != len(test.expectedResult) {
 				t.Errorf("expected %d teams, got %d", len(test.expectedResult), len(result))
 			}
@@ -1295,3 +1299,79 @@ func TestGameUtils_CalculateScores(t *testing.T) {
 	}
 
 }
+
+func TestGameUtils_NewGameWithSeed(t *testing.T) {
+	tests := []struct {
+		name      string
+		playerIDs []string
+		moves     int
+	}{
+		{
+			name:      "Two players",
+			playerIDs: []string{"1", "2"},
+			moves:     60,
+		},
+		{
+			name:      "Five players",
+			playerIDs: []string{"1", "2", "3", "4", "5"},
+			moves:     200,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Play the same moves in two games with the same seed
+			var games [2]Game
+			for i := range games {
+				g, err := NewGameWithSeed(test.playerIDs, "test", "1", 1234)
+				if err != nil {
+					t.Fatalf("failed to create game: %v", err)
+				}
+				g.ID = "1"
+				now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				g.Timestamp = now
+				g.CurrentRound.Timestamp = now
+				g.CurrentRound.CurrentHand.Timestamp = now
+
+				var history []Event
+				for m := 0; m < test.moves && g.Status == Active; m++ {
+					now = now.Add(time.Minute)
+					playMove(t, &g, now, &history)
+				}
+				g.clock = nil
+				games[i] = g
+			}
+
+			if games[0].CurrentRound.Number < 2 {
+				t.Fatalf("expected at least one round to be dealt after the first, got round %d", games[0].CurrentRound.Number)
+			}
+			if !reflect.DeepEqual(games[0], games[1]) {
+				t.Errorf("expected the same seed and moves to produce the same game\nfirst:  %+v\nsecond: %+v", games[0], games[1])
+			}
+
+			// A different seed should deal different cards
+			other, err := NewGameWithSeed(test.playerIDs, "test", "1", 4321)
+			if err != nil {
+				t.Fatalf("failed to create game: %v", err)
+			}
+			first, _ := NewGameWithSeed(test.playerIDs, "test", "1", 1234)
+			if reflect.DeepEqual(first.Deck, other.Deck) {
+				t.Errorf("expected different seeds to deal different cards")
+			}
+		})
+	}
+}
+
+func TestGameUtils_roundSeed(t *testing.T) {
+	seen := make(map[int64]bool)
+	for round := 1; round <= 100; round++ {
+		seed := roundSeed(1234, round)
+		if seen[seed] {
+			t.Errorf("expected a different seed for each round, got %d twice", seed)
+		}
+		seen[seed] = true
+		if seed != roundSeed(1234, round) {
+			t.Errorf("expected the same seed for the same round")
+		}
+	}
+}
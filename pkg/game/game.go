@@ -9,6 +9,8 @@ type Status string
 const (
 	Active    Status = "ACTIVE"
 	Completed        = "COMPLETED"
+	// Lobby is a game that players are still joining, it becomes Active once it is started
+	Lobby = "LOBBY"
 )
 
 type RoundStatus string
@@ -41,6 +43,7 @@ type Player struct {
 	Rings  int        `bson:"rings" json:"rings"`
 	TeamID string     `bson:"teamId" json:"teamId"`
 	Winner bool       `bson:"winner" json:"winner"`
+	Bot    BotType    `bson:"bot,omitempty" json:"bot,omitempty"`
 }
 
 type PlayedCard struct {
@@ -48,6 +51,7 @@ type PlayedCard struct {
 	Card     CardName `bson:"card" json:"card"`
 }
 
+// Hand is a card played by each player in turn. Timestamp is when the current player's go started.
 type Hand struct {
 	Timestamp       time.Time    `bson:"timestamp" json:"timestamp"`
 	LeadOut         CardName     `bson:"leadOut" json:"leadOut,omitempty"`
@@ -55,9 +59,23 @@ type Hand struct {
 	PlayedCards     []PlayedCard `bson:"playedCards" json:"playedCards"`
 }
 
+// PlayerCall is a call made by a player during a round.
+type PlayerCall struct {
+	PlayerID string `bson:"playerId" json:"playerId"`
+	Call     Call   `bson:"call" json:"call"`
+}
+
+// RoundScore is how much a player scored in a round and their score at the end of it.
+type RoundScore struct {
+	PlayerID string `bson:"playerId" json:"playerId"`
+	Points   int    `bson:"points" json:"points"`
+	Total    int    `bson:"total" json:"total"`
+}
+
 type Round struct {
 	Timestamp      time.Time   `bson:"timestamp" json:"timestamp"`
 	Number         int         `bson:"number" json:"number"`
+	Seed           int64       `bson:"seed" json:"-"`
 	DealerID       string      `bson:"dealerId" json:"dealerId"`
 	GoerID         string      `bson:"goerId" json:"goerId,omitempty"`
 	Suit           Suit        `bson:"suit" json:"suit,omitempty"`
@@ -65,35 +83,116 @@ type Round struct {
 	CurrentHand    Hand        `bson:"currentHand" json:"currentHand"`
 	DealerSeeing   bool        `bson:"dealerSeeingCall" json:"dealerSeeingCall"`
 	CompletedHands []Hand      `bson:"completedHands" json:"completedHands"`
+	// Calls are the calls made in the round, in the order they were made
+	Calls []PlayerCall `bson:"calls,omitempty" json:"calls,omitempty"`
+	// Scores are only set for rounds that were played out
+	Scores []RoundScore `bson:"scores,omitempty" json:"scores,omitempty"`
 }
 
 type Game struct {
-	ID           string     `bson:"_id,omitempty" json:"id"`
-	Revision     int        `bson:"revision" json:"revision"`
-	AdminID      string     `bson:"adminId" json:"adminId"`
-	Timestamp    time.Time  `bson:"timestamp" json:"timestamp"`
-	Name         string     `bson:"name" json:"name"`
-	Status       Status     `bson:"status" json:"status"`
-	Players      []Player   `bson:"players" json:"players"`
-	Dummy        []CardName `bson:"dummy" json:"-"`
-	CurrentRound Round      `bson:"currentRound" json:"-"`
-	Completed    []Round    `bson:"completedRounds" json:"-"`
-	Deck         []CardName `bson:"deck" json:"-"`
+	ID        string    `bson:"_id,omitempty" json:"id"`
+	Revision  int       `bson:"revision" json:"revision"`
+	AdminID   string    `bson:"adminId" json:"adminId"`
+	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
+	Name      string    `bson:"name" json:"name"`
+	Status    Status    `bson:"status" json:"status"`
+	Seed      int64     `bson:"seed" json:"-"`
+	Rules     *Rules    `bson:"rules,omitempty" json:"rules,omitempty"`
+	Invited   []string  `bson:"invited,omitempty" json:"invited,omitempty"`
+	// JoinCode is kept out of the game's JSON as anyone can list the games, only the admin is given it
+	JoinCode       string `bson:"joinCode,omitempty" json:"-"`
+	PreviousGameID string `bson:"previousGameId,omitempty" json:"previousGameId,omitempty"`
+	NextGameID     string `bson:"nextGameId,omitempty" json:"nextGameId,omitempty"`
+	// HistoryIncomplete is set if the events of a move couldn't be saved, so the game can no longer be replayed
+	HistoryIncomplete bool       `bson:"historyIncomplete,omitempty" json:"-"`
+	Players           []Player   `bson:"players" json:"players"`
+	Dummy             []CardName `bson:"dummy" json:"-"`
+	CurrentRound      Round      `bson:"currentRound" json:"-"`
+	Completed         []Round    `bson:"completedRounds" json:"-"`
+	Deck              []CardName `bson:"deck" json:"-"`
+
+	// clock is used for timestamps in place of the wall clock when replaying or applying a move
+	clock func() time.Time
+	// events produced by moves that haven't been saved yet
+	events []Event
+	// quiet stops the game logging, used by bots when simulating moves
+	quiet bool
+	// sealed is the number of events that have been stamped with the revision they produced
+	sealed int
 }
 
 type State struct {
-	ID           string     `json:"id"`
-	Revision     int        `bson:"revision" json:"revision"`
-	Status       Status     `json:"status"`
-	Me           Player     `json:"me"`
-	IamSpectator bool       `json:"iamSpectator"`
-	IsMyGo       bool       `json:"isMyGo"`
-	IamGoer      bool       `json:"iamGoer"`
-	IamDealer    bool       `json:"iamDealer"`
-	IamAdmin     bool       `json:"iamAdmin"`
-	MaxCall      Call       `json:"maxCall"`
-	Players      []Player   `json:"players"`
-	Round        Round      `json:"round"`
-	PrevRound    Round      `json:"previousRound"`
-	Cards        []CardName `json:"cards"`
+	ID             string     `json:"id"`
+	Revision       int        `bson:"revision" json:"revision"`
+	Status         Status     `json:"status"`
+	Me             Player     `json:"me"`
+	IamSpectator   bool       `json:"iamSpectator"`
+	IsMyGo         bool       `json:"isMyGo"`
+	IamGoer        bool       `json:"iamGoer"`
+	IamDealer      bool       `json:"iamDealer"`
+	IamAdmin       bool       `json:"iamAdmin"`
+	MaxCall        Call       `json:"maxCall"`
+	Players        []Player   `json:"players"`
+	Round          Round      `json:"round"`
+	PrevRound      Round      `json:"previousRound"`
+	Cards          []CardName `json:"cards"`
+	Seed           int64      `json:"seed,omitempty"`
+	Rules          Rules      `json:"rules"`
+	Moves          *Moves     `json:"moves,omitempty"`
+	PreviousGameID string     `json:"previousGameId,omitempty"`
+	NextGameID     string     `json:"nextGameId,omitempty"`
+	// JoinCode is only given to the admin of a lobby
+	JoinCode string `json:"joinCode,omitempty"`
+}
+
+// Keep is the choice of cards to keep when selecting a suit or buying.
+type Keep struct {
+	Min  int        `json:"min"`
+	Max  int        `json:"max"`
+	From []CardName `json:"from"`
+}
+
+// Moves is the set of legal moves for the current player. Only the moves for the current stage of the round are set.
+type Moves struct {
+	Calls []Call     `json:"calls,omitempty"`
+	Suits []Suit     `json:"suits,omitempty"`
+	Keep  *Keep      `json:"keep,omitempty"`
+	Cards []CardName `json:"cards,omitempty"`
+}
+
+// FollowSuitViolation explains why a card couldn't be played into a hand.
+type FollowSuitViolation struct {
+	LedSuit    Suit       `json:"ledSuit"`
+	Trumps     Suit       `json:"trumps"`
+	LegalCards []CardName `json:"legalCards"`
+	// Renegable are the trumps the player could have held back, they only had to play one of their other trumps
+	Renegable []CardName `json:"renegable,omitempty"`
+}
+
+// FullPlayer is a player along with the cards in their hand.
+type FullPlayer struct {
+	Player
+	Cards []CardName `json:"cards"`
+}
+
+// FullState is the complete state of a game, including everyone's cards.
+// It gives away every hand so it is only ever shared once the game is over.
+type FullState struct {
+	ID        string       `json:"id"`
+	Revision  int          `json:"revision"`
+	Name      string       `json:"name"`
+	Status    Status       `json:"status"`
+	Seed      int64        `json:"seed"`
+	Players   []FullPlayer `json:"players"`
+	Dummy     []CardName   `json:"dummy"`
+	Deck      []CardName   `json:"deck"`
+	Round     Round        `json:"round"`
+	Completed []Round      `json:"completedRounds"`
+}
+
+// Replayed is a game as it stood at a point in its history.
+// Completed games are returned in full, for active games only the caller's state is returned.
+type Replayed struct {
+	Game  *FullState `json:"game,omitempty"`
+	State *State     `json:"state,omitempty"`
 }
@@ -1,6 +1,11 @@
 package game
 
-import "testing"
+import (
+	"cards-110-api/pkg/api"
+	"errors"
+	"reflect"
+	"testing"
+)
 
 func TestGame_Me(t *testing.T) {
 	tests := []struct {
@@ -1317,3 +1322,268 @@ func TestGame_Play(t *testing.T) {
 		})
 	}
 }
+
+func TestGame_GetStateSeed(t *testing.T) {
+	tests := []struct {
+		name         string
+		game         Game
+		playerID     string
+		expectedSeed int64
+	}{
+		{
+			name:     "Seed hidden from players while the game is active",
+			game:     func() Game { g := TwoPlayerGame(); g.Seed = 1234; return g }(),
+			playerID: "1",
+		},
+		{
+			name:     "Seed hidden from spectators while the game is active",
+			game:     func() Game { g := TwoPlayerGame(); g.Seed = 1234; return g }(),
+			playerID: "3",
+		},
+		{
+			name:         "Seed revealed once the game is completed",
+			game:         func() Game { g := CompletedGame(); g.Seed = 1234; return g }(),
+			playerID:     "1",
+			expectedSeed: 1234,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := test.game.GetState(test.playerID)
+			if state.Seed != test.expectedSeed {
+				t.Errorf("expected seed %d, got %d", test.expectedSeed, state.Seed)
+			}
+		})
+	}
+}
+
+func TestGame_LegalMoves(t *testing.T) {
+	dealerSeeing := TwoPlayerGame()
+	dealerSeeing.Players[1].Call = Twenty
+	dealerSeeing.CurrentRound.CurrentHand.CurrentPlayerID = "1"
+
+	inBunker := TwoPlayerGame()
+	inBunker.Players[1].Score = -35
+
+	tests := []struct {
+		name           string
+		game           Game
+		playerID       string
+		expectedMoves  Moves
+		expectingError bool
+	}{
+		{
+			name:          "Calling",
+			game:          TwoPlayerGame(),
+			playerID:      "2",
+			expectedMoves: Moves{Calls: []Call{Pass, Fifteen, Twenty, TwentyFive, Jink}},
+		},
+		{
+			name:          "Calling 10 in doubles",
+			game:          SixPlayerGame(),
+			playerID:      "5",
+			expectedMoves: Moves{Calls: []Call{Pass, Ten, Fifteen, Twenty, TwentyFive, Jink}},
+		},
+		{
+			name:          "Dealer can see a call",
+			game:          dealerSeeing,
+			playerID:      "1",
+			expectedMoves: Moves{Calls: []Call{Pass, Twenty, TwentyFive, Jink}},
+		},
+		{
+			name:          "In the bunker",
+			game:          inBunker,
+			playerID:      "2",
+			expectedMoves: Moves{Calls: []Call{Pass}},
+		},
+		{
+			name:     "Selecting suit",
+			game:     CalledGameFivePlayers(),
+			playerID: "PlayerCalled",
+			expectedMoves: Moves{
+				Suits: []Suit{Clubs, Diamonds, Hearts, Spades},
+				Keep: &Keep{
+					Min:  1,
+					Max:  5,
+					From: append(PlayerCalled().Cards, Dummy()...),
+				},
+			},
+		},
+		{
+			name:     "Buying",
+			game:     BuyingGame("1"),
+			playerID: "2",
+			expectedMoves: Moves{
+				Keep: &Keep{Min: 0, Max: 5, From: BuyingGame("1").Players[1].Cards},
+			},
+		},
+		{
+			name:          "Leading out",
+			game:          PlayingGame_RoundStart("1"),
+			playerID:      "1",
+			expectedMoves: Moves{Cards: PlayingGame_RoundStart("1").Players[0].Cards},
+		},
+		{
+			name:          "Following suit",
+			game:          PlayingGame_RoundStart_FirstCardPlayed(),
+			playerID:      "2",
+			expectedMoves: Moves{Cards: []CardName{THREE_CLUBS, FIVE_CLUBS, ACE_HEARTS}},
+		},
+		{
+			name:           "Not my go",
+			game:           TwoPlayerGame(),
+			playerID:       "1",
+			expectingError: true,
+		},
+		{
+			name:           "Completed game",
+			game:           CompletedGame(),
+			playerID:       "1",
+			expectingError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			moves, err := test.game.LegalMoves(test.playerID)
+			if test.expectingError {
+				if err == nil {
+					t.Errorf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(moves, test.expectedMoves) {
+				t.Errorf("expected moves %+v, got %+v", test.expectedMoves, moves)
+			}
+
+			// The moves are included in the state for the current player
+			state := test.game.GetState(test.playerID)
+			if state.Moves == nil || !reflect.DeepEqual(*state.Moves, test.expectedMoves) {
+				t.Errorf("expected state to include moves %+v, got %+v", test.expectedMoves, state.Moves)
+			}
+		})
+	}
+}
+
+func TestGame_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name         string
+		game         Game
+		move         func(g *Game) error
+		expectedCode api.ErrorCode
+	}{
+		{
+			name:         "Not your turn to call",
+			game:         TwoPlayerGame(),
+			move:         func(g *Game) error { return g.Call("1", Fifteen) },
+			expectedCode: api.NotYourTurn,
+		},
+		{
+			name:         "Call too low",
+			game:         TwoPlayerGame(),
+			move:         func(g *Game) error { return g.Call("2", Ten) },
+			expectedCode: api.IllegalMove,
+		},
+		{
+			name:         "Buy while calling",
+			game:         TwoPlayerGame(),
+			move:         func(g *Game) error { return g.Buy("2", []CardName{}) },
+			expectedCode: api.WrongPhase,
+		},
+		{
+			name:         "Not following suit",
+			game:         PlayingGame_RoundStart_FirstCardPlayed(),
+			move:         func(g *Game) error { return g.Play("2", FOUR_DIAMONDS) },
+			expectedCode: api.IllegalMove,
+		},
+		{
+			name:         "Not your turn to play",
+			game:         PlayingGame_RoundStart_FirstCardPlayed(),
+			move:         func(g *Game) error { return g.Play("1", KING_CLUBS) },
+			expectedCode: api.NotYourTurn,
+		},
+		{
+			name:         "Start a game that isn't in the lobby",
+			game:         TwoPlayerGame(),
+			move:         func(g *Game) error { return g.Start("1", nil) },
+			expectedCode: api.WrongPhase,
+		},
+		{
+			name: "Start someone else's game",
+			game: func() Game {
+				rules, _ := NewRules(HouseRules)
+				return NewLobby("test", "1", rules, nil)
+			}(),
+			move:         func(g *Game) error { return g.Start("2", nil) },
+			expectedCode: api.Forbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.move(&test.game)
+			if err == nil {
+				t.Fatalf("expected an error, got nil")
+			}
+			if code := api.CodeOf(err); code != test.expectedCode {
+				t.Errorf("expected code %s, got %s (%v)", test.expectedCode, code, err)
+			}
+		})
+	}
+}
+
+func TestGame_FollowSuitViolation(t *testing.T) {
+	heartsTrumps := PlayingGame_RoundStart_FirstCardPlayed()
+	heartsTrumps.CurrentRound.Suit = Hearts
+
+	tests := []struct {
+		name              string
+		game              Game
+		card              CardName
+		expectedViolation FollowSuitViolation
+	}{
+		{
+			name: "Trumps led with trumps that could be held back",
+			game: PlayingGame_RoundStart_FirstCardPlayed(),
+			card: FOUR_DIAMONDS,
+			expectedViolation: FollowSuitViolation{
+				LedSuit:    Clubs,
+				Trumps:     Clubs,
+				LegalCards: []CardName{THREE_CLUBS, FIVE_CLUBS, ACE_HEARTS},
+				Renegable:  []CardName{FIVE_CLUBS, ACE_HEARTS},
+			},
+		},
+		{
+			name: "Cold suit led",
+			game: heartsTrumps,
+			card: FOUR_DIAMONDS,
+			expectedViolation: FollowSuitViolation{
+				LedSuit:    Clubs,
+				Trumps:     Hearts,
+				LegalCards: []CardName{TWO_HEARTS, THREE_CLUBS, FIVE_CLUBS, ACE_HEARTS},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.game.Play("2", test.card)
+
+			var apiErr *api.Error
+			if !errors.As(err, &apiErr) || apiErr.Code != api.IllegalMove {
+				t.Fatalf("expected an illegal move, got %v", err)
+			}
+			violation, ok := apiErr.Details.(FollowSuitViolation)
+			if !ok {
+				t.Fatalf("expected the violation to be explained, got %v", apiErr.Details)
+			}
+			if !reflect.DeepEqual(violation, test.expectedViolation) {
+				t.Errorf("expected %+v, got %+v", test.expectedViolation, violation)
+			}
+		})
+	}
+}
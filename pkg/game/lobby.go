@@ -0,0 +1,175 @@
+package game
+
+import (
+	"cards-110-api/pkg/api"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// joinCodeChars are the characters used in join codes, leaving out the ones that are easily confused.
+const joinCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// maxPlayers is the most players that can sit at a table.
+const maxPlayers = 6
+
+// newJoinCode returns a random code players can use to join a lobby.
+func newJoinCode() string {
+	code := make([]byte, 6)
+	for i := range code {
+		code[i] = joinCodeChars[rand.Intn(len(joinCodeChars))]
+	}
+	return string(code)
+}
+
+// NewLobby creates an open table that players can join before the game is started.
+func NewLobby(name string, adminID string, rules Rules, invited []string) Game {
+	lobby := Game{
+		ID:        newGameID(),
+		Timestamp: time.Now(),
+		Name:      name,
+		Status:    Lobby,
+		AdminID:   adminID,
+		Rules:     &rules,
+		JoinCode:  newJoinCode(),
+		Players:   []Player{{ID: adminID}},
+		Invited:   make([]string, 0),
+	}
+	for _, id := range invited {
+		if id != adminID && !slices.Contains(lobby.Invited, id) {
+			lobby.Invited = append(lobby.Invited, id)
+		}
+	}
+	return lobby
+}
+
+// validateLobby checks the game hasn't started yet.
+func (g *Game) validateLobby() error {
+	if g.Status != Lobby {
+		return api.Errorf(api.WrongPhase, "game has already started")
+	}
+	return nil
+}
+
+// hasJoined checks if the player has joined the game.
+func (g *Game) hasJoined(playerID string) bool {
+	_, err := g.Me(playerID)
+	return err == nil
+}
+
+// Invite players to join the game. Players that have already joined or been invited are ignored.
+func (g *Game) Invite(adminID string, playerIDs []string) error {
+	if err := g.validateLobby(); err != nil {
+		return err
+	}
+	if g.AdminID != adminID {
+		return api.Errorf(api.Forbidden, "not admin")
+	}
+
+	for _, id := range playerIDs {
+		if !g.hasJoined(id) && !slices.Contains(g.Invited, id) {
+			g.Invited = append(g.Invited, id)
+		}
+	}
+
+	g.Revision++
+	return nil
+}
+
+// join adds the player to the table.
+func (g *Game) join(playerID string) error {
+	if g.hasJoined(playerID) {
+		return api.Errorf(api.Conflict, "already joined")
+	}
+	if len(g.Players) >= maxPlayers {
+		return api.Errorf(api.Conflict, "table is full")
+	}
+
+	g.Players = append(g.Players, Player{ID: playerID})
+	g.Invited = slices.DeleteFunc(g.Invited, func(id string) bool { return id == playerID })
+
+	g.Revision++
+	return nil
+}
+
+// Accept an invitation to join the game.
+func (g *Game) Accept(playerID string) error {
+	if err := g.validateLobby(); err != nil {
+		return err
+	}
+	if !slices.Contains(g.Invited, playerID) {
+		return api.Errorf(api.Forbidden, "not invited")
+	}
+	return g.join(playerID)
+}
+
+// Join the game with its join code, no invitation is needed.
+func (g *Game) Join(playerID string, code string) error {
+	if err := g.validateLobby(); err != nil {
+		return err
+	}
+	if code == "" || g.JoinCode != code {
+		return api.Errorf(api.NotFound, "invalid join code")
+	}
+	return g.join(playerID)
+}
+
+// Decline an invitation to join the game.
+func (g *Game) Decline(playerID string) error {
+	if err := g.validateLobby(); err != nil {
+		return err
+	}
+	if !slices.Contains(g.Invited, playerID) {
+		return api.Errorf(api.Forbidden, "not invited")
+	}
+
+	g.Invited = slices.DeleteFunc(g.Invited, func(id string) bool { return id == playerID })
+
+	g.Revision++
+	return nil
+}
+
+// Leave the game before it starts. The admin can't leave, they can delete the game instead.
+func (g *Game) Leave(playerID string) error {
+	if err := g.validateLobby(); err != nil {
+		return err
+	}
+	if g.AdminID == playerID {
+		return api.Errorf(api.Forbidden, "admin can't leave the game")
+	}
+	if !g.hasJoined(playerID) {
+		return api.Errorf(api.NotFound, "not in game")
+	}
+
+	g.Players = slices.DeleteFunc(g.Players, func(p Player) bool { return p.ID == playerID })
+
+	g.Revision++
+	return nil
+}
+
+// Start the game with the players that have joined, filling any remaining seats with bots.
+// The game keeps its ID, name and rules, everything else is set up as for a new game.
+func (g *Game) Start(adminID string, bots []BotType) error {
+	if err := g.validateLobby(); err != nil {
+		return err
+	}
+	if g.AdminID != adminID {
+		return api.Errorf(api.Forbidden, "not admin")
+	}
+
+	playerIDs := make([]string, len(g.Players))
+	for i, p := range g.Players {
+		playerIDs[i] = p.ID
+	}
+	game, err := newGame(playerIDs, bots, g.rules(), g.Name, g.AdminID)
+	if err != nil {
+		return err
+	}
+	game.ID = g.ID
+	game.Revision = g.Revision + 1
+	game.clock = g.clock
+	game.quiet = g.quiet
+
+	*g = game
+	return g.recordCreated()
+}
@@ -0,0 +1,277 @@
+package game
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newTestLobby() Game {
+	rules, _ := NewRules(HouseRules)
+	return NewLobby("test", "1", rules, []string{"2", "3", "1", "2"})
+}
+
+func TestLobby_NewLobby(t *testing.T) {
+	lobby := newTestLobby()
+
+	if lobby.Status != Lobby {
+		t.Errorf("expected status %s, got %s", Lobby, lobby.Status)
+	}
+	if len(lobby.Players) != 1 || lobby.Players[0].ID != "1" {
+		t.Errorf("expected only the admin to have joined, got %v", lobby.Players)
+	}
+	if !compareIDs(lobby.Invited, []string{"2", "3"}) {
+		t.Errorf("expected 2 and 3 to be invited, got %v", lobby.Invited)
+	}
+	if len(lobby.JoinCode) != 6 {
+		t.Errorf("expected a join code, got %s", lobby.JoinCode)
+	}
+}
+
+func TestLobby_JoinCode(t *testing.T) {
+	lobby := newTestLobby()
+	_ = lobby.Join("2", lobby.JoinCode)
+
+	if code := lobby.GetState("1").JoinCode; code != lobby.JoinCode {
+		t.Errorf("expected the admin to be given the join code, got %q", code)
+	}
+	if code := lobby.GetState("2").JoinCode; code != "" {
+		t.Errorf("expected other players not to be given the join code, got %q", code)
+	}
+	if code := lobby.GetState("3").JoinCode; code != "" {
+		t.Errorf("expected spectators not to be given the join code, got %q", code)
+	}
+
+	// Anyone can list the games, so the code is never in the game itself
+	b, err := json.Marshal(lobby)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(string(b), lobby.JoinCode) {
+		t.Errorf("expected the join code to be left out of the game, got %s", b)
+	}
+}
+
+// compareIDs checks two lists of IDs are the same, in the same order.
+func compareIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLobby_Moves(t *testing.T) {
+	full := newTestLobby()
+	for _, id := range []string{"4", "5", "6", "7", "8"} {
+		_ = full.Join(id, full.JoinCode)
+	}
+	full.Invited = []string{"2"}
+
+	tests := []struct {
+		name            string
+		game            Game
+		move            func(g *Game) error
+		expectedPlayers []string
+		expectedInvited []string
+		expectingError  bool
+	}{
+		{
+			name:            "Invite",
+			game:            newTestLobby(),
+			move:            func(g *Game) error { return g.Invite("1", []string{"4", "2", "1"}) },
+			expectedPlayers: []string{"1"},
+			expectedInvited: []string{"2", "3", "4"},
+		},
+		{
+			name:           "Only the admin can invite",
+			game:           newTestLobby(),
+			move:           func(g *Game) error { return g.Invite("2", []string{"4"}) },
+			expectingError: true,
+		},
+		{
+			name:            "Accept",
+			game:            newTestLobby(),
+			move:            func(g *Game) error { return g.Accept("2") },
+			expectedPlayers: []string{"1", "2"},
+			expectedInvited: []string{"3"},
+		},
+		{
+			name:           "Accept without an invite",
+			game:           newTestLobby(),
+			move:           func(g *Game) error { return g.Accept("4") },
+			expectingError: true,
+		},
+		{
+			name:           "Accept when the table is full",
+			game:           full,
+			move:           func(g *Game) error { return g.Accept("2") },
+			expectingError: true,
+		},
+		{
+			name:            "Decline",
+			game:            newTestLobby(),
+			move:            func(g *Game) error { return g.Decline("2") },
+			expectedPlayers: []string{"1"},
+			expectedInvited: []string{"3"},
+		},
+		{
+			name:            "Join with the code",
+			game:            newTestLobby(),
+			move:            func(g *Game) error { return g.Join("4", g.JoinCode) },
+			expectedPlayers: []string{"1", "4"},
+			expectedInvited: []string{"2", "3"},
+		},
+		{
+			name:           "Join with the wrong code",
+			game:           newTestLobby(),
+			move:           func(g *Game) error { return g.Join("4", "WRONG") },
+			expectingError: true,
+		},
+		{
+			name:           "Join twice",
+			game:           newTestLobby(),
+			move:           func(g *Game) error { return g.Join("1", g.JoinCode) },
+			expectingError: true,
+		},
+		{
+			name: "Leave",
+			game: newTestLobby(),
+			move: func(g *Game) error {
+				_ = g.Accept("2")
+				return g.Leave("2")
+			},
+			expectedPlayers: []string{"1"},
+			expectedInvited: []string{"3"},
+		},
+		{
+			name:           "Admin can't leave",
+			game:           newTestLobby(),
+			move:           func(g *Game) error { return g.Leave("1") },
+			expectingError: true,
+		},
+		{
+			name:           "Can't join once the game has started",
+			game:           TwoPlayerGame(),
+			move:           func(g *Game) error { return g.Join("4", "") },
+			expectingError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			revision := test.game.Revision
+			err := test.move(&test.game)
+			if test.expectingError {
+				if err == nil {
+					t.Errorf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			var players []string
+			for _, p := range test.game.Players {
+				players = append(players, p.ID)
+			}
+			if !compareIDs(players, test.expectedPlayers) {
+				t.Errorf("expected players %v, got %v", test.expectedPlayers, players)
+			}
+			if !compareIDs(test.game.Invited, test.expectedInvited) {
+				t.Errorf("expected invited %v, got %v", test.expectedInvited, test.game.Invited)
+			}
+			if test.game.Revision <= revision {
+				t.Errorf("expected the revision to be incremented")
+			}
+		})
+	}
+}
+
+func TestLobby_Start(t *testing.T) {
+	tests := []struct {
+		name           string
+		joined         []string
+		bots           []BotType
+		adminID        string
+		expectedCount  int
+		expectingError bool
+	}{
+		{
+			name:          "Start with players",
+			joined:        []string{"2", "3"},
+			adminID:       "1",
+			expectedCount: 3,
+		},
+		{
+			name:          "Start with bots",
+			bots:          []BotType{RuleBased},
+			adminID:       "1",
+			expectedCount: 2,
+		},
+		{
+			name:           "Not enough players",
+			adminID:        "1",
+			expectingError: true,
+		},
+		{
+			name:           "Only the admin can start",
+			joined:         []string{"2"},
+			adminID:        "2",
+			expectingError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lobby := newTestLobby()
+			id, revision := lobby.ID, lobby.Revision
+			for _, id := range test.joined {
+				_ = lobby.Join(id, lobby.JoinCode)
+			}
+
+			err := lobby.Start(test.adminID, test.bots)
+			if test.expectingError {
+				if err == nil {
+					t.Errorf("expected an error, got nil")
+				}
+				if lobby.Status != Lobby {
+					t.Errorf("expected the game to still be in the lobby, got %s", lobby.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if lobby.Status != Active {
+				t.Errorf("expected the game to be active, got %s", lobby.Status)
+			}
+			if lobby.ID != id || lobby.Revision <= revision {
+				t.Errorf("expected the lobby to become the game, got ID %s revision %d", lobby.ID, lobby.Revision)
+			}
+			if len(lobby.Players) != test.expectedCount {
+				t.Errorf("expected %d players, got %d", test.expectedCount, len(lobby.Players))
+			}
+			for _, p := range lobby.Players {
+				if len(p.Cards) != 5 {
+					t.Errorf("expected player %s to be dealt 5 cards, got %d", p.ID, len(p.Cards))
+				}
+			}
+			if lobby.Rules == nil || lobby.Rules.Preset != HouseRules {
+				t.Errorf("expected the rules to be kept, got %v", lobby.Rules)
+			}
+
+			// The history starts with the game as it was dealt
+			events := lobby.takeEvents()
+			if len(events) != 1 || events[0].Type != EventGameCreated || events[0].Revision != lobby.Revision {
+				t.Errorf("expected the game created event at revision %d, got %v", lobby.Revision, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package game
+
+import (
+	"cards-110-api/pkg/api"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// rematchSuffix matches the number a rematch adds to the end of a game's name.
+var rematchSuffix = regexp.MustCompile(`^(.*) \((\d+)\)$`)
+
+// rematchName numbers the games of a series, e.g. "Friday" is followed by "Friday (2)" then "Friday (3)".
+func rematchName(name string) string {
+	number := 1
+	if match := rematchSuffix.FindStringSubmatch(name); match != nil {
+		name = match[1]
+		number, _ = strconv.Atoi(match[2])
+	}
+	return fmt.Sprintf("%s (%d)", name, number+1)
+}
+
+// Rematch creates a new game for the same table once a game is over.
+// Everyone keeps their seat, the rules stay the same and the first deal moves on to the next player.
+func (g *Game) Rematch(adminID string) (Game, error) {
+	if g.AdminID != adminID {
+		return Game{}, api.Errorf(api.Forbidden, "not admin")
+	}
+	if g.Status != Completed {
+		return Game{}, api.Errorf(api.WrongPhase, "game not completed")
+	}
+	if g.NextGameID != "" {
+		return Game{}, api.Errorf(api.Conflict, "rematch already created")
+	}
+
+	// Seat everyone as they were in the last game
+	players := make([]Player, len(g.Players))
+	for i, p := range g.Players {
+		players[i] = Player{ID: p.ID, Seat: p.Seat, TeamID: p.TeamID, Bot: p.Bot}
+	}
+
+	// The player after the first dealer of the last game deals first
+	firstDealer := g.CurrentRound.DealerID
+	if len(g.Completed) > 0 {
+		firstDealer = g.Completed[0].DealerID
+	}
+	dealer, err := nextPlayer(players, firstDealer)
+	if err != nil {
+		return Game{}, err
+	}
+
+	game, err := dealGame(players, dealer.ID, rematchName(g.Name), g.AdminID, newSeed())
+	if err != nil {
+		return Game{}, err
+	}
+	rules := g.rules()
+	game.Rules = &rules
+
+	// The rematch follows on from this game, this game is only linked to it once the rematch has been saved
+	game.PreviousGameID = g.ID
+
+	return game, nil
+}
+
+// linkRematch links a completed game to its rematch so the series can be followed.
+// Only one rematch can be linked to a game.
+func (g *Game) linkRematch(rematchID string) error {
+	if g.NextGameID != "" {
+		return api.Errorf(api.Conflict, "rematch already created")
+	}
+	g.NextGameID = rematchID
+	g.Revision++
+	return nil
+}
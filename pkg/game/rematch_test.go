@@ -0,0 +1,117 @@
+package game
+
+import (
+	"cards-110-api/pkg/api"
+	"testing"
+)
+
+func TestGame_RematchName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "Friday", expected: "Friday (2)"},
+		{name: "Friday (2)", expected: "Friday (3)"},
+		{name: "Friday (9)", expected: "Friday (10)"},
+		{name: "(2)", expected: "(2) (2)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := rematchName(test.name); result != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestGame_Rematch(t *testing.T) {
+	linked := GameWithCompletedRounds()
+	linked.NextGameID = "3"
+
+	tests := []struct {
+		name           string
+		game           Game
+		adminID        string
+		expectedDealer string
+		expectedCode   api.ErrorCode
+	}{
+		{
+			name:           "Rematch",
+			game:           withRules(GameWithCompletedRounds(), ShortGame),
+			adminID:        "1",
+			expectedDealer: "2",
+		},
+		{
+			name:         "Game not over",
+			game:         TwoPlayerGame(),
+			adminID:      "1",
+			expectedCode: api.WrongPhase,
+		},
+		{
+			name:         "Not admin",
+			game:         GameWithCompletedRounds(),
+			adminID:      "2",
+			expectedCode: api.Forbidden,
+		},
+		{
+			name:         "Rematch already created",
+			game:         linked,
+			adminID:      "1",
+			expectedCode: api.Conflict,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			revision := test.game.Revision
+			rematch, err := test.game.Rematch(test.adminID)
+			if test.expectedCode != "" {
+				if code := api.CodeOf(err); code != test.expectedCode {
+					t.Errorf("expected code %s, got %s (%v)", test.expectedCode, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if rematch.Status != Active || rematch.Name != test.game.Name+" (2)" || rematch.AdminID != test.game.AdminID {
+				t.Errorf("expected an active game called %s (2), got %s %s", test.game.Name, rematch.Status, rematch.Name)
+			}
+			if rematch.Rules == nil || rematch.Rules.Preset != test.game.rules().Preset {
+				t.Errorf("expected the rules to be kept, got %v", rematch.Rules)
+			}
+			if rematch.CurrentRound.DealerID != test.expectedDealer {
+				t.Errorf("expected %s to deal, got %s", test.expectedDealer, rematch.CurrentRound.DealerID)
+			}
+			for i, p := range rematch.Players {
+				old := test.game.Players[i]
+				if p.ID != old.ID || p.Seat != old.Seat || p.TeamID != old.TeamID {
+					t.Errorf("expected %s to keep their seat, got %v", old.ID, p)
+				}
+				if p.Score != 0 || p.Winner || len(p.Cards) != 5 {
+					t.Errorf("expected %s to start again with a new hand, got %v", p.ID, p)
+				}
+			}
+			if rematch.PreviousGameID != test.game.ID {
+				t.Errorf("expected the rematch to follow on from %s, got %s", test.game.ID, rematch.PreviousGameID)
+			}
+			if test.game.NextGameID != "" || test.game.Revision != revision {
+				t.Errorf("expected the completed game to be unchanged until the rematch is saved")
+			}
+
+			// Link the completed game to the rematch, only one rematch can be linked
+			err = test.game.linkRematch(rematch.ID)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if test.game.NextGameID != rematch.ID || test.game.Revision != revision+1 {
+				t.Errorf("expected the completed game to be linked and revised, got %s", test.game.NextGameID)
+			}
+			if code := api.CodeOf(test.game.linkRematch("4")); code != api.Conflict {
+				t.Errorf("expected a second rematch to conflict, got %s", code)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"cards-110-api/pkg/api"
+	"time"
+)
+
+// minTurnTimeLimit is the shortest time a player can be given to take their go.
+const minTurnTimeLimit = 10
+
+type RulesPreset string
+
+const (
+	HouseRules RulesPreset = "HOUSE"
+	Classic                = "CLASSIC"
+	ShortGame              = "SHORT"
+)
+
+// Rules are the variations of the rules a game is played with.
+type Rules struct {
+	Preset RulesPreset `bson:"preset" json:"preset"`
+	// Target is the score a team must reach to win the game.
+	Target int `bson:"target" json:"target"`
+	// Bunker is the score below which a player can only pass.
+	Bunker int `bson:"bunker" json:"bunker"`
+	// JinkPoints is the score for making a jink.
+	JinkPoints int `bson:"jinkPoints" json:"jinkPoints"`
+	// TenCallPlayers are the numbers of players that a call of 10 is allowed with.
+	TenCallPlayers []int `bson:"tenCallPlayers" json:"tenCallPlayers"`
+	// MinKeep is the minimum number of cards that must be kept when buying, indexed by the number of players.
+	MinKeep []int `bson:"minKeep" json:"minKeep"`
+	// TurnTimeLimit is the number of seconds a player has to take their go before it is taken for them, 0 for no limit.
+	TurnTimeLimit int `bson:"turnTimeLimit,omitempty" json:"turnTimeLimit,omitempty"`
+}
+
+var presets = map[RulesPreset]Rules{
+	HouseRules: {
+		Preset:         HouseRules,
+		Target:         110,
+		Bunker:         -30,
+		JinkPoints:     60,
+		TenCallPlayers: []int{6},
+		MinKeep:        []int{0, 0, 0, 0, 0, 1, 2},
+	},
+	// Classic 110 has no call of 10
+	Classic: {
+		Preset:         Classic,
+		Target:         110,
+		Bunker:         -30,
+		JinkPoints:     60,
+		TenCallPlayers: []int{},
+		MinKeep:        []int{0, 0, 0, 0, 0, 1, 2},
+	},
+	// A short game is played to 60, so a jink is only worth the 30 points it takes
+	ShortGame: {
+		Preset:         ShortGame,
+		Target:         60,
+		Bunker:         -30,
+		JinkPoints:     30,
+		TenCallPlayers: []int{6},
+		MinKeep:        []int{0, 0, 0, 0, 0, 1, 2},
+	},
+}
+
+// NewRules returns the rules for a preset. House rules are used if no preset is given.
+func NewRules(preset RulesPreset) (Rules, error) {
+	if preset == "" {
+		preset = HouseRules
+	}
+	rules, ok := presets[preset]
+	if !ok {
+		return Rules{}, api.Errorf(api.InvalidRequest, "unknown rules %s", preset)
+	}
+	// Copy the slices so that changing the rules of a game can't change the preset
+	rules.TenCallPlayers = append([]int{}, rules.TenCallPlayers...)
+	rules.MinKeep = append([]int{}, rules.MinKeep...)
+	return rules, nil
+}
+
+// WithTurnTimeLimit returns the rules with a limit on how many seconds a player has to take their go.
+func (r Rules) WithTurnTimeLimit(seconds int) (Rules, error) {
+	if seconds != 0 && seconds < minTurnTimeLimit {
+		return Rules{}, api.Errorf(api.InvalidRequest, "turn time limit must be at least %d seconds", minTurnTimeLimit)
+	}
+	r.TurnTimeLimit = seconds
+	return r, nil
+}
+
+// turnTimeLimit returns how long a player has to take their go, 0 if there is no limit.
+func (r Rules) turnTimeLimit() time.Duration {
+	return time.Duration(r.TurnTimeLimit) * time.Second
+}
+
+// rules returns the rules the game is played with.
+// Games created before the rules could be chosen are played with house rules.
+func (g *Game) rules() Rules {
+	if g.Rules == nil {
+		return presets[HouseRules]
+	}
+	return *g.Rules
+}
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"testing"
+)
+
+// withRules returns the game played with the given rules.
+func withRules(game Game, preset RulesPreset) Game {
+	rules, _ := NewRules(preset)
+	game.Rules = &rules
+	return game
+}
+
+func TestRules_NewRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		preset         RulesPreset
+		expectedTarget int
+		expectingError bool
+	}{
+		{name: "Default to house rules", preset: "", expectedTarget: 110},
+		{name: "Classic", preset: Classic, expectedTarget: 110},
+		{name: "Short game", preset: ShortGame, expectedTarget: 60},
+		{name: "Unknown", preset: "CALVINBALL", expectingError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := NewRules(test.preset)
+			if test.expectingError {
+				if err == nil {
+					t.Errorf("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if rules.Target != test.expectedTarget {
+				t.Errorf("expected target %d, got %d", test.expectedTarget, rules.Target)
+			}
+		})
+	}
+}
+
+func TestRules_NewRulesCopiesPreset(t *testing.T) {
+	rules, err := NewRules(HouseRules)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	rules.TenCallPlayers[0] = 2
+	rules.MinKeep[6] = 0
+
+	preset, _ := NewRules(HouseRules)
+	if preset.TenCallPlayers[0] != 6 || preset.MinKeep[6] != 2 {
+		t.Errorf("expected the preset to be unchanged, got %+v", preset)
+	}
+}
+
+func TestRules_Game(t *testing.T) {
+	shortGameOver := withRules(TwoPlayerGame(), ShortGame)
+	shortGameOver.Players[0].Score = 60
+
+	inBunker := TwoPlayerGame()
+	inBunker.Players[1].Score = -35
+
+	tests := []struct {
+		name     string
+		check    func(g *Game) bool
+		game     Game
+		expected bool
+	}{
+		{
+			name:     "Existing games are played to 110",
+			game:     func() Game { g := TwoPlayerGame(); g.Players[0].Score = 60; return g }(),
+			check:    func(g *Game) bool { return g.isGameOver() },
+			expected: false,
+		},
+		{
+			name:     "Short game is over at 60",
+			game:     shortGameOver,
+			check:    func(g *Game) bool { return g.isGameOver() },
+			expected: true,
+		},
+		{
+			name:     "Can call 10 in doubles in house rules",
+			game:     SixPlayerGame(),
+			check:    func(g *Game) bool { return g.Call("5", Ten) == nil },
+			expected: true,
+		},
+		{
+			name:     "Can't call 10 in classic rules",
+			game:     withRules(SixPlayerGame(), Classic),
+			check:    func(g *Game) bool { return g.Call("5", Ten) == nil },
+			expected: false,
+		},
+		{
+			name:     "Can't call in the bunker",
+			game:     inBunker,
+			check:    func(g *Game) bool { return g.Call("2", Fifteen) == nil },
+			expected: false,
+		},
+		{
+			name: "Jink is worth 60 in house rules",
+			game: PlayingGame_Jink(),
+			check: func(g *Game) bool {
+				err := g.applyScores()
+				return err == nil && g.Players[2].Score == 60
+			},
+			expected: true,
+		},
+		{
+			name: "Jink is worth 30 in a short game",
+			game: withRules(PlayingGame_Jink(), ShortGame),
+			check: func(g *Game) bool {
+				err := g.applyScores()
+				return err == nil && g.Players[2].Score == 30
+			},
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.check(&test.game); result != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, result)
+			}
+		})
+	}
+}
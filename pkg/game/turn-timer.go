@@ -0,0 +1,63 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// errTurnNotExpired is returned when a player's go is taken for them before their time has run out.
+var errTurnNotExpired = errors.New("turn has not expired")
+
+// turnDeadline returns when the current player's time to take their go runs out.
+// It is derived from when their go started so it survives the server restarting. There is no deadline if the game
+// has no turn time limit or isn't active.
+func (g *Game) turnDeadline() (time.Time, bool) {
+	limit := g.rules().turnTimeLimit()
+	if limit <= 0 || g.Status != Active {
+		return time.Time{}, false
+	}
+	return g.CurrentRound.CurrentHand.Timestamp.Add(limit), true
+}
+
+// turnExpired checks if the current player has run out of time to take their go.
+func (g *Game) turnExpired(now time.Time) bool {
+	deadline, ok := g.turnDeadline()
+	return ok && !now.Before(deadline)
+}
+
+// timeout takes the go of a player whose time has run out.
+// They pass when calling, keep their best cards when selecting a suit or buying and play their lowest card.
+func (g *Game) timeout() error {
+	if !g.turnExpired(g.now()) {
+		return errTurnNotExpired
+	}
+
+	playerID := g.CurrentRound.CurrentHand.CurrentPlayerID
+	moves, err := g.LegalMoves(playerID)
+	if err != nil {
+		return err
+	}
+	g.logf("Player %s ran out of time in game %s", playerID, g.ID)
+
+	switch g.CurrentRound.Status {
+	case Calling:
+		return g.Call(playerID, Pass)
+	case Called:
+		suit, _ := bestSuit(moves.Keep.From, moves.Suits)
+		return g.SelectSuit(playerID, suit, keepBest(moves.Keep.From, suit, moves.Keep))
+	case Buying:
+		return g.Buy(playerID, keepBest(moves.Keep.From, g.CurrentRound.Suit, moves.Keep))
+	case Playing:
+		cards := append([]CardName{}, moves.Cards...)
+		if len(cards) == 0 {
+			return fmt.Errorf("no cards to play")
+		}
+		sort.SliceStable(cards, func(i, j int) bool {
+			return rank(cards[i], g.CurrentRound.Suit) < rank(cards[j], g.CurrentRound.Suit)
+		})
+		return g.Play(playerID, cards[0])
+	}
+	return fmt.Errorf("invalid round status")
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func withTurnTimeLimit(game Game, seconds int) Game {
+	rules, _ := NewRules(HouseRules)
+	rules.TurnTimeLimit = seconds
+	game.Rules = &rules
+	return game
+}
+
+func TestGame_Timeout(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	notExpired := withTurnTimeLimit(TwoPlayerGame(), 30)
+	notExpired.CurrentRound.CurrentHand.Timestamp = now.Add(-29 * time.Second)
+
+	tests := []struct {
+		name           string
+		game           Game
+		expectedStatus RoundStatus
+		expectedPlayer string
+		check          func(t *testing.T, g Game)
+		expectedError  error
+	}{
+		{
+			name:           "Pass when calling",
+			game:           withTurnTimeLimit(TwoPlayerGame(), 30),
+			expectedStatus: Calling,
+			expectedPlayer: "1",
+			check: func(t *testing.T, g Game) {
+				if me, _ := g.Me("2"); me.Call != Pass {
+					t.Errorf("expected player 2 to pass, got %d", me.Call)
+				}
+			},
+		},
+		{
+			name:           "Keep the best cards when selecting a suit",
+			game:           withTurnTimeLimit(CalledGameThreePlayers(), 30),
+			expectedStatus: Buying,
+			expectedPlayer: "2",
+			check: func(t *testing.T, g Game) {
+				if g.CurrentRound.Suit == "" {
+					t.Errorf("expected a suit to be selected")
+				}
+			},
+		},
+		{
+			name:           "Keep the best cards when buying",
+			game:           withTurnTimeLimit(BuyingGame("1"), 30),
+			expectedStatus: Buying,
+			expectedPlayer: "3",
+			check: func(t *testing.T, g Game) {
+				if me, _ := g.Me("2"); len(me.Cards) != 5 {
+					t.Errorf("expected player 2 to have 5 cards, got %d", len(me.Cards))
+				}
+			},
+		},
+		{
+			name:           "Play the lowest card",
+			game:           withTurnTimeLimit(PlayingGame_RoundStart_FirstCardPlayed(), 30),
+			expectedStatus: Playing,
+			expectedPlayer: "1",
+			check: func(t *testing.T, g Game) {
+				played := g.CurrentRound.CompletedHands[0].PlayedCards
+				if played[1].Card != THREE_CLUBS {
+					t.Errorf("expected %s to be played, got %s", THREE_CLUBS, played[1].Card)
+				}
+			},
+		},
+		{
+			name:          "Time hasn't run out",
+			game:          notExpired,
+			expectedError: errTurnNotExpired,
+		},
+		{
+			name:          "No time limit",
+			game:          TwoPlayerGame(),
+			expectedError: errTurnNotExpired,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.game.clock = func() time.Time { return now }
+			err := test.game.timeout()
+			if test.expectedError != nil {
+				if !errors.Is(err, test.expectedError) {
+					t.Errorf("expected error %v, got %v", test.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if test.game.CurrentRound.Status != test.expectedStatus {
+				t.Errorf("expected round status %s, got %s", test.expectedStatus, test.game.CurrentRound.Status)
+			}
+			if test.game.CurrentRound.CurrentHand.CurrentPlayerID != test.expectedPlayer {
+				t.Errorf("expected player %s to be next, got %s", test.expectedPlayer, test.game.CurrentRound.CurrentHand.CurrentPlayerID)
+			}
+			// The next player's time starts now
+			if !test.game.CurrentRound.CurrentHand.Timestamp.Equal(now) {
+				t.Errorf("expected the next go to start at %v, got %v", now, test.game.CurrentRound.CurrentHand.Timestamp)
+			}
+			test.check(t, test.game)
+		})
+	}
+}
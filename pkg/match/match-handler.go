@@ -0,0 +1,100 @@
+package match
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/auth"
+	"cards-110-api/pkg/game"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Handler struct {
+	S ServiceI
+}
+
+type CreateMatchRequest struct {
+	PlayerIDs     []string         `json:"players"`
+	Bots          []game.BotType   `json:"bots"`
+	Rules         game.RulesPreset `json:"rules"`
+	TurnTimeLimit int              `json:"turnTimeLimit"`
+	BestOf        int              `json:"bestOf"`
+	Name          string           `json:"name"`
+}
+
+// Create @Summary Create a new match
+// @Description Creates a best of N match and its first game. The game is set up in the same way as a single game.
+// @Description When a game completes its winning team is awarded the game and the next game is started, until a team has won a majority of the games.
+// @Tags Match
+// @ID create-match
+// @Accept json
+// @Produce json
+// @Param match body CreateMatchRequest true "Match"
+// @Security Bearer
+// @Success 200 {object} Match
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /match [put]
+func (h *Handler) Create(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the request body
+	var req CreateMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
+		return
+	}
+
+	// Create the match
+	match, err := h.S.Create(ctx, req.PlayerIDs, req.Bots, req.Rules, req.TurnTimeLimit, req.BestOf, req.Name, id)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, match)
+}
+
+// Get @Summary Get a match
+// @Description Returns a match with the given ID, including the games played so far and how many each team has won
+// @Tags Match
+// @ID get-match
+// @Produce json
+// @Param matchId path string true "Match ID"
+// @Security Bearer
+// @Success 200 {object} Match
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /match/{matchId} [get]
+func (h *Handler) Get(c *gin.Context) {
+	// Check the user is correctly authenticated
+	_, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the match ID from the request
+	matchId := c.Param("matchId")
+
+	// Get the match from the database
+	match, has, err := h.S.Get(ctx, matchId)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	if !has {
+		api.WriteError(c, ErrNotFound)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, match)
+}
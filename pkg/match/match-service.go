@@ -0,0 +1,154 @@
+package match
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrNotFound = api.Errorf(api.NotFound, "match not found")
+
+// ErrConflict is returned when a match couldn't be saved because it kept being updated by other requests.
+var ErrConflict = api.Errorf(api.Conflict, "match was updated by another request, please try again")
+
+// maxUpdateAttempts is the number of times a result is recorded before giving up because of concurrent updates.
+const maxUpdateAttempts = 3
+
+type ServiceI interface {
+	Create(ctx context.Context, playerIDs []string, bots []game.BotType, rules game.RulesPreset, turnTimeLimit int, bestOf int, name string, adminID string) (Match, error)
+	Get(ctx context.Context, matchId string) (Match, bool, error)
+	GameCompleted(ctx context.Context, g game.Game) error
+}
+
+type Service struct {
+	Col   db.CollectionI[Match]
+	Games game.ServiceI
+}
+
+// Create starts a match with its first game.
+func (s *Service) Create(ctx context.Context, playerIDs []string, bots []game.BotType, rules game.RulesPreset, turnTimeLimit int, bestOf int, name string, adminID string) (Match, error) {
+	// Check the match can be won before creating its first game
+	err := validateBestOf(bestOf)
+	if err != nil {
+		return Match{}, err
+	}
+
+	first, err := s.Games.Create(ctx, playerIDs, bots, rules, turnTimeLimit, name, adminID)
+	if err != nil {
+		return Match{}, err
+	}
+
+	m, err := NewMatch(name, adminID, bestOf, first)
+	if err != nil {
+		return Match{}, err
+	}
+	err = s.Col.Upsert(ctx, m, m.ID)
+	if err != nil {
+		return Match{}, err
+	}
+	return m, nil
+}
+
+// Get returns a match by ID.
+// If the current game has been completed but its result hasn't been recorded, e.g. because the next game couldn't be
+// started at the time, the result is recorded first so the match can carry on.
+func (s *Service) Get(ctx context.Context, matchId string) (Match, bool, error) {
+	m, has, err := s.Col.FindOne(ctx, bson.M{"_id": matchId})
+	if err != nil || !has || m.Status != Active {
+		return m, has, err
+	}
+
+	current, has, err := s.Games.Get(ctx, m.CurrentGameID())
+	if err != nil || !has || current.Status != game.Completed {
+		return m, true, err
+	}
+	err = s.GameCompleted(ctx, current)
+	if err != nil {
+		// The match is still returned, the result will be recorded again the next time it is read
+		log.Printf("Failed to record the result of game %s in match %s: %v", current.ID, m.ID, err)
+		return m, true, nil
+	}
+	return s.Col.FindOne(ctx, bson.M{"_id": matchId})
+}
+
+// CheckDelete stops a game being deleted while it is being played as part of a match, which would leave the match
+// without a game to finish.
+func (s *Service) CheckDelete(ctx context.Context, g game.Game) error {
+	m, has, err := s.Col.FindOne(ctx, bson.M{"gameIds": g.ID, "status": Active})
+	if err != nil {
+		return err
+	}
+	if has {
+		return api.Errorf(api.Conflict, "game is part of match %s", m.ID)
+	}
+	return nil
+}
+
+// GameCompleted records the result of a game that was played as part of a match and starts the next game if the
+// match isn't over. Games that aren't part of a match, or whose result has already been recorded, are ignored.
+// The match is only saved if no one else has saved it since it was read, otherwise the result is recorded again
+// against the latest revision. The result isn't saved until the next game has been started, so if it can't be started
+// the result is recorded again the next time the match is read.
+func (s *Service) GameCompleted(ctx context.Context, g game.Game) error {
+	for attempt := 1; attempt <= maxUpdateAttempts; attempt++ {
+		m, has, err := s.Col.FindOne(ctx, bson.M{"gameIds": g.ID, "status": Active})
+		if err != nil {
+			return err
+		}
+		if !has || m.Recorded(g.ID) {
+			return nil
+		}
+
+		revision := m.Revision
+		err = m.RecordResult(g)
+		if err != nil {
+			return err
+		}
+
+		// Start the next game
+		if m.Status == Active {
+			nextID, err := s.nextGame(ctx, g, m.AdminID)
+			if err != nil {
+				return err
+			}
+			m.AddGame(nextID)
+		}
+
+		m.Revision++
+		saved, err := s.Col.ConditionalUpdateOne(ctx, m, bson.M{"_id": m.ID, "revision": revision})
+		if err != nil {
+			return err
+		}
+		if !saved {
+			continue
+		}
+		return nil
+	}
+
+	return ErrConflict
+}
+
+// nextGame starts the next game of a match, or returns the one that has already been started if the result is being
+// recorded again.
+func (s *Service) nextGame(ctx context.Context, g game.Game, adminID string) (string, error) {
+	next, err := s.Games.Rematch(ctx, g.ID, adminID)
+	if err == nil {
+		return next.ID, nil
+	}
+	if api.CodeOf(err) != api.Conflict {
+		return "", err
+	}
+
+	latest, has, errG := s.Games.Get(ctx, g.ID)
+	if errG != nil {
+		return "", errG
+	}
+	if !has || latest.NextGameID == "" {
+		return "", err
+	}
+	return latest.NextGameID, nil
+}
@@ -0,0 +1,144 @@
+package match
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/game"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+type Status string
+
+const (
+	Active    Status = "ACTIVE"
+	Completed        = "COMPLETED"
+)
+
+// Team is a team in a match along with the number of games it has won.
+type Team struct {
+	ID        string   `bson:"teamId" json:"teamId"`
+	PlayerIDs []string `bson:"playerIds" json:"playerIds"`
+	Wins      int      `bson:"wins" json:"wins"`
+}
+
+// Match is a series of games played by the same table. The first team to win a majority of the games wins the match.
+// With more than two teams no one may have a majority after BestOf games, in which case play continues until someone
+// does.
+type Match struct {
+	ID         string    `bson:"_id,omitempty" json:"id"`
+	Revision   int       `bson:"revision" json:"revision"`
+	Name       string    `bson:"name" json:"name"`
+	AdminID    string    `bson:"adminId" json:"adminId"`
+	Timestamp  time.Time `bson:"timestamp" json:"timestamp"`
+	Status     Status    `bson:"status" json:"status"`
+	BestOf     int       `bson:"bestOf" json:"bestOf"`
+	WinsNeeded int       `bson:"winsNeeded" json:"winsNeeded"`
+	GameIDs    []string  `bson:"gameIds" json:"gameIds"`
+	Teams      []Team    `bson:"teams" json:"teams"`
+	WinnerID   string    `bson:"winnerId,omitempty" json:"winnerId,omitempty"`
+}
+
+// validateBestOf checks a match can be won by a majority of its games.
+func validateBestOf(bestOf int) error {
+	if bestOf < 1 || bestOf%2 == 0 {
+		return api.Errorf(api.InvalidRequest, "best of must be an odd number of games")
+	}
+	return nil
+}
+
+// NewMatch creates a match starting with the given game. The teams are taken from the seating of the first game.
+func NewMatch(name string, adminID string, bestOf int, first game.Game) (Match, error) {
+	err := validateBestOf(bestOf)
+	if err != nil {
+		return Match{}, err
+	}
+
+	// Group the players into their teams, in the order they are seated
+	teams := make([]Team, 0)
+	index := make(map[string]int)
+	for _, p := range first.Players {
+		i, ok := index[p.TeamID]
+		if !ok {
+			i = len(teams)
+			index[p.TeamID] = i
+			teams = append(teams, Team{ID: p.TeamID, PlayerIDs: make([]string, 0)})
+		}
+		teams[i].PlayerIDs = append(teams[i].PlayerIDs, p.ID)
+	}
+
+	return Match{
+		ID:         strconv.Itoa(rand.Intn(10000000)),
+		Name:       name,
+		AdminID:    adminID,
+		Timestamp:  time.Now(),
+		Status:     Active,
+		BestOf:     bestOf,
+		WinsNeeded: bestOf/2 + 1,
+		GameIDs:    []string{first.ID},
+		Teams:      teams,
+	}, nil
+}
+
+// CurrentGameID returns the ID of the game being played, or the last game if the match is over.
+func (m *Match) CurrentGameID() string {
+	return m.GameIDs[len(m.GameIDs)-1]
+}
+
+// RecordResult counts the win of a completed game and ends the match once a team has won enough games.
+func (m *Match) RecordResult(g game.Game) error {
+	if m.Status != Active {
+		return api.Errorf(api.WrongPhase, "match is over")
+	}
+	if g.Status != game.Completed {
+		return api.Errorf(api.WrongPhase, "game not completed")
+	}
+	if g.ID != m.CurrentGameID() {
+		return api.Errorf(api.Conflict, "game %s isn't the current game of the match", g.ID)
+	}
+	if m.Recorded(g.ID) {
+		return api.Errorf(api.Conflict, "result of game %s already recorded", g.ID)
+	}
+
+	// Find the team that won the game
+	var winner string
+	for _, p := range g.Players {
+		if p.Winner {
+			winner = p.TeamID
+			break
+		}
+	}
+
+	for i, team := range m.Teams {
+		if team.ID != winner {
+			continue
+		}
+		m.Teams[i].Wins++
+		if m.Teams[i].Wins >= m.WinsNeeded {
+			m.Status = Completed
+			m.WinnerID = team.ID
+		}
+		return nil
+	}
+	return api.Errorf(api.Conflict, "winning team %s isn't in the match", winner)
+}
+
+// Recorded checks whether the result of a game in the match has been counted.
+// Every game is won by one team, so the games that have been counted are the first as many games as there are wins.
+func (m *Match) Recorded(gameID string) bool {
+	wins := 0
+	for _, team := range m.Teams {
+		wins += team.Wins
+	}
+	for i, id := range m.GameIDs {
+		if id == gameID {
+			return i < wins
+		}
+	}
+	return false
+}
+
+// AddGame adds the next game of the match.
+func (m *Match) AddGame(gameID string) {
+	m.GameIDs = append(m.GameIDs, gameID)
+}
@@ -0,0 +1,368 @@
+package match
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// completedGame returns a completed game with two teams of two, won by the given team.
+func completedGame(id string, winner string) game.Game {
+	players := []game.Player{
+		{ID: "1", Seat: 1, TeamID: "1"},
+		{ID: "2", Seat: 2, TeamID: "2"},
+		{ID: "3", Seat: 3, TeamID: "1"},
+		{ID: "4", Seat: 4, TeamID: "2"},
+	}
+	for i := range players {
+		players[i].Winner = players[i].TeamID == winner
+	}
+	return game.Game{ID: id, Status: game.Completed, Players: players}
+}
+
+func TestMatch_NewMatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		bestOf        int
+		expectedWins  int
+		expectedError bool
+	}{
+		{name: "Single game", bestOf: 1, expectedWins: 1},
+		{name: "Best of three", bestOf: 3, expectedWins: 2},
+		{name: "Best of five", bestOf: 5, expectedWins: 3},
+		{name: "Even number of games", bestOf: 4, expectedError: true},
+		{name: "No games", bestOf: 0, expectedError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := NewMatch("Friday", "1", test.bestOf, completedGame("1", "1"))
+			if test.expectedError {
+				if api.CodeOf(err) != api.InvalidRequest {
+					t.Errorf("expected an invalid request error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if m.WinsNeeded != test.expectedWins {
+				t.Errorf("expected %d wins needed, got %d", test.expectedWins, m.WinsNeeded)
+			}
+			if m.Status != Active || m.CurrentGameID() != "1" {
+				t.Errorf("expected an active match playing game 1, got %s playing %s", m.Status, m.CurrentGameID())
+			}
+			if len(m.Teams) != 2 {
+				t.Fatalf("expected 2 teams, got %d", len(m.Teams))
+			}
+			if m.Teams[0].ID != "1" || len(m.Teams[0].PlayerIDs) != 2 || m.Teams[0].PlayerIDs[1] != "3" {
+				t.Errorf("unexpected first team %v", m.Teams[0])
+			}
+		})
+	}
+}
+
+func TestMatch_RecordResult(t *testing.T) {
+	tests := []struct {
+		name           string
+		winners        []string
+		expectedStatus Status
+		expectedWinner string
+		expectedWins   []int
+	}{
+		{
+			name:           "First game",
+			winners:        []string{"1"},
+			expectedStatus: Active,
+			expectedWins:   []int{1, 0},
+		},
+		{
+			name:           "One game each",
+			winners:        []string{"1", "2"},
+			expectedStatus: Active,
+			expectedWins:   []int{1, 1},
+		},
+		{
+			name:           "Won in two",
+			winners:        []string{"2", "2"},
+			expectedStatus: Completed,
+			expectedWinner: "2",
+			expectedWins:   []int{0, 2},
+		},
+		{
+			name:           "Won in three",
+			winners:        []string{"1", "2", "1"},
+			expectedStatus: Completed,
+			expectedWinner: "1",
+			expectedWins:   []int{2, 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := NewMatch("Friday", "1", 3, completedGame("g1", test.winners[0]))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, winner := range test.winners {
+				if i > 0 {
+					m.AddGame(fmt.Sprintf("g%d", i+1))
+				}
+				err = m.RecordResult(completedGame(m.CurrentGameID(), winner))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if m.Status != test.expectedStatus {
+				t.Errorf("expected status %s, got %s", test.expectedStatus, m.Status)
+			}
+			if m.WinnerID != test.expectedWinner {
+				t.Errorf("expected winner %s, got %s", test.expectedWinner, m.WinnerID)
+			}
+			for i, team := range m.Teams {
+				if team.Wins != test.expectedWins[i] {
+					t.Errorf("expected team %s to have %d wins, got %d", team.ID, test.expectedWins[i], team.Wins)
+				}
+			}
+		})
+	}
+}
+
+func TestMatch_RecordResultErrors(t *testing.T) {
+	over, _ := NewMatch("Friday", "1", 1, completedGame("1", "1"))
+	_ = over.RecordResult(completedGame("1", "1"))
+
+	unfinished := completedGame("1", "1")
+	unfinished.Status = game.Active
+
+	recorded, _ := NewMatch("Friday", "1", 3, completedGame("1", "1"))
+	_ = recorded.RecordResult(completedGame("1", "1"))
+
+	tests := []struct {
+		name         string
+		match        Match
+		game         game.Game
+		expectedCode api.ErrorCode
+	}{
+		{name: "Match over", match: over, game: completedGame("1", "1"), expectedCode: api.WrongPhase},
+		{name: "Game not completed", game: unfinished, expectedCode: api.WrongPhase},
+		{name: "Not the current game", game: completedGame("2", "1"), expectedCode: api.Conflict},
+		{name: "Already recorded", match: recorded, game: completedGame("1", "1"), expectedCode: api.Conflict},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := test.match
+			if m.ID == "" {
+				m, _ = NewMatch("Friday", "1", 3, completedGame("1", "1"))
+			}
+			err := m.RecordResult(test.game)
+			if api.CodeOf(err) != test.expectedCode {
+				t.Errorf("expected %s, got %v", test.expectedCode, err)
+			}
+		})
+	}
+}
+
+// games starts the next game of a match.
+type games struct {
+	game.ServiceI
+	rematches  int
+	rematchErr error
+	current    game.Game
+}
+
+func (g *games) Rematch(ctx context.Context, gameId string, adminId string) (game.Game, error) {
+	if g.rematchErr != nil {
+		return game.Game{}, g.rematchErr
+	}
+	g.rematches++
+	return game.Game{ID: "next"}, nil
+}
+
+func (g *games) Get(ctx context.Context, gameId string) (game.Game, bool, error) {
+	return g.current, g.current.ID == gameId, nil
+}
+
+var errFailed = errors.New("failed")
+
+func TestMatchService_GameCompleted(t *testing.T) {
+	ctx := context.Background()
+	// Each read of the match gets its own copy, as it would from the database
+	m := func() Match {
+		m, _ := NewMatch("Friday", "1", 3, completedGame("1", "1"))
+		m.ID = "1"
+		return m
+	}
+	recorded := m()
+	recorded.Teams[0].Wins = 1
+
+	tests := []struct {
+		name          string
+		mockGetResult *[]Match
+		mockGetExists *[]bool
+		mockConflict  *[]bool
+		rematchErr    error
+		expectedSaves int
+		expectedError error
+	}{
+		{
+			name:          "result recorded and next game started",
+			mockGetResult: &[]Match{m()},
+			mockGetExists: &[]bool{true},
+			mockConflict:  &[]bool{false},
+			expectedSaves: 1,
+		},
+		{
+			name:          "conflict is retried against the latest revision",
+			mockGetResult: &[]Match{m(), m()},
+			mockGetExists: &[]bool{true, true},
+			mockConflict:  &[]bool{true, false},
+			expectedSaves: 2,
+		},
+		{
+			name:          "result already recorded by someone else",
+			mockGetResult: &[]Match{m(), recorded},
+			mockGetExists: &[]bool{true, true},
+			mockConflict:  &[]bool{true},
+			expectedSaves: 1,
+		},
+		{
+			name:          "persistent conflict returns ErrConflict",
+			mockGetResult: &[]Match{m(), m(), m()},
+			mockGetExists: &[]bool{true, true, true},
+			mockConflict:  &[]bool{true, true, true},
+			expectedSaves: 3,
+			expectedError: ErrConflict,
+		},
+		{
+			name:          "result isn't saved if the next game can't be started",
+			mockGetResult: &[]Match{m()},
+			mockGetExists: &[]bool{true},
+			mockConflict:  &[]bool{false},
+			rematchErr:    errFailed,
+			expectedError: errFailed,
+		},
+		{
+			name:          "game not part of a match",
+			mockGetResult: &[]Match{{}},
+			mockGetExists: &[]bool{false},
+			mockConflict:  &[]bool{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := len(*test.mockConflict)
+			s := Service{
+				Col: &db.MockCollection[Match]{
+					MockFindOneResult:                test.mockGetResult,
+					MockFindOneExists:                test.mockGetExists,
+					MockFindOneErr:                   &[]error{},
+					MockConditionalUpdateOneConflict: test.mockConflict,
+				},
+				Games: &games{rematchErr: test.rematchErr},
+			}
+
+			err := s.GameCompleted(ctx, completedGame("1", "1"))
+			if !errors.Is(err, test.expectedError) {
+				t.Errorf("expected error %v, got %v", test.expectedError, err)
+			}
+			if saves := attempts - len(*test.mockConflict); saves != test.expectedSaves {
+				t.Errorf("expected %d saves, got %d", test.expectedSaves, saves)
+			}
+		})
+	}
+}
+
+func TestMatchService_Get(t *testing.T) {
+	ctx := context.Background()
+	m := func() Match {
+		m, _ := NewMatch("Friday", "1", 3, completedGame("1", "1"))
+		m.ID = "1"
+		return m
+	}
+	resumed := m()
+	resumed.Teams[0].Wins = 1
+	resumed.AddGame("next")
+	playing := completedGame("1", "1")
+	playing.Status = game.Active
+
+	tests := []struct {
+		name              string
+		mockGetResult     *[]Match
+		mockGetExists     *[]bool
+		mockConflict      *[]bool
+		current           game.Game
+		expectedRematches int
+		expectedGames     int
+	}{
+		{
+			name:          "current game still being played",
+			mockGetResult: &[]Match{m()},
+			mockGetExists: &[]bool{true},
+			mockConflict:  &[]bool{},
+			current:       playing,
+			expectedGames: 1,
+		},
+		{
+			name:              "result of a completed game that wasn't recorded is recorded",
+			mockGetResult:     &[]Match{m(), m(), resumed},
+			mockGetExists:     &[]bool{true, true, true},
+			mockConflict:      &[]bool{false},
+			current:           completedGame("1", "1"),
+			expectedRematches: 1,
+			expectedGames:     2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := &games{current: test.current}
+			s := Service{
+				Col: &db.MockCollection[Match]{
+					MockFindOneResult:                test.mockGetResult,
+					MockFindOneExists:                test.mockGetExists,
+					MockFindOneErr:                   &[]error{},
+					MockConditionalUpdateOneConflict: test.mockConflict,
+				},
+				Games: g,
+			}
+
+			result, has, err := s.Get(ctx, "1")
+			if err != nil || !has {
+				t.Fatalf("expected the match, got %v %v", has, err)
+			}
+			if g.rematches != test.expectedRematches {
+				t.Errorf("expected %d games to be started, got %d", test.expectedRematches, g.rematches)
+			}
+			if len(result.GameIDs) != test.expectedGames {
+				t.Errorf("expected %d games, got %v", test.expectedGames, result.GameIDs)
+			}
+		})
+	}
+}
+
+func TestMatchService_CheckDelete(t *testing.T) {
+	m, _ := NewMatch("Friday", "1", 3, completedGame("1", "1"))
+	s := Service{
+		Col: &db.MockCollection[Match]{
+			MockFindOneResult: &[]Match{m, {}},
+			MockFindOneExists: &[]bool{true, false},
+			MockFindOneErr:    &[]error{},
+		},
+	}
+
+	err := s.CheckDelete(context.Background(), completedGame("1", "1"))
+	if api.CodeOf(err) != api.Conflict {
+		t.Errorf("expected a game in a match not to be deletable, got %v", err)
+	}
+	err = s.CheckDelete(context.Background(), completedGame("2", "1"))
+	if err != nil {
+		t.Errorf("expected a game outside a match to be deletable, got %v", err)
+	}
+}
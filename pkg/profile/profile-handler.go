@@ -46,11 +46,11 @@ func (h *Handler) Get(c *gin.Context) {
 	p, has, err := h.S.Get(ctx, id)
 
 	if err != nil {
-		c.JSON(http.StatusOK, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 	if !has {
-		c.JSON(http.StatusNotFound, api.ErrorResponse{Message: "User not found"})
+		api.WriteError(c, api.Errorf(api.NotFound, "User not found"))
 		return
 	}
 
@@ -83,14 +83,14 @@ func (h *Handler) Update(c *gin.Context) {
 	// Get the request body
 	var req UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
 		return
 	}
 
 	// Update the profile
 	p, exists, err := h.S.Get(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 	if !exists {
@@ -103,7 +103,7 @@ func (h *Handler) Update(c *gin.Context) {
 
 	err = h.S.Save(ctx, p)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 
@@ -133,7 +133,7 @@ func (h *Handler) GetAll(c *gin.Context) {
 	// Get all profiles
 	profiles, err := h.S.GetAll(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 
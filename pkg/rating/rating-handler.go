@@ -0,0 +1,78 @@
+package rating
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Handler struct {
+	S ServiceI
+}
+
+// Get @Summary Get a player's rating
+// @Description Returns the current rating of a player. Players who haven't completed a game have the initial rating of 1500.
+// @Tags Rating
+// @ID get-rating
+// @Produce json
+// @Param playerId path string true "Player ID"
+// @Security Bearer
+// @Success 200 {object} Rating
+// @Failure 500 {object} api.ErrorResponse
+// @Router /rating/{playerId} [get]
+func (h *Handler) Get(c *gin.Context) {
+	// Check the user is correctly authenticated
+	_, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the player ID from the request
+	playerId := c.Param("playerId")
+
+	// Get the rating
+	rating, err := h.S.Get(ctx, playerId)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, rating)
+}
+
+// GetHistory @Summary Get the rating history of a player
+// @Description Returns how a player's rating changed after each game they completed, oldest first
+// @Tags Rating
+// @ID get-rating-history
+// @Produce json
+// @Param playerId path string true "Player ID"
+// @Security Bearer
+// @Success 200 {array} Change
+// @Failure 500 {object} api.ErrorResponse
+// @Router /rating/{playerId}/history [get]
+func (h *Handler) GetHistory(c *gin.Context) {
+	// Check the user is correctly authenticated
+	_, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the player ID from the request
+	playerId := c.Param("playerId")
+
+	// Get the rating history
+	history, err := h.S.GetHistory(ctx, playerId)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, history)
+}
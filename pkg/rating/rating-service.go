@@ -0,0 +1,184 @@
+package rating
+
+import (
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type ServiceI interface {
+	Get(ctx context.Context, playerId string) (Rating, error)
+	GetHistory(ctx context.Context, playerId string) ([]Change, error)
+	GameCompleted(ctx context.Context, g game.Game) error
+	Backfill(ctx context.Context) (int, error)
+}
+
+type Service struct {
+	Col     db.CollectionI[Rating]
+	History db.CollectionI[Change]
+	Games   db.CollectionI[game.Game]
+}
+
+// Get returns a player's current rating, or the initial rating if they haven't completed a game.
+// Any of the player's changes that couldn't be applied when their game was completed are applied first.
+func (s *Service) Get(ctx context.Context, playerId string) (Rating, error) {
+	pending, err := s.History.Find(ctx, bson.M{"playerId": playerId, "applied": false})
+	if err != nil {
+		return Rating{}, err
+	}
+	for _, c := range pending {
+		if err := s.apply(ctx, c); err != nil {
+			log.Printf("Failed to apply the rating change of player %s in game %s: %v", c.PlayerID, c.GameID, err)
+		}
+	}
+
+	r, has, err := s.Col.FindOne(ctx, bson.M{"_id": playerId})
+	if err != nil {
+		return Rating{}, err
+	}
+	if !has {
+		return NewRating(playerId), nil
+	}
+	return r, nil
+}
+
+// GetHistory returns how a player's rating has changed, oldest first.
+func (s *Service) GetHistory(ctx context.Context, playerId string) ([]Change, error) {
+	changes, err := s.History.Find(ctx, bson.M{"playerId": playerId})
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		return []Change{}, nil
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Timestamp.Before(changes[j].Timestamp)
+	})
+	return changes, nil
+}
+
+// GameCompleted updates the ratings of the players in a completed game. A game is only rated once.
+// Inserting the changes claims the game, so if the game is completed more than once only the first call works out
+// its changes. Any of the changes that haven't been applied yet are then added to the ratings rather than
+// overwriting them, so games completing at the same time don't lose each other's changes. A change that can't be
+// applied is applied the next time the game is completed or the player's rating is read.
+func (s *Service) GameCompleted(ctx context.Context, g game.Game) error {
+	// Get the current ratings of the players
+	playerIDs := make([]string, len(g.Players))
+	for i, p := range g.Players {
+		playerIDs[i] = p.ID
+	}
+	current, err := s.Col.Find(ctx, bson.M{"_id": bson.M{"$in": playerIDs}})
+	if err != nil {
+		return err
+	}
+	ratings := make(map[string]Rating)
+	for _, r := range current {
+		ratings[r.PlayerID] = r
+	}
+
+	changes, err := Rate(g, ratings)
+	if err != nil {
+		return err
+	}
+	if err := s.History.InsertMany(ctx, changes); err != nil {
+		if !db.IsDuplicateKey(err) {
+			return err
+		}
+		// Already rated, only apply the changes that weren't applied at the time
+		changes, err = s.History.Find(ctx, bson.M{"gameId": g.ID, "applied": false})
+		if err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, c := range changes {
+		if err := s.apply(ctx, c); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply the rating change of player %s in game %s: %w", c.PlayerID, c.GameID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply adds a change to the player's rating and marks it as applied.
+// The rating remembers the change, so if it has already been added, e.g. by a call that failed before marking it,
+// it isn't added again. If the rating changed since the change was worked out the change is corrected to show the
+// rating it was added to.
+func (s *Service) apply(ctx context.Context, c Change) error {
+	// Make sure the player has a rating to add to
+	if err := s.Col.InsertMany(ctx, []Rating{NewRating(c.PlayerID)}); err != nil && !db.IsDuplicateKey(err) {
+		return err
+	}
+
+	delta := c.After - c.Before
+	r, err := s.Col.FindOneAndUpdate(ctx, bson.M{"_id": c.PlayerID, "changeIds": bson.M{"$ne": c.ID}}, bson.M{
+		"$inc":  bson.M{"rating": delta, "games": 1},
+		"$max":  bson.M{"timestamp": c.Timestamp},
+		"$push": bson.M{"changeIds": bson.M{"$each": []string{c.ID}, "$slice": -maxChangeIDs}},
+	})
+	if err != nil {
+		return err
+	}
+
+	applied := bson.M{"applied": true}
+	if r.PlayerID != "" && r.Rating != c.After {
+		applied["before"] = r.Rating - delta
+		applied["after"] = r.Rating
+	}
+	_, err = s.History.FindOneAndUpdate(ctx, bson.M{"_id": c.ID}, bson.M{"$set": applied})
+	return err
+}
+
+// Backfill recalculates every rating from scratch by replaying all completed games in the order they were played.
+// It returns the number of games rated.
+// The ratings are overwritten without checking for changes made while it runs, so the API must be stopped first.
+func (s *Service) Backfill(ctx context.Context) (int, error) {
+	games, err := s.Games.Find(ctx, bson.M{"status": game.Completed})
+	if err != nil {
+		return 0, err
+	}
+	sort.SliceStable(games, func(i, j int) bool {
+		return games[i].Timestamp.Before(games[j].Timestamp)
+	})
+
+	ratings := make(map[string]Rating)
+	history := make([]Change, 0)
+	rated := 0
+	for _, g := range games {
+		changes, err := Rate(g, ratings)
+		if err != nil {
+			log.Printf("Skipping game %s: %v", g.ID, err)
+			continue
+		}
+		Apply(ratings, changes)
+		for i := range changes {
+			changes[i].Applied = true
+		}
+		history = append(history, changes...)
+		rated++
+	}
+
+	return rated, s.save(ctx, ratings, history)
+}
+
+// save writes the ratings and the changes that led to them.
+func (s *Service) save(ctx context.Context, ratings map[string]Rating, changes []Change) error {
+	for _, c := range changes {
+		if err := s.History.Upsert(ctx, c, c.ID); err != nil {
+			return err
+		}
+	}
+	for _, r := range ratings {
+		if err := s.Col.Upsert(ctx, r, r.PlayerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package rating
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/game"
+	"math"
+	"time"
+)
+
+const (
+	// InitialRating is the rating of a player who hasn't completed a game.
+	InitialRating = 1500.0
+	// kFactor is the most a team's rating can change by in one game.
+	kFactor = 32.0
+	// maxChangeIDs is how many of the latest changes a rating remembers having been added to it.
+	maxChangeIDs = 100
+)
+
+// Rating is a player's current rating. Bots are rated the same as anyone else.
+type Rating struct {
+	PlayerID  string    `bson:"_id" json:"playerId"`
+	Rating    float64   `bson:"rating" json:"rating"`
+	Games     int       `bson:"games" json:"games"`
+	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
+	// ChangeIDs are the latest changes added to the rating, so a change that is retried is never added twice
+	ChangeIDs []string `bson:"changeIds" json:"-"`
+}
+
+// Change is how a player's rating changed after a game.
+type Change struct {
+	ID        string    `bson:"_id" json:"-"`
+	PlayerID  string    `bson:"playerId" json:"playerId"`
+	GameID    string    `bson:"gameId" json:"gameId"`
+	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
+	Before    float64   `bson:"before" json:"before"`
+	After     float64   `bson:"after" json:"after"`
+	// Applied is set once the change has been added to the player's rating
+	Applied bool `bson:"applied" json:"-"`
+}
+
+// NewRating returns the rating of a player who hasn't completed a game.
+func NewRating(playerID string) Rating {
+	return Rating{PlayerID: playerID, Rating: InitialRating}
+}
+
+type team struct {
+	playerIDs []string
+	rating    float64
+	score     int
+	winner    bool
+}
+
+// teams groups the players of a game into their teams. A team's rating is the average of its players' ratings.
+func teams(g game.Game, ratings map[string]Rating) []team {
+	result := make([]team, 0)
+	index := make(map[string]int)
+	for _, p := range g.Players {
+		i, ok := index[p.TeamID]
+		if !ok {
+			i = len(result)
+			index[p.TeamID] = i
+			result = append(result, team{score: p.Score})
+		}
+		t := &result[i]
+		t.playerIDs = append(t.playerIDs, p.ID)
+		t.winner = t.winner || p.Winner
+		t.score = max(t.score, p.Score)
+		r, ok := ratings[p.ID]
+		if !ok {
+			r = NewRating(p.ID)
+		}
+		t.rating += r.Rating
+	}
+	for i := range result {
+		result[i].rating /= float64(len(result[i].playerIDs))
+	}
+	return result
+}
+
+// outcome is the result of a game between two teams from the point of view of the first: 1 for a win, 0 for a loss
+// and 0.5 for a draw. The winning team beats everyone, otherwise the team with the higher score finishes ahead.
+func outcome(a team, b team) float64 {
+	switch {
+	case a.winner:
+		return 1
+	case b.winner:
+		return 0
+	case a.score > b.score:
+		return 1
+	case a.score < b.score:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// Rate works out how the ratings of the players in a completed game change.
+// Each team is treated as having played a head to head Elo match against every other team, with the changes scaled
+// so that a game counts the same no matter how many teams played. Every player on a team gets the team's change.
+func Rate(g game.Game, ratings map[string]Rating) ([]Change, error) {
+	if g.Status != game.Completed {
+		return nil, api.Errorf(api.WrongPhase, "game not completed")
+	}
+	ts := teams(g, ratings)
+	if len(ts) < 2 {
+		return nil, api.Errorf(api.InvalidRequest, "a game needs at least 2 teams to be rated")
+	}
+
+	changes := make([]Change, 0, len(g.Players))
+	for i, a := range ts {
+		delta := 0.0
+		for j, b := range ts {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (b.rating-a.rating)/400))
+			delta += outcome(a, b) - expected
+		}
+		delta *= kFactor / float64(len(ts)-1)
+
+		for _, id := range a.playerIDs {
+			before := InitialRating
+			if r, ok := ratings[id]; ok {
+				before = r.Rating
+			}
+			changes = append(changes, Change{
+				ID:        g.ID + "-" + id,
+				PlayerID:  id,
+				GameID:    g.ID,
+				Timestamp: g.Timestamp,
+				Before:    before,
+				After:     before + delta,
+			})
+		}
+	}
+	return changes, nil
+}
+
+// Apply updates the ratings with the changes from a game.
+func Apply(ratings map[string]Rating, changes []Change) {
+	for _, c := range changes {
+		r, ok := ratings[c.PlayerID]
+		if !ok {
+			r = NewRating(c.PlayerID)
+		}
+		r.Rating = c.After
+		r.Games++
+		r.Timestamp = c.Timestamp
+		ratings[c.PlayerID] = r
+	}
+}
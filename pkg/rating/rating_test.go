@@ -0,0 +1,274 @@
+package rating
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// completedGame returns a completed game where each player is on their own team, or on one of 3 teams of 2 with 6
+// players. The first player wins and the rest are given the scores.
+func completedGame(scores ...int) game.Game {
+	players := make([]game.Player, len(scores))
+	for i, score := range scores {
+		team := i + 1
+		if len(scores) == 6 {
+			team = i%3 + 1
+		}
+		players[i] = game.Player{
+			ID:     strconv.Itoa(i + 1),
+			TeamID: strconv.Itoa(team),
+			Score:  score,
+			Winner: team == 1,
+		}
+	}
+	return game.Game{ID: "g", Status: game.Completed, Players: players}
+}
+
+func after(changes []Change, playerID string) float64 {
+	for _, c := range changes {
+		if c.PlayerID == playerID {
+			return c.After
+		}
+	}
+	return math.NaN()
+}
+
+func TestRating_Rate(t *testing.T) {
+	tests := []struct {
+		name     string
+		game     game.Game
+		ratings  map[string]Rating
+		expected map[string]float64
+	}{
+		{
+			name:     "Head to head between new players",
+			game:     completedGame(110, 50),
+			expected: map[string]float64{"1": 1516, "2": 1484},
+		},
+		{
+			name:     "Favourite wins",
+			game:     completedGame(110, 50),
+			ratings:  map[string]Rating{"1": {PlayerID: "1", Rating: 1900}, "2": {PlayerID: "2", Rating: 1500}},
+			expected: map[string]float64{"1": 1902.9091, "2": 1497.0909},
+		},
+		{
+			name:     "Losers ordered by score",
+			game:     completedGame(110, 80, 20),
+			expected: map[string]float64{"1": 1516, "2": 1500, "3": 1484},
+		},
+		{
+			name:     "Losers with the same score draw",
+			game:     completedGame(110, 40, 40, 40, 40),
+			expected: map[string]float64{"1": 1516, "2": 1496, "3": 1496, "4": 1496, "5": 1496},
+		},
+		{
+			name:     "Teams of two",
+			game:     completedGame(115, 70, -10, 115, 70, -10),
+			expected: map[string]float64{"1": 1516, "4": 1516, "2": 1500, "5": 1500, "3": 1484, "6": 1484},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ratings := test.ratings
+			if ratings == nil {
+				ratings = map[string]Rating{}
+			}
+			changes, err := Rate(test.game, ratings)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(changes) != len(test.game.Players) {
+				t.Errorf("expected a change for each player, got %d", len(changes))
+			}
+			for id, expected := range test.expected {
+				if got := after(changes, id); math.Abs(got-expected) > 0.001 {
+					t.Errorf("expected player %s to be rated %.4f, got %.4f", id, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRating_RateErrors(t *testing.T) {
+	active := completedGame(110, 50)
+	active.Status = game.Active
+
+	single := completedGame(110, 50)
+	single.Players[1].TeamID = "1"
+
+	tests := []struct {
+		name         string
+		game         game.Game
+		expectedCode api.ErrorCode
+	}{
+		{name: "Game not completed", game: active, expectedCode: api.WrongPhase},
+		{name: "One team", game: single, expectedCode: api.InvalidRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Rate(test.game, map[string]Rating{})
+			if api.CodeOf(err) != test.expectedCode {
+				t.Errorf("expected %s, got %v", test.expectedCode, err)
+			}
+		})
+	}
+}
+
+func TestRating_Apply(t *testing.T) {
+	ratings := map[string]Rating{"1": {PlayerID: "1", Rating: 1600, Games: 4}}
+	changes, _ := Rate(completedGame(110, 50), ratings)
+	Apply(ratings, changes)
+
+	if ratings["1"].Games != 5 || ratings["1"].Rating <= 1600 {
+		t.Errorf("expected the winner's rating to go up after their fifth game, got %v", ratings["1"])
+	}
+	if ratings["2"].Games != 1 || ratings["2"].Rating >= InitialRating {
+		t.Errorf("expected the new player's rating to go down after their first game, got %v", ratings["2"])
+	}
+}
+
+func TestRatingService_GameCompleted(t *testing.T) {
+	duplicate := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Code: 11000}}}}
+	failed := errors.New("failed")
+	pending := Change{ID: "g-2", PlayerID: "2", GameID: "g", Before: 1500, After: 1490}
+
+	tests := []struct {
+		name              string
+		mockClaimErr      *[]error
+		mockPending       *[][]Change
+		mockCreateErr     *[]error
+		mockUpdated       *[]Rating
+		expectedIncrement int
+		expectedApplied   int
+		expectedError     bool
+	}{
+		{
+			name:              "every player's rating is incremented",
+			mockClaimErr:      &[]error{nil},
+			mockPending:       &[][]Change{},
+			mockUpdated:       &[]Rating{{PlayerID: "1"}, {PlayerID: "2"}},
+			expectedIncrement: 2,
+			expectedApplied:   2,
+		},
+		{
+			name:         "already rated",
+			mockClaimErr: &[]error{duplicate},
+			mockPending:  &[][]Change{{}},
+		},
+		{
+			name:              "already rated but a change wasn't applied",
+			mockClaimErr:      &[]error{duplicate},
+			mockPending:       &[][]Change{{pending}},
+			mockUpdated:       &[]Rating{{PlayerID: "2", Rating: 1490}},
+			expectedIncrement: 1,
+			expectedApplied:   1,
+		},
+		{
+			name:              "change already added to the rating is only marked as applied",
+			mockClaimErr:      &[]error{duplicate},
+			mockPending:       &[][]Change{{pending}},
+			mockUpdated:       &[]Rating{{}},
+			expectedIncrement: 1,
+			expectedApplied:   1,
+		},
+		{
+			name:          "claim fails",
+			mockClaimErr:  &[]error{failed},
+			mockPending:   &[][]Change{},
+			expectedError: true,
+		},
+		{
+			name:              "other changes are applied when one fails",
+			mockClaimErr:      &[]error{nil},
+			mockPending:       &[][]Change{},
+			mockCreateErr:     &[]error{failed, nil},
+			mockUpdated:       &[]Rating{{PlayerID: "2"}},
+			expectedIncrement: 1,
+			expectedApplied:   1,
+			expectedError:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ratings := &db.MockCollection[Rating]{
+				MockFindResult:             &[][]Rating{{{PlayerID: "1", Rating: 1600, Games: 4}}},
+				MockFindErr:                &[]error{nil},
+				MockInsertManyErr:          test.mockCreateErr,
+				MockFindOneAndUpdateResult: test.mockUpdated,
+			}
+			history := &db.MockCollection[Change]{
+				MockInsertManyErr: test.mockClaimErr,
+				MockFindResult:    test.mockPending,
+				MockFindErr:       &[]error{},
+			}
+			s := Service{Col: ratings, History: history}
+
+			err := s.GameCompleted(context.Background(), completedGame(110, 50))
+			if (err != nil) != test.expectedError {
+				t.Fatalf("expected error %v, got %v", test.expectedError, err)
+			}
+
+			increments := 0
+			for _, update := range ratings.FindOneAndUpdateCalls {
+				inc, ok := update["$inc"].(bson.M)
+				if !ok || inc["games"] != 1 {
+					t.Errorf("expected the rating to be incremented, got %v", update)
+				}
+				increments++
+			}
+			if increments != test.expectedIncrement {
+				t.Errorf("expected %d ratings to be incremented, got %d", test.expectedIncrement, increments)
+			}
+
+			applied := 0
+			for _, update := range history.FindOneAndUpdateCalls {
+				set, ok := update["$set"].(bson.M)
+				if !ok || set["applied"] != true {
+					t.Errorf("expected the change to be marked as applied, got %v", update)
+				}
+				applied++
+			}
+			if applied != test.expectedApplied {
+				t.Errorf("expected %d changes to be marked as applied, got %d", test.expectedApplied, applied)
+			}
+		})
+	}
+}
+
+func TestRatingService_Get(t *testing.T) {
+	pending := Change{ID: "g-1", PlayerID: "1", GameID: "g", Before: 1500, After: 1516}
+	ratings := &db.MockCollection[Rating]{
+		MockFindOneResult:          &[]Rating{{PlayerID: "1", Rating: 1516, Games: 1}},
+		MockFindOneExists:          &[]bool{true},
+		MockFindOneErr:             &[]error{},
+		MockFindOneAndUpdateResult: &[]Rating{{PlayerID: "1", Rating: 1516, Games: 1}},
+	}
+	history := &db.MockCollection[Change]{
+		MockFindResult: &[][]Change{{pending}},
+		MockFindErr:    &[]error{},
+	}
+	s := Service{Col: ratings, History: history}
+
+	r, err := s.Get(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Rating != 1516 {
+		t.Errorf("expected the rating with the pending change, got %v", r)
+	}
+	if len(ratings.FindOneAndUpdateCalls) != 1 || len(history.FindOneAndUpdateCalls) != 1 {
+		t.Errorf("expected the pending change to be applied, got %v", history.FindOneAndUpdateCalls)
+	}
+}
@@ -38,15 +38,12 @@ func (h *Handler) Get(c *gin.Context) {
 	settings, has, err := h.S.Get(ctx, id)
 
 	if err != nil {
-		c.JSON(http.StatusOK, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 	if !has {
 		log.Printf("No settings found for user, creating new settings")
-		settings = Settings{
-			ID:      id,
-			AutoBuy: true,
-		}
+		settings = Default(id)
 	}
 
 	c.IndentedJSON(http.StatusOK, settings)
@@ -78,7 +75,7 @@ func (h *Handler) Update(c *gin.Context) {
 	// Get the user from the database
 	var settings Settings
 	if err := c.ShouldBindJSON(&settings); err != nil {
-		c.JSON(http.StatusBadRequest, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
 		return
 	}
 
@@ -87,7 +84,7 @@ func (h *Handler) Update(c *gin.Context) {
 	log.Printf("Saving settings for user %s", id)
 
 	if err := h.S.Save(ctx, settings); err != nil {
-		c.JSON(http.StatusOK, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 
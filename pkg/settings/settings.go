@@ -1,6 +1,15 @@
 package settings
 
 type Settings struct {
-	ID      string `bson:"_id,omitempty" json:"-"`
-	AutoBuy bool   `bson:"autoBuyCards" json:"autoBuyCards"`
+	ID       string `bson:"_id,omitempty" json:"-"`
+	AutoBuy  bool   `bson:"autoBuyCards" json:"autoBuyCards"`
+	AutoPlay bool   `bson:"autoPlayCards" json:"autoPlayCards"`
+}
+
+// Default returns the settings for a user who hasn't saved any. Cards are bought automatically but never played.
+func Default(userId string) Settings {
+	return Settings{
+		ID:      userId,
+		AutoBuy: true,
+	}
 }
@@ -0,0 +1,48 @@
+package settings
+
+import (
+	"context"
+)
+
+type MockService struct {
+	MockGetResult *[]Settings
+	MockGetExists *[]bool
+	MockGetErr    *[]error
+	MockSaveErr   *[]error
+}
+
+func (m *MockService) Get(ctx context.Context, userId string) (Settings, bool, error) {
+	// Get the first element of the result array and remove it from the array, return nil if the array is empty
+	var result Settings
+	if m.MockGetResult != nil && len(*m.MockGetResult) > 0 {
+		result = (*m.MockGetResult)[0]
+		*m.MockGetResult = (*m.MockGetResult)[1:]
+	}
+
+	// Get the first element of the exists array and remove it from the array, return false if the array is empty
+	var exists bool
+	if m.MockGetExists != nil && len(*m.MockGetExists) > 0 {
+		exists = (*m.MockGetExists)[0]
+		*m.MockGetExists = (*m.MockGetExists)[1:]
+	}
+
+	// Get the first element of the error array and remove it from the array, return nil if the array is empty
+	var err error
+	if m.MockGetErr != nil && len(*m.MockGetErr) > 0 {
+		err = (*m.MockGetErr)[0]
+		*m.MockGetErr = (*m.MockGetErr)[1:]
+	}
+
+	return result, exists, err
+}
+
+func (m *MockService) Save(ctx context.Context, settings Settings) error {
+	// Get the first element of the error array and remove it from the array, return nil if the array is empty
+	var err error
+	if m.MockSaveErr != nil && len(*m.MockSaveErr) > 0 {
+		err = (*m.MockSaveErr)[0]
+		*m.MockSaveErr = (*m.MockSaveErr)[1:]
+	}
+
+	return err
+}
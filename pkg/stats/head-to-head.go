@@ -0,0 +1,77 @@
+package stats
+
+import (
+	"cards-110-api/pkg/game"
+)
+
+// HeadToHead compares how two players did in the completed games they played together.
+// Teammates in a game of 6 can win the same game, so the wins of both players don't always add up to the games played.
+type HeadToHead struct {
+	PlayerID     string `json:"playerId"`
+	OpponentID   string `json:"opponentId"`
+	Games        int    `json:"games"`
+	Wins         int    `json:"wins"`
+	OpponentWins int    `json:"opponentWins"`
+	// AverageScoreDifference is the player's final score minus the opponent's, averaged over the games
+	AverageScoreDifference float64 `json:"averageScoreDifference"`
+	Tricks                 int     `json:"tricks"`
+	OpponentTricks         int     `json:"opponentTricks"`
+	// RoundsWon are the rounds played out in which the player won more tricks than the opponent
+	RoundsWon         int `json:"roundsWon"`
+	OpponentRoundsWon int `json:"opponentRoundsWon"`
+	// RoundsDrawn are the rounds played out in which they won the same number of tricks. Rounds where neither of them
+	// won a trick aren't counted.
+	RoundsDrawn int `json:"roundsDrawn"`
+}
+
+// headToHeadTotals are totalled up by the database from the games two players played together.
+type headToHeadTotals struct {
+	Games           int `bson:"games"`
+	Wins            int `bson:"wins"`
+	OpponentWins    int `bson:"opponentWins"`
+	ScoreDifference int `bson:"scoreDifference"`
+}
+
+// headToHead compares two players using the totals of the completed games they played together and the rounds of those
+// games.
+func headToHead(playerID string, opponentID string, totals headToHeadTotals, games []game.Game) HeadToHead {
+	h := HeadToHead{
+		PlayerID:     playerID,
+		OpponentID:   opponentID,
+		Games:        totals.Games,
+		Wins:         totals.Wins,
+		OpponentWins: totals.OpponentWins,
+	}
+	if h.Games > 0 {
+		h.AverageScoreDifference = float64(totals.ScoreDifference) / float64(h.Games)
+	}
+
+	for _, g := range games {
+		for _, r := range rounds(g) {
+			tricks, _, _, err := r.Tricks()
+			if err != nil {
+				continue
+			}
+			mine, theirs := 0, 0
+			for _, trick := range tricks {
+				switch trick.PlayerID {
+				case playerID:
+					mine++
+				case opponentID:
+					theirs++
+				}
+			}
+			h.Tricks += mine
+			h.OpponentTricks += theirs
+			switch {
+			case mine > theirs:
+				h.RoundsWon++
+			case mine < theirs:
+				h.OpponentRoundsWon++
+			case mine > 0:
+				h.RoundsDrawn++
+			}
+		}
+	}
+	return h
+}
@@ -0,0 +1,175 @@
+package stats
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/cache"
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"context"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestStats_HeadToHead(t *testing.T) {
+	// Player 1 wins every trick of the last round
+	neither := roundStatsGame()
+	neither.CurrentRound.Suit = game.Hearts
+	neither.CurrentRound.CompletedHands = append(neither.Completed[0].CompletedHands[:4:4],
+		hand("1", "QUEEN_HEARTS", "2", "THREE_SPADES", "3", "TWO_SPADES"))
+
+	tests := []struct {
+		name       string
+		playerID   string
+		opponentID string
+		totals     headToHeadTotals
+		games      []game.Game
+		expected   HeadToHead
+	}{
+		{
+			name:       "One game",
+			playerID:   "2",
+			opponentID: "1",
+			totals:     headToHeadTotals{Games: 1, Wins: 1, ScoreDifference: 90},
+			games:      []game.Game{roundStatsGame()},
+			expected: HeadToHead{
+				PlayerID:               "2",
+				OpponentID:             "1",
+				Games:                  1,
+				Wins:                   1,
+				AverageScoreDifference: 90,
+				Tricks:                 6,
+				OpponentTricks:         4,
+				RoundsWon:              1,
+				OpponentRoundsWon:      1,
+			},
+		},
+		{
+			name:       "Games played together",
+			playerID:   "2",
+			opponentID: "1",
+			totals:     headToHeadTotals{Games: 2, Wins: 1, OpponentWins: 1, ScoreDifference: 10},
+			games:      []game.Game{roundStatsGame(), roundStatsGame()},
+			expected: HeadToHead{
+				PlayerID:               "2",
+				OpponentID:             "1",
+				Games:                  2,
+				Wins:                   1,
+				OpponentWins:           1,
+				AverageScoreDifference: 5,
+				Tricks:                 12,
+				OpponentTricks:         8,
+				RoundsWon:              2,
+				OpponentRoundsWon:      2,
+			},
+		},
+		{
+			name:       "Round where neither won a trick isn't a draw",
+			playerID:   "2",
+			opponentID: "3",
+			totals:     headToHeadTotals{Games: 1},
+			games:      []game.Game{neither},
+			expected: HeadToHead{
+				PlayerID:   "2",
+				OpponentID: "3",
+				Games:      1,
+				Tricks:     1,
+				RoundsWon:  1,
+			},
+		},
+		{
+			name:       "No games",
+			playerID:   "2",
+			opponentID: "1",
+			expected:   HeadToHead{PlayerID: "2", OpponentID: "1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := headToHead(test.playerID, test.opponentID, test.totals, test.games)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestStatsService_GetHeadToHead(t *testing.T) {
+	s := Service{
+		HeadToHeadCache: &cache.MockCache[HeadToHead]{
+			MockGetResult: &[]HeadToHead{{PlayerID: "1", OpponentID: "2", Games: 3}},
+			MockGetExists: &[]bool{true},
+			MockGetErr:    &[]error{nil},
+		},
+	}
+
+	_, err := s.GetHeadToHead(context.Background(), "1", "1")
+	if api.CodeOf(err) != api.InvalidRequest {
+		t.Errorf("expected comparing a player with themselves to be invalid, got %v", err)
+	}
+	h, err := s.GetHeadToHead(context.Background(), "1", "2")
+	if err != nil || h.Games != 3 {
+		t.Errorf("expected the cached comparison, got %v %v", h, err)
+	}
+}
+
+func TestStatsService_GetHeadToHeadPipeline(t *testing.T) {
+	totals := headToHeadTotals{Games: 4, Wins: 3, OpponentWins: 1, ScoreDifference: 50}
+	col := &db.MockCollection[game.Game]{MockAggregateResult: &[][]interface{}{{totals}, {}}}
+	s := Service{Col: col, HeadToHeadCache: missingCache[HeadToHead]()}
+
+	h, err := s.GetHeadToHead(context.Background(), "1", "2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Games != 4 || h.Wins != 3 || h.OpponentWins != 1 || h.AverageScoreDifference != 12.5 {
+		t.Errorf("expected the totals, got %+v", h)
+	}
+	if len(col.AggregateCalls) != 2 {
+		t.Fatalf("expected the totals and rounds to be aggregated, got %d", len(col.AggregateCalls))
+	}
+
+	match := bson.D{{Key: "status", Value: game.Completed}, {Key: "players._id", Value: bson.D{{Key: "$all", Value: bson.A{"1", "2"}}}}}
+	for _, pipeline := range col.AggregateCalls {
+		if m := stage(t, pipeline, 0, "$match"); !reflect.DeepEqual(m, match) {
+			t.Errorf("expected the games they played together, got %v", m)
+		}
+	}
+
+	totalsPipeline := col.AggregateCalls[0]
+	project := stage(t, totalsPipeline, 1, "$project")
+	for field, id := range map[string]string{"player": "1", "opponent": "2"} {
+		elem, _ := lookup(lookup(project, field), "$arrayElemAt").(bson.A)
+		if len(elem) != 2 {
+			t.Fatalf("expected the %s to be picked out of the players, got %v", field, project)
+		}
+		cond := lookup(lookup(elem[0], "$filter"), "cond")
+		if !reflect.DeepEqual(cond, bson.D{{Key: "$eq", Value: bson.A{"$$player._id", id}}}) {
+			t.Errorf("expected the %s to be player %s, got %v", field, id, cond)
+		}
+	}
+	group := stage(t, totalsPipeline, 2, "$group")
+	difference := bson.D{{Key: "$sum", Value: bson.D{{Key: "$subtract", Value: bson.A{"$player.score", "$opponent.score"}}}}}
+	if d := lookup(group, "scoreDifference"); !reflect.DeepEqual(d, difference) {
+		t.Errorf("expected the score difference to be summed, got %v", d)
+	}
+	wins := bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$opponent.winner", 1, 0}}}}}
+	if w := lookup(group, "opponentWins"); !reflect.DeepEqual(w, wins) {
+		t.Errorf("expected the opponent's wins to be counted, got %v", w)
+	}
+}
+
+func TestStatsService_GetHeadToHeadNoGames(t *testing.T) {
+	col := &db.MockCollection[game.Game]{MockAggregateResult: &[][]interface{}{{}, {}}}
+	s := Service{Col: col, HeadToHeadCache: missingCache[HeadToHead]()}
+
+	h, err := s.GetHeadToHead(context.Background(), "1", "2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Games != 0 || h.AverageScoreDifference != 0 {
+		t.Errorf("expected no games, got %+v", h)
+	}
+}
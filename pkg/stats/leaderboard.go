@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"cards-110-api/pkg/api"
+	"time"
+)
+
+type Metric string
+
+const (
+	Wins         Metric = "WINS"
+	WinRate             = "WIN_RATE"
+	AverageScore        = "AVERAGE_SCORE"
+	Rings               = "RINGS"
+	Rating              = "RATING"
+)
+
+// metricFields are the fields of a leaderboard entry that each metric ranks players by.
+var metricFields = map[Metric]string{
+	Wins:         "wins",
+	WinRate:      "winRate",
+	AverageScore: "averageScore",
+	Rings:        "rings",
+	Rating:       "rating",
+}
+
+type Window string
+
+const (
+	AllTime Window = "ALL_TIME"
+	// Season is the current quarter of the year.
+	Season = "SEASON"
+	Month  = "MONTH"
+	// Week starts on a Monday.
+	Week = "WEEK"
+)
+
+// LeaderboardEntry is how a player did in the games they completed in a window.
+// Rating is always the player's current rating.
+type LeaderboardEntry struct {
+	Rank         int     `bson:"-" json:"rank"`
+	PlayerID     string  `bson:"playerId" json:"playerId"`
+	Games        int     `bson:"games" json:"games"`
+	Wins         int     `bson:"wins" json:"wins"`
+	WinRate      float64 `bson:"winRate" json:"winRate"`
+	AverageScore float64 `bson:"averageScore" json:"averageScore"`
+	Rings        int     `bson:"rings" json:"rings"`
+	Rating       float64 `bson:"rating" json:"rating"`
+}
+
+type Leaderboard struct {
+	Metric  Metric             `json:"metric"`
+	Window  Window             `json:"window"`
+	From    *time.Time         `json:"from,omitempty"`
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+// windowStart returns when a window started, or nil for all time. Windows are calendar periods in UTC.
+func windowStart(window Window, now time.Time) (*time.Time, error) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var start time.Time
+	switch window {
+	case AllTime:
+		return nil, nil
+	case Season:
+		start = time.Date(now.Year(), now.Month()-(now.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case Month:
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case Week:
+		// Go weeks start on a Sunday
+		start = today.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+	default:
+		return nil, api.Errorf(api.InvalidRequest, "unknown window %s", window)
+	}
+	return &start, nil
+}
@@ -0,0 +1,208 @@
+package stats
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/cache"
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"cards-110-api/pkg/rating"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLeaderboard_WindowStart(t *testing.T) {
+	// A Thursday in the middle of the third quarter
+	now := time.Date(2024, time.August, 15, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		window   Window
+		expected *time.Time
+	}{
+		{name: "All time", window: AllTime},
+		{name: "Season", window: Season, expected: ptr(time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC))},
+		{name: "Month", window: Month, expected: ptr(time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC))},
+		{name: "Week", window: Week, expected: ptr(time.Date(2024, time.August, 12, 0, 0, 0, 0, time.UTC))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, err := windowStart(test.window, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (start == nil) != (test.expected == nil) || (start != nil && !start.Equal(*test.expected)) {
+				t.Errorf("expected %v, got %v", test.expected, start)
+			}
+		})
+	}
+}
+
+func TestLeaderboard_WindowStartEdges(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		window   Window
+		expected time.Time
+	}{
+		{name: "Sunday", now: time.Date(2024, time.August, 18, 23, 0, 0, 0, time.UTC), window: Week, expected: time.Date(2024, time.August, 12, 0, 0, 0, 0, time.UTC)},
+		{name: "Monday", now: time.Date(2024, time.August, 19, 1, 0, 0, 0, time.UTC), window: Week, expected: time.Date(2024, time.August, 19, 0, 0, 0, 0, time.UTC)},
+		{name: "First season", now: time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC), window: Season, expected: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Last season", now: time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC), window: Season, expected: time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, err := windowStart(test.window, test.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !start.Equal(test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, start)
+			}
+		})
+	}
+}
+
+func TestStatsService_GetLeaderboard(t *testing.T) {
+	cached := Leaderboard{Metric: Wins, Window: Week, Entries: []LeaderboardEntry{{Rank: 1, PlayerID: "1"}}}
+
+	tests := []struct {
+		name         string
+		metric       Metric
+		window       Window
+		limit        int
+		expectedCode api.ErrorCode
+	}{
+		{name: "Cached", metric: Wins, window: Week, limit: 10},
+		{name: "Unknown metric", metric: "LOSSES", window: Week, limit: 10, expectedCode: api.InvalidRequest},
+		{name: "Unknown window", metric: Wins, window: "DAY", limit: 10, expectedCode: api.InvalidRequest},
+		{name: "No players", metric: Wins, window: Week, limit: 0, expectedCode: api.InvalidRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := Service{
+				LeaderboardCache: &cache.MockCache[Leaderboard]{
+					MockGetResult: &[]Leaderboard{cached},
+					MockGetExists: &[]bool{true},
+					MockGetErr:    &[]error{nil},
+				},
+			}
+
+			leaderboard, err := s.GetLeaderboard(context.Background(), test.metric, test.window, test.limit)
+			if test.expectedCode != "" {
+				if api.CodeOf(err) != test.expectedCode {
+					t.Errorf("expected %s, got %v", test.expectedCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(leaderboard.Entries) != 1 || leaderboard.Entries[0].PlayerID != "1" {
+				t.Errorf("expected the cached leaderboard, got %v", leaderboard)
+			}
+		})
+	}
+}
+
+func TestStatsService_GetLeaderboardPipeline(t *testing.T) {
+	entries := []interface{}{
+		LeaderboardEntry{PlayerID: "2", Games: 3, Wins: 3},
+		LeaderboardEntry{PlayerID: "1", Games: 4, Wins: 2},
+	}
+
+	tests := []struct {
+		name          string
+		metric        Metric
+		window        Window
+		expectedMatch bson.D
+		expectedSort  string
+	}{
+		{
+			name:          "All time",
+			metric:        Wins,
+			window:        AllTime,
+			expectedMatch: bson.D{{Key: "status", Value: game.Completed}},
+			expectedSort:  "wins",
+		},
+		{
+			name:         "This week",
+			metric:       Rating,
+			window:       Week,
+			expectedSort: "rating",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			col := &db.MockCollection[game.Game]{MockAggregateResult: &[][]interface{}{entries}}
+			s := Service{Col: col, LeaderboardCache: missingCache[Leaderboard]()}
+
+			leaderboard, err := s.GetLeaderboard(context.Background(), test.metric, test.window, 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(col.AggregateCalls) != 1 {
+				t.Fatalf("expected one aggregation, got %d", len(col.AggregateCalls))
+			}
+			pipeline := col.AggregateCalls[0]
+
+			expectedMatch := test.expectedMatch
+			if leaderboard.From != nil {
+				expectedMatch = bson.D{
+					{Key: "status", Value: game.Completed},
+					{Key: "timestamp", Value: bson.D{{Key: "$gte", Value: *leaderboard.From}}},
+				}
+			}
+			if match := stage(t, pipeline, 0, "$match"); !reflect.DeepEqual(match, expectedMatch) {
+				t.Errorf("expected games matching %v, got %v", expectedMatch, match)
+			}
+			bots := bson.D{{Key: "players.bot", Value: bson.D{{Key: "$exists", Value: false}}}}
+			if match := stage(t, pipeline, 2, "$match"); !reflect.DeepEqual(match, bots) {
+				t.Errorf("expected bots to be left out, got %v", match)
+			}
+			if group := stage(t, pipeline, 3, "$group"); lookup(group, "_id") != "$players._id" {
+				t.Errorf("expected the games to be grouped by player, got %v", group)
+			}
+			lookupRatings := stage(t, pipeline, 4, "$lookup")
+			if lookup(lookupRatings, "from") != "ratings" {
+				t.Errorf("expected the ratings to be looked up, got %v", lookupRatings)
+			}
+			project := stage(t, pipeline, 5, "$project")
+			initial := bson.D{{Key: "$ifNull", Value: bson.A{
+				bson.D{{Key: "$arrayElemAt", Value: bson.A{"$ratings.rating", 0}}},
+				rating.InitialRating,
+			}}}
+			if r := lookup(project, "rating"); !reflect.DeepEqual(r, initial) {
+				t.Errorf("expected players without a rating to have the initial rating, got %v", r)
+			}
+			sort := stage(t, pipeline, 6, "$sort")
+			expectedSort := bson.D{{Key: test.expectedSort, Value: -1}, {Key: "games", Value: -1}, {Key: "playerId", Value: 1}}
+			if !reflect.DeepEqual(sort, expectedSort) {
+				t.Errorf("expected the players to be sorted by %v, got %v", expectedSort, sort)
+			}
+			if limit := stage(t, pipeline, 7, "$limit"); limit != 10 {
+				t.Errorf("expected a limit of 10, got %v", limit)
+			}
+
+			if len(leaderboard.Entries) != 2 {
+				t.Fatalf("expected 2 entries, got %v", leaderboard.Entries)
+			}
+			for i, entry := range leaderboard.Entries {
+				if entry.Rank != i+1 {
+					t.Errorf("expected %s to be ranked %d, got %d", entry.PlayerID, i+1, entry.Rank)
+				}
+			}
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,151 @@
+package stats
+
+import (
+	"cards-110-api/pkg/game"
+)
+
+// RoundStats are a player's stats from the rounds of their completed games.
+// Contracts, jinks and rounds in the bunker can only be counted for games that recorded the calls and scores of
+// each round.
+type RoundStats struct {
+	PlayerID string `json:"playerId"`
+	Games    int    `json:"games"`
+	Rounds   int    `json:"rounds"`
+	// Calls is how many times each call was made, passes included
+	Calls           map[game.Call]int `json:"calls"`
+	ContractsMade   int               `json:"contractsMade"`
+	ContractsFailed int               `json:"contractsFailed"`
+	JinksAttempted  int               `json:"jinksAttempted"`
+	JinksSucceeded  int               `json:"jinksSucceeded"`
+	TricksWon       int               `json:"tricksWon"`
+	// BestCardTricks are the tricks won with the best trump, worth 10 points
+	BestCardTricks int `json:"bestCardTricks"`
+	RoundsInBunker int `json:"roundsInBunker"`
+	// DealerSeeing is how many times the player took a call as the dealer
+	DealerSeeing int `json:"dealerSeeing"`
+}
+
+func newRoundStats(playerID string) RoundStats {
+	return RoundStats{PlayerID: playerID, Calls: make(map[game.Call]int)}
+}
+
+// rounds returns the rounds of a game, including the last round of a completed game.
+func rounds(g game.Game) []game.Round {
+	rounds := append([]game.Round{}, g.Completed...)
+	if g.Status == game.Completed && len(g.CurrentRound.CompletedHands) == 5 {
+		rounds = append(rounds, g.CurrentRound)
+	}
+	return rounds
+}
+
+// contract returns the call the goer made in a round, or Pass if the calls weren't recorded.
+func contract(r game.Round) game.Call {
+	call := game.Call(game.Pass)
+	for _, c := range r.Calls {
+		if c.PlayerID == r.GoerID && c.Call > call {
+			call = c.Call
+		}
+	}
+	return call
+}
+
+// addGame adds the rounds of a completed game to a player's stats.
+func (s *RoundStats) addGame(g game.Game) {
+	teams := make(map[string]string)
+	for _, p := range g.Players {
+		teams[p.ID] = p.TeamID
+	}
+	team, ok := teams[s.PlayerID]
+	if !ok {
+		return
+	}
+	// Games created before the rules could be chosen are played with house rules
+	rules, _ := game.NewRules(game.HouseRules)
+	if g.Rules != nil {
+		rules = *g.Rules
+	}
+
+	s.Games++
+	score := 0
+	scoresKnown := true
+	for _, r := range rounds(g) {
+		s.Rounds++
+		if scoresKnown && score < rules.Bunker {
+			s.RoundsInBunker++
+		}
+		for _, c := range r.Calls {
+			if c.PlayerID == s.PlayerID {
+				s.Calls[c.Call]++
+			}
+		}
+		if r.DealerID == s.PlayerID && r.DealerSeeing {
+			s.DealerSeeing++
+		}
+
+		// Follow the player's score from round to round for as long as it was recorded
+		playedOut := len(r.CompletedHands) == 5
+		if len(r.Scores) > 0 {
+			for _, rs := range r.Scores {
+				if rs.PlayerID == s.PlayerID {
+					score = rs.Total
+				}
+			}
+		} else if playedOut {
+			scoresKnown = false
+		}
+		if !playedOut {
+			continue
+		}
+
+		tricks, best, trumpPlayed, err := r.Tricks()
+		if err != nil {
+			continue
+		}
+		points := make(map[string]int)
+		allTricks := make(map[string]int)
+		for _, trick := range tricks {
+			value := 5
+			if trumpPlayed && trick.Card == best.Card {
+				value = 10
+				if trick.PlayerID == s.PlayerID {
+					s.BestCardTricks++
+				}
+			}
+			if trick.PlayerID == s.PlayerID {
+				s.TricksWon++
+			}
+			points[teams[trick.PlayerID]] += value
+			allTricks[teams[trick.PlayerID]]++
+		}
+
+		// Count the contracts the player went for
+		call := contract(r)
+		if r.GoerID != s.PlayerID || call == game.Pass {
+			continue
+		}
+		made := points[team] >= int(call)
+		if call == game.Jink && len(g.Players) > 2 {
+			s.JinksAttempted++
+			if allTricks[team] == len(tricks) {
+				s.JinksSucceeded++
+				made = true
+			}
+		}
+		if made {
+			s.ContractsMade++
+		} else {
+			s.ContractsFailed++
+		}
+	}
+}
+
+// roundStats works out a player's stats from their completed games.
+func roundStats(playerID string, games []game.Game) RoundStats {
+	stats := newRoundStats(playerID)
+	for _, g := range games {
+		if g.Status == game.Completed {
+			stats.addGame(g)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,174 @@
+package stats
+
+import (
+	"cards-110-api/pkg/game"
+	"reflect"
+	"testing"
+)
+
+// hand returns a completed hand from pairs of player IDs and the cards they played.
+func hand(plays ...string) game.Hand {
+	h := game.Hand{LeadOut: game.CardName(plays[1])}
+	for i := 0; i < len(plays); i += 2 {
+		h.PlayedCards = append(h.PlayedCards, game.PlayedCard{PlayerID: plays[i], Card: game.CardName(plays[i+1])})
+	}
+	return h
+}
+
+// roundStatsGame is a completed game between 3 players with 3 rounds:
+//   - Player 1 goes for 20 and makes it with 4 tricks including the best trump
+//   - Everyone passes, player 3 is in the bunker
+//   - Player 1 calls a jink, the dealer (player 2) takes it and gets all 5 tricks
+func roundStatsGame() game.Game {
+	return game.Game{
+		ID:     "1",
+		Status: game.Completed,
+		Players: []game.Player{
+			{ID: "1", TeamID: "1"},
+			{ID: "2", TeamID: "2"},
+			{ID: "3", TeamID: "3"},
+		},
+		Completed: []game.Round{
+			{
+				Number:   1,
+				DealerID: "3",
+				GoerID:   "1",
+				Suit:     game.Hearts,
+				Status:   game.Playing,
+				Calls: []game.PlayerCall{
+					{PlayerID: "1", Call: game.Twenty},
+					{PlayerID: "2", Call: game.Pass},
+					{PlayerID: "3", Call: game.Pass},
+				},
+				CompletedHands: []game.Hand{
+					hand("1", "FIVE_HEARTS", "2", "TWO_CLUBS", "3", "THREE_CLUBS"),
+					hand("1", "JACK_HEARTS", "2", "FOUR_CLUBS", "3", "SIX_CLUBS"),
+					hand("1", "ACE_HEARTS", "2", "SEVEN_CLUBS", "3", "EIGHT_CLUBS"),
+					hand("1", "KING_HEARTS", "2", "NINE_CLUBS", "3", "TEN_CLUBS"),
+					hand("2", "KING_CLUBS", "3", "THREE_SPADES", "1", "TWO_SPADES"),
+				},
+				Scores: []game.RoundScore{
+					{PlayerID: "1", Points: 25, Total: 25},
+					{PlayerID: "2", Points: 5, Total: 5},
+					{PlayerID: "3", Points: -35, Total: -35},
+				},
+			},
+			{
+				Number:   2,
+				DealerID: "1",
+				Status:   game.Calling,
+				Calls: []game.PlayerCall{
+					{PlayerID: "2", Call: game.Pass},
+					{PlayerID: "3", Call: game.Pass},
+					{PlayerID: "1", Call: game.Pass},
+				},
+			},
+		},
+		CurrentRound: game.Round{
+			Number:       3,
+			DealerID:     "2",
+			GoerID:       "2",
+			Suit:         game.Spades,
+			Status:       game.Playing,
+			DealerSeeing: true,
+			Calls: []game.PlayerCall{
+				{PlayerID: "3", Call: game.Pass},
+				{PlayerID: "1", Call: game.Jink},
+				{PlayerID: "2", Call: game.Jink},
+				{PlayerID: "1", Call: game.Pass},
+			},
+			CompletedHands: []game.Hand{
+				hand("2", "FIVE_SPADES", "3", "THREE_HEARTS", "1", "TWO_HEARTS"),
+				hand("2", "JACK_SPADES", "3", "SIX_HEARTS", "1", "FOUR_HEARTS"),
+				hand("2", "ACE_SPADES", "3", "EIGHT_HEARTS", "1", "SEVEN_HEARTS"),
+				hand("2", "KING_SPADES", "3", "TEN_HEARTS", "1", "NINE_HEARTS"),
+				hand("2", "QUEEN_SPADES", "3", "THREE_DIAMONDS", "1", "TWO_DIAMONDS"),
+			},
+			Scores: []game.RoundScore{
+				{PlayerID: "1", Points: 0, Total: 25},
+				{PlayerID: "2", Points: 60, Total: 65},
+				{PlayerID: "3", Points: 0, Total: -35},
+			},
+		},
+	}
+}
+
+func TestStats_RoundStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		playerID string
+		games    []game.Game
+		expected RoundStats
+	}{
+		{
+			name:     "Goer who made their contract",
+			playerID: "1",
+			games:    []game.Game{roundStatsGame()},
+			expected: RoundStats{
+				PlayerID:       "1",
+				Games:          1,
+				Rounds:         3,
+				Calls:          map[game.Call]int{game.Pass: 2, game.Twenty: 1, game.Jink: 1},
+				ContractsMade:  1,
+				TricksWon:      4,
+				BestCardTricks: 1,
+			},
+		},
+		{
+			name:     "Dealer who took a jink",
+			playerID: "2",
+			games:    []game.Game{roundStatsGame()},
+			expected: RoundStats{
+				PlayerID:       "2",
+				Games:          1,
+				Rounds:         3,
+				Calls:          map[game.Call]int{game.Pass: 2, game.Jink: 1},
+				ContractsMade:  1,
+				JinksAttempted: 1,
+				JinksSucceeded: 1,
+				TricksWon:      6,
+				BestCardTricks: 1,
+				DealerSeeing:   1,
+			},
+		},
+		{
+			name:     "Player in the bunker",
+			playerID: "3",
+			games:    []game.Game{roundStatsGame()},
+			expected: RoundStats{
+				PlayerID:       "3",
+				Games:          1,
+				Rounds:         3,
+				Calls:          map[game.Call]int{game.Pass: 3},
+				RoundsInBunker: 2,
+			},
+		},
+		{
+			name:     "Not in the game",
+			playerID: "4",
+			games:    []game.Game{roundStatsGame()},
+			expected: RoundStats{PlayerID: "4", Calls: map[game.Call]int{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := roundStats(test.playerID, test.games)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestStats_RoundStatsFailedContract(t *testing.T) {
+	g := roundStatsGame()
+	// Player 1 goes for 25 and player 2 trumps their fourth trick, leaving them with 20
+	g.Completed[0].Calls[0].Call = game.TwentyFive
+	g.Completed[0].CompletedHands[3] = hand("1", "NINE_CLUBS", "2", "KING_HEARTS", "3", "TEN_CLUBS")
+
+	result := roundStats("1", []game.Game{g})
+	if result.ContractsMade != 0 || result.ContractsFailed != 1 {
+		t.Errorf("expected a failed contract, got %d made and %d failed", result.ContractsMade, result.ContractsFailed)
+	}
+}
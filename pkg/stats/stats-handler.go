@@ -5,19 +5,73 @@ import (
 	"cards-110-api/pkg/auth"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type Handler struct {
 	S ServiceI
 }
 
+// parseTime parses a time given as RFC 3339 or as a date in UTC.
+func parseTime(value string) (*time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, value)
+	}
+	if err != nil {
+		return nil, api.Errorf(api.InvalidRequest, "invalid time %s", value)
+	}
+	return &t, nil
+}
+
+// filterFromQuery reads which games to include in the stats from the query parameters.
+func filterFromQuery(c *gin.Context) (Filter, error) {
+	filter := NewFilter()
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = parseTime(from); err != nil {
+			return Filter{}, err
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = parseTime(to); err != nil {
+			return Filter{}, err
+		}
+	}
+	filter.OpponentID = c.Query("opponent")
+
+	numbers := []struct {
+		name  string
+		value *int
+	}{
+		{name: "players", value: &filter.Players},
+		{name: "page", value: &filter.Page},
+		{name: "limit", value: &filter.Limit},
+	}
+	for _, n := range numbers {
+		if value := c.Query(n.name); value != "" {
+			if *n.value, err = strconv.Atoi(value); err != nil {
+				return Filter{}, api.Errorf(api.InvalidRequest, "invalid %s", n.name)
+			}
+		}
+	}
+	return filter, nil
+}
+
 // GetStats @Summary Get the user's stats
-// @Description Returns stats for the current user
+// @Description Returns a page of the current user's completed games, newest first, along with a summary of all the games that match the filters
 // @Tags Stats
 // @ID get-stats
 // @Produce json
+// @Param from query string false "Only games created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only games created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param opponent query string false "Only games played with this player"
+// @Param players query int false "Only games with this number of players"
+// @Param page query int false "Page, at most 10000" default(1)
+// @Param limit query int false "Games per page, at most 100" default(20)
 // @Security Bearer
-// @Success 200 {object} PlayerStats
+// @Success 200 {object} Stats
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /stats [get]
@@ -31,10 +85,17 @@ func (h *Handler) GetStats(c *gin.Context) {
 	// Get the context from the request
 	ctx := c.Request.Context()
 
+	// Get the filter from the query
+	filter, err := filterFromQuery(c)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
 	// Get the stats from the database
-	stats, err := h.S.GetStats(ctx, id)
+	stats, err := h.S.GetStats(ctx, id, filter)
 	if err != nil {
-		c.JSON(http.StatusOK, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 
@@ -42,12 +103,18 @@ func (h *Handler) GetStats(c *gin.Context) {
 }
 
 // GetStatsForPlayer @Summary Get the stats for a player
-// @Description Returns stats for a player
+// @Description Returns a page of a player's completed games, newest first, along with a summary of all the games that match the filters
 // @Tags Stats
 // @ID get-stats-for-player
 // @Produce json
 // @Param playerId path string true "Player ID"
-// @Success 200 {object} PlayerStats
+// @Param from query string false "Only games created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only games created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param opponent query string false "Only games played with this player"
+// @Param players query int false "Only games with this number of players"
+// @Param page query int false "Page, at most 10000" default(1)
+// @Param limit query int false "Games per page, at most 100" default(20)
+// @Success 200 {object} Stats
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /stats/{playerId} [get]
@@ -64,10 +131,157 @@ func (h *Handler) GetStatsForPlayer(c *gin.Context) {
 	// Get the player ID from the request
 	playerId := c.Param("playerId")
 
+	// Get the filter from the query
+	filter, err := filterFromQuery(c)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	// Get the stats from the database
+	stats, err := h.S.GetStats(ctx, playerId, filter)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, stats)
+}
+
+// GetLeaderboard @Summary Get a leaderboard
+// @Description Ranks the players who completed a game in a window of time. Players can be ranked by WINS, WIN_RATE, AVERAGE_SCORE, RINGS or RATING, where rating is always a player's current rating.
+// @Description The window is ALL_TIME, SEASON (the current quarter), MONTH or WEEK (starting on Monday), all in UTC.
+// @Tags Stats
+// @ID get-leaderboard
+// @Produce json
+// @Param metric query string false "Metric" Enums(WINS, WIN_RATE, AVERAGE_SCORE, RINGS, RATING) default(WINS)
+// @Param window query string false "Window" Enums(ALL_TIME, SEASON, MONTH, WEEK) default(ALL_TIME)
+// @Param limit query int false "Number of players" default(10)
+// @Security Bearer
+// @Success 200 {object} Leaderboard
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /leaderboard [get]
+func (h *Handler) GetLeaderboard(c *gin.Context) {
+	// Check the user is correctly authenticated
+	_, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the query parameters
+	metric := Metric(c.DefaultQuery("metric", string(Wins)))
+	window := Window(c.DefaultQuery("window", string(AllTime)))
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "invalid limit"))
+		return
+	}
+
+	// Get the leaderboard from the database
+	leaderboard, err := h.S.GetLeaderboard(ctx, metric, window, limit)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, leaderboard)
+}
+
+// GetRoundStats @Summary Get the user's round stats
+// @Description Returns stats for the current user from the rounds of their completed games: the calls they made, the contracts they made and failed, jinks, tricks, rounds in the bunker and how often they took a call as the dealer
+// @Tags Stats
+// @ID get-round-stats
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} RoundStats
+// @Failure 500 {object} api.ErrorResponse
+// @Router /stats/rounds [get]
+func (h *Handler) GetRoundStats(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the stats from the database
+	stats, err := h.S.GetRoundStats(ctx, id)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, stats)
+}
+
+// GetRoundStatsForPlayer @Summary Get the round stats for a player
+// @Description Returns stats for a player from the rounds of their completed games
+// @Tags Stats
+// @ID get-round-stats-for-player
+// @Produce json
+// @Param playerId path string true "Player ID"
+// @Success 200 {object} RoundStats
+// @Failure 500 {object} api.ErrorResponse
+// @Router /stats/{playerId}/rounds [get]
+func (h *Handler) GetRoundStatsForPlayer(c *gin.Context) {
+	// Check the user is correctly authenticated
+	_, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the player ID from the request
+	playerId := c.Param("playerId")
+
+	// Get the stats from the database
+	stats, err := h.S.GetRoundStats(ctx, playerId)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, stats)
+}
+
+// GetHeadToHead @Summary Compare two players
+// @Description Compares two players over the completed games they played together: how many games each won, the average difference in their final scores and how many tricks and rounds each won against the other
+// @Tags Stats
+// @ID get-head-to-head
+// @Produce json
+// @Param playerId path string true "Player ID"
+// @Param opponentId path string true "Opponent ID"
+// @Security Bearer
+// @Success 200 {object} HeadToHead
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /stats/head-to-head/{playerId}/{opponentId} [get]
+func (h *Handler) GetHeadToHead(c *gin.Context) {
+	// Check the user is correctly authenticated
+	_, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the player IDs from the request
+	playerId := c.Param("playerId")
+	opponentId := c.Param("opponentId")
+
 	// Get the stats from the database
-	stats, err := h.S.GetStats(ctx, playerId)
+	stats, err := h.S.GetHeadToHead(ctx, playerId, opponentId)
 	if err != nil {
-		c.JSON(http.StatusOK, api.ErrorResponse{Message: err.Error()})
+		api.WriteError(c, err)
 		return
 	}
 
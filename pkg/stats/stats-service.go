@@ -1,41 +1,119 @@
 package stats
 
 import (
+	"cards-110-api/pkg/api"
 	"cards-110-api/pkg/cache"
 	"cards-110-api/pkg/db"
 	"cards-110-api/pkg/game"
+	"cards-110-api/pkg/rating"
 	"context"
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"log"
 	"time"
 )
 
 type ServiceI interface {
-	GetStats(ctx context.Context, playerId string) ([]PlayerStats, error)
+	GetStats(ctx context.Context, playerId string, filter Filter) (Stats, error)
+	GetLeaderboard(ctx context.Context, metric Metric, window Window, limit int) (Leaderboard, error)
+	GetRoundStats(ctx context.Context, playerId string) (RoundStats, error)
+	GetHeadToHead(ctx context.Context, playerId string, opponentId string) (HeadToHead, error)
+	GameCompleted(ctx context.Context, g game.Game) error
 }
 
 type Service struct {
-	Col   db.CollectionI[game.Game]
-	Cache *cache.RedisCache[[]PlayerStats]
+	Col              db.CollectionI[game.Game]
+	Cache            cache.Cache[Stats]
+	LeaderboardCache cache.Cache[Leaderboard]
+	RoundStatsCache  cache.Cache[RoundStats]
+	HeadToHeadCache  cache.Cache[HeadToHead]
+	// Generations are part of every cache key, so changing a player's generation invalidates everything cached about
+	// them. Without it cached stats are only refreshed when they expire.
+	Generations cache.Cache[int64]
 }
 
-func getCacheKey(playerId string) string {
-	return "stats-" + playerId
+// generationExpiration must be longer than any stats are cached for, so that a generation can't expire and return to
+// a value stats are still cached under.
+const generationExpiration = 10 * time.Minute
+
+// leaderboardGeneration is the generation of every leaderboard, as any game can change them.
+const leaderboardGeneration = "leaderboard"
+
+func getGenerationKey(id string) string {
+	return "stats-generation-" + id
+}
+
+// generation returns the current generation of a player's stats, or of the leaderboards.
+func (s *Service) generation(id string) int64 {
+	if s.Generations == nil {
+		return 0
+	}
+	generation, found, err := s.Generations.Get(getGenerationKey(id))
+	if err != nil || !found {
+		return 0
+	}
+	return generation
+}
+
+// GameCompleted invalidates the cached stats of the players in a game that has just been completed, along with the
+// leaderboards.
+func (s *Service) GameCompleted(ctx context.Context, g game.Game) error {
+	if s.Generations == nil {
+		return nil
+	}
+	generation := time.Now().UnixNano()
+	ids := []string{leaderboardGeneration}
+	for _, p := range g.Players {
+		ids = append(ids, p.ID)
+	}
+	for _, id := range ids {
+		err := s.Generations.Set(getGenerationKey(id), generation, generationExpiration)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getCacheKey(playerId string, generation int64, filter Filter) string {
+	return fmt.Sprintf("stats-%s-%d-%s", playerId, generation, filter.key())
 }
 
-// GetStats Get the stats for a player.
-func (s *Service) GetStats(ctx context.Context, playerID string) ([]PlayerStats, error) {
+// GetStats Get a page of the stats for a player along with a summary of all the games that match the filter.
+func (s *Service) GetStats(ctx context.Context, playerID string, filter Filter) (Stats, error) {
+	err := filter.validate()
+	if err != nil {
+		return Stats{}, err
+	}
+
 	// Check the cache.
-	stats, found, err := s.Cache.Get(getCacheKey(playerID))
+	key := getCacheKey(playerID, s.generation(playerID), filter)
+	stats, found, err := s.Cache.Get(key)
 	if err == nil && found {
 		return stats, nil
 	}
 
+	match := bson.D{{Key: "status", Value: game.Completed}, {Key: "players._id", Value: playerID}}
+	if filter.OpponentID != "" {
+		match[1] = bson.E{Key: "players._id", Value: bson.D{{Key: "$all", Value: bson.A{playerID, filter.OpponentID}}}}
+	}
+	if filter.Players != 0 {
+		match = append(match, bson.E{Key: "players", Value: bson.D{{Key: "$size", Value: filter.Players}}})
+	}
+	if filter.From != nil || filter.To != nil {
+		timestamp := bson.D{}
+		if filter.From != nil {
+			timestamp = append(timestamp, bson.E{Key: "$gte", Value: *filter.From})
+		}
+		if filter.To != nil {
+			timestamp = append(timestamp, bson.E{Key: "$lt", Value: *filter.To})
+		}
+		match = append(match, bson.E{Key: "timestamp", Value: timestamp})
+	}
+
 	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.D{{Key: "status", Value: "COMPLETED"}, {Key: "players._id", Value: playerID}}}},
+		{{Key: "$match", Value: match}},
 		{{Key: "$unwind", Value: "$players"}},
 		{{Key: "$match", Value: bson.D{{Key: "players._id", Value: playerID}}}},
 		{{Key: "$project", Value: bson.D{
@@ -45,81 +123,290 @@ func (s *Service) GetStats(ctx context.Context, playerID string) ([]PlayerStats,
 			{Key: "score", Value: "$players.score"},
 			{Key: "rings", Value: "$players.rings"},
 		}}},
+		{{Key: "$facet", Value: bson.D{
+			{Key: "games", Value: bson.A{
+				bson.D{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: -1}, {Key: "gameId", Value: 1}}}},
+				bson.D{{Key: "$skip", Value: (filter.Page - 1) * filter.Limit}},
+				bson.D{{Key: "$limit", Value: filter.Limit}},
+			}},
+			{Key: "summary", Value: summaryPipeline()},
+		}}},
 	}
 
 	cursor, err := s.Col.Aggregate(ctx, pipeline)
 	if err != nil {
-		return []PlayerStats{}, err
+		return Stats{}, err
 	}
 
-	defer func(cursor *mongo.Cursor, ctx context.Context) {
-		err := cursor.Close(ctx)
-		if err != nil {
-			log.Fatal(err)
+	// The facets are returned as a single document
+	var results []struct {
+		Games   []PlayerStats `bson:"games"`
+		Summary []Summary     `bson:"summary"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return Stats{}, err
+	}
+
+	stats = Stats{Games: []PlayerStats{}, Page: filter.Page, Limit: filter.Limit}
+	if len(results) > 0 {
+		if results[0].Games != nil {
+			stats.Games = results[0].Games
+		}
+		if len(results[0].Summary) > 0 {
+			stats.Summary = results[0].Summary[0]
+			stats.Summary.CurrentStreak, stats.Summary.LongestWinStreak, stats.Summary.LongestLossStreak = streaks(stats.Summary.Results)
+			stats.Summary.Results = nil
 		}
-	}(cursor, ctx)
+	}
 
-	// Return an empty slice if there are no results.
-	if cursor.RemainingBatchLength() == 0 {
-		return []PlayerStats{}, nil
+	// Save the result to the cache.
+	err = s.Cache.Set(key, stats, 2*time.Minute)
+	if err != nil {
+		log.Printf("Failed to save state to cache: %s", err)
 	}
 
-	// Iterate over the cursor and decode each result.
-	var results []PlayerStats
-	for cursor.Next(ctx) {
-		var result bson.M
-		if err = cursor.Decode(&result); err != nil {
-			// Log the detailed error
-			log.Printf("Error decoding cursor result: %v", err)
-			return []PlayerStats{}, err
-		}
+	return stats, nil
+}
 
-		// Map the result to a PlayerStats struct.
-		playerStats := PlayerStats{}
+// summaryPipeline totals up the games, along with whether each game was won in the order they were played so that the
+// streaks can be worked out.
+func summaryPipeline() bson.A {
+	return bson.A{
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: 1}, {Key: "gameId", Value: 1}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "games", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "wins", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$winner", 1, 0}}}}}},
+			{Key: "totalScore", Value: bson.D{{Key: "$sum", Value: "$score"}}},
+			{Key: "rings", Value: bson.D{{Key: "$sum", Value: "$rings"}}},
+			{Key: "results", Value: bson.D{{Key: "$push", Value: "$winner"}}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "games", Value: 1},
+			{Key: "wins", Value: 1},
+			{Key: "rings", Value: 1},
+			{Key: "winRate", Value: bson.D{{Key: "$divide", Value: bson.A{"$wins", "$games"}}}},
+			{Key: "meanScore", Value: bson.D{{Key: "$divide", Value: bson.A{"$totalScore", "$games"}}}},
+			{Key: "results", Value: 1},
+		}}},
+	}
+}
 
-		// Safely assert types
-		if gameId, ok := result["gameId"].(string); ok {
-			playerStats.GameID = gameId
-		} else {
-			// Handle missing or invalid gameId
-			return []PlayerStats{}, fmt.Errorf("failed to decode gameID")
-		}
+func getLeaderboardCacheKey(metric Metric, window Window, limit int, generation int64) string {
+	return fmt.Sprintf("leaderboard-%s-%s-%d-%d", metric, window, limit, generation)
+}
 
-		if timestamp, ok := result["timestamp"].(primitive.DateTime); ok {
-			// Convert primitive.DateTime to time.Time
-			playerStats.Timestamp = time.Unix(int64(timestamp)/1000, 0)
-		} else {
-			// Handle missing or invalid timestamp
-			return []PlayerStats{}, fmt.Errorf("timestamp is not a valid DateTime")
-		}
+// GetLeaderboard ranks the players who completed a game in the window by a metric. Bots aren't ranked.
+func (s *Service) GetLeaderboard(ctx context.Context, metric Metric, window Window, limit int) (Leaderboard, error) {
+	field, ok := metricFields[metric]
+	if !ok {
+		return Leaderboard{}, api.Errorf(api.InvalidRequest, "unknown metric %s", metric)
+	}
+	from, err := windowStart(window, time.Now())
+	if err != nil {
+		return Leaderboard{}, err
+	}
+	if limit < 1 {
+		return Leaderboard{}, api.Errorf(api.InvalidRequest, "limit must be at least 1")
+	}
 
-		if winner, ok := result["winner"].(bool); ok {
-			playerStats.Winner = winner
-		} else {
-			return []PlayerStats{}, fmt.Errorf("winner is not a bool")
-		}
+	// Check the cache.
+	key := getLeaderboardCacheKey(metric, window, limit, s.generation(leaderboardGeneration))
+	leaderboard, found, err := s.LeaderboardCache.Get(key)
+	if err == nil && found {
+		return leaderboard, nil
+	}
 
-		if score, ok := result["score"].(int32); ok {
-			playerStats.Score = int(score)
-		} else {
-			return []PlayerStats{}, fmt.Errorf("score is not an int")
-		}
+	match := bson.D{{Key: "status", Value: game.Completed}}
+	if from != nil {
+		match = append(match, bson.E{Key: "timestamp", Value: bson.D{{Key: "$gte", Value: *from}}})
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$players"}},
+		{{Key: "$match", Value: bson.D{{Key: "players.bot", Value: bson.D{{Key: "$exists", Value: false}}}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$players._id"},
+			{Key: "games", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "wins", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$players.winner", 1, 0}}}}}},
+			{Key: "totalScore", Value: bson.D{{Key: "$sum", Value: "$players.score"}}},
+			{Key: "rings", Value: bson.D{{Key: "$sum", Value: "$players.rings"}}},
+		}}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "ratings"},
+			{Key: "localField", Value: "_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "ratings"},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "playerId", Value: "$_id"},
+			{Key: "games", Value: 1},
+			{Key: "wins", Value: 1},
+			{Key: "rings", Value: 1},
+			{Key: "winRate", Value: bson.D{{Key: "$divide", Value: bson.A{"$wins", "$games"}}}},
+			{Key: "averageScore", Value: bson.D{{Key: "$divide", Value: bson.A{"$totalScore", "$games"}}}},
+			{Key: "rating", Value: bson.D{{Key: "$ifNull", Value: bson.A{
+				bson.D{{Key: "$arrayElemAt", Value: bson.A{"$ratings.rating", 0}}},
+				rating.InitialRating,
+			}}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: field, Value: -1}, {Key: "games", Value: -1}, {Key: "playerId", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
 
-		if rings, ok := result["rings"].(int32); ok {
-			playerStats.Rings = int(rings)
-		} else {
-			return []PlayerStats{}, fmt.Errorf("rings is not an int")
-		}
+	cursor, err := s.Col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return Leaderboard{}, err
+	}
+	entries := make([]LeaderboardEntry, 0)
+	if err = cursor.All(ctx, &entries); err != nil {
+		return Leaderboard{}, err
+	}
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	leaderboard = Leaderboard{Metric: metric, Window: window, From: from, Entries: entries}
+
+	// Save the result to the cache.
+	err = s.LeaderboardCache.Set(key, leaderboard, 2*time.Minute)
+	if err != nil {
+		log.Printf("Failed to save leaderboard to cache: %s", err)
+	}
+
+	return leaderboard, nil
+}
+
+func getRoundStatsCacheKey(playerId string, generation int64) string {
+	return fmt.Sprintf("round-stats-%s-%d", playerId, generation)
+}
+
+// GetRoundStats works out a player's stats from the rounds of their completed games.
+func (s *Service) GetRoundStats(ctx context.Context, playerId string) (RoundStats, error) {
+	// Check the cache.
+	key := getRoundStatsCacheKey(playerId, s.generation(playerId))
+	stats, found, err := s.RoundStatsCache.Get(key)
+	if err == nil && found {
+		return stats, nil
+	}
 
-		results = append(results, playerStats)
+	// Only the players, rounds and rules are needed from each game
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "status", Value: game.Completed}, {Key: "players._id", Value: playerId}}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "status", Value: 1},
+			{Key: "rules", Value: 1},
+			{Key: "players", Value: 1},
+			{Key: "completedRounds", Value: 1},
+			{Key: "currentRound", Value: 1},
+		}}},
+	}
 
+	cursor, err := s.Col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return RoundStats{}, err
 	}
+	games := make([]game.Game, 0)
+	if err = cursor.All(ctx, &games); err != nil {
+		return RoundStats{}, err
+	}
+	stats = roundStats(playerId, games)
 
 	// Save the result to the cache.
-	err = s.Cache.Set(getCacheKey(playerID), results, 2*time.Minute)
+	err = s.RoundStatsCache.Set(key, stats, 2*time.Minute)
 	if err != nil {
-		log.Printf("Failed to save state to cache: %s", err)
+		log.Printf("Failed to save round stats to cache: %s", err)
+	}
+
+	return stats, nil
+}
+
+func getHeadToHeadCacheKey(playerId string, opponentId string, generation int64) string {
+	return fmt.Sprintf("head-to-head-%s-%s-%d", playerId, opponentId, generation)
+}
+
+// GetHeadToHead compares two players over the completed games they played together.
+func (s *Service) GetHeadToHead(ctx context.Context, playerId string, opponentId string) (HeadToHead, error) {
+	if playerId == opponentId {
+		return HeadToHead{}, api.Errorf(api.InvalidRequest, "a player can't be compared with themselves")
+	}
+
+	// Check the cache.
+	// Any game they played together changes the generation of both players, so either one will do
+	key := getHeadToHeadCacheKey(playerId, opponentId, s.generation(playerId))
+	h, found, err := s.HeadToHeadCache.Get(key)
+	if err == nil && found {
+		return h, nil
+	}
+
+	// The games and scores are totalled up by the database
+	playerIDs := bson.A{playerId, opponentId}
+	match := bson.D{{Key: "status", Value: game.Completed}, {Key: "players._id", Value: bson.D{{Key: "$all", Value: playerIDs}}}}
+	player := func(id string) bson.D {
+		return bson.D{{Key: "$arrayElemAt", Value: bson.A{
+			bson.D{{Key: "$filter", Value: bson.D{
+				{Key: "input", Value: "$players"},
+				{Key: "as", Value: "player"},
+				{Key: "cond", Value: bson.D{{Key: "$eq", Value: bson.A{"$$player._id", id}}}},
+			}}},
+			0,
+		}}}
+	}
+	totalsPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "player", Value: player(playerId)},
+			{Key: "opponent", Value: player(opponentId)},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "games", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "wins", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$player.winner", 1, 0}}}}}},
+			{Key: "opponentWins", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{"$opponent.winner", 1, 0}}}}}},
+			{Key: "scoreDifference", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$subtract", Value: bson.A{"$player.score", "$opponent.score"}}}}}},
+		}}},
+	}
+
+	cursor, err := s.Col.Aggregate(ctx, totalsPipeline)
+	if err != nil {
+		return HeadToHead{}, err
+	}
+	var totals []headToHeadTotals
+	if err = cursor.All(ctx, &totals); err != nil {
+		return HeadToHead{}, err
+	}
+	if len(totals) == 0 {
+		totals = append(totals, headToHeadTotals{})
+	}
+
+	// Who won each trick is worked out from the ranking of the cards, so only the hands of each round are loaded
+	roundsPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "status", Value: 1},
+			{Key: "completedRounds.suit", Value: 1},
+			{Key: "completedRounds.completedHands", Value: 1},
+			{Key: "currentRound.suit", Value: 1},
+			{Key: "currentRound.completedHands", Value: 1},
+		}}},
+	}
+
+	cursor, err = s.Col.Aggregate(ctx, roundsPipeline)
+	if err != nil {
+		return HeadToHead{}, err
+	}
+	games := make([]game.Game, 0)
+	if err = cursor.All(ctx, &games); err != nil {
+		return HeadToHead{}, err
+	}
+	h = headToHead(playerId, opponentId, totals[0], games)
+
+	// Save the result to the cache.
+	err = s.HeadToHeadCache.Set(key, h, 2*time.Minute)
+	if err != nil {
+		log.Printf("Failed to save head to head to cache: %s", err)
 	}
 
-	return results, nil
+	return h, nil
 }
@@ -1,6 +1,17 @@
 package stats
 
-import "time"
+import (
+	"cards-110-api/pkg/api"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+	// maxPage keeps the number of games skipped to reach a page well within range
+	maxPage = 10000
+)
 
 type PlayerStats struct {
 	GameID    string    `bson:"gameId" json:"gameId"`
@@ -9,3 +20,95 @@ type PlayerStats struct {
 	Score     int       `bson:"score" json:"score"`
 	Rings     int       `bson:"rings" json:"rings"`
 }
+
+// Summary totals up all the games that match a filter, not just the page returned.
+type Summary struct {
+	Games int `bson:"games" json:"games"`
+	Wins  int `bson:"wins" json:"wins"`
+	// WinRate is the fraction of the games that were won
+	WinRate   float64 `bson:"winRate" json:"winRate"`
+	MeanScore float64 `bson:"meanScore" json:"meanScore"`
+	Rings     int     `bson:"rings" json:"rings"`
+	// CurrentStreak is the number of games won in a row up to the latest game, or lost in a row if negative
+	CurrentStreak     int `bson:"currentStreak" json:"currentStreak"`
+	LongestWinStreak  int `bson:"longestWinStreak" json:"longestWinStreak"`
+	LongestLossStreak int `bson:"longestLossStreak" json:"longestLossStreak"`
+	// Results are whether each game was won, oldest first, used to work out the streaks
+	Results []bool `bson:"results" json:"-"`
+}
+
+// Stats is a page of a player's completed games, newest first, along with a summary of every game that matched.
+type Stats struct {
+	Games   []PlayerStats `json:"games"`
+	Summary Summary       `json:"summary"`
+	Page    int           `json:"page"`
+	Limit   int           `json:"limit"`
+}
+
+// streaks works out the current streak and the longest winning and losing streaks from the results of games, oldest
+// first. The current streak is negative if the latest game was lost.
+func streaks(results []bool) (current int, longestWin int, longestLoss int) {
+	run := 0
+	for i, won := range results {
+		if i > 0 && won == results[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		if won {
+			longestWin = max(longestWin, run)
+		} else {
+			longestLoss = max(longestLoss, run)
+		}
+	}
+	if len(results) > 0 && !results[len(results)-1] {
+		return -run, longestWin, longestLoss
+	}
+	return run, longestWin, longestLoss
+}
+
+// Filter chooses which of a player's completed games are included in their stats.
+type Filter struct {
+	// From and To bound when the games were created, either can be left unset
+	From *time.Time
+	To   *time.Time
+	// OpponentID only includes games that were played with another player
+	OpponentID string
+	// Players only includes games with this number of players, 0 for any number
+	Players int
+	Page    int
+	Limit   int
+}
+
+// NewFilter returns a filter for the first page of all games.
+func NewFilter() Filter {
+	return Filter{Page: 1, Limit: defaultLimit}
+}
+
+// validate checks the filter can be applied.
+func (f Filter) validate() error {
+	if f.Page < 1 || f.Page > maxPage {
+		return api.Errorf(api.InvalidRequest, "page must be between 1 and %d", maxPage)
+	}
+	if f.Limit < 1 || f.Limit > maxLimit {
+		return api.Errorf(api.InvalidRequest, "limit must be between 1 and %d", maxLimit)
+	}
+	if f.Players != 0 && (f.Players < 2 || f.Players > 6) {
+		return api.Errorf(api.InvalidRequest, "number of players must be between 2 and 6")
+	}
+	if f.From != nil && f.To != nil && f.To.Before(*f.From) {
+		return api.Errorf(api.InvalidRequest, "to must not be before from")
+	}
+	return nil
+}
+
+// key identifies the filter in a cache key.
+func (f Filter) key() string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%s-%s-%s-%d-%d-%d", formatTime(f.From), formatTime(f.To), f.OpponentID, f.Players, f.Page, f.Limit)
+}
@@ -0,0 +1,407 @@
+package stats
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/cache"
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestStats_FilterFromQuery(t *testing.T) {
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.April, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		query        string
+		expected     Filter
+		expectedCode api.ErrorCode
+	}{
+		{
+			name:     "Defaults",
+			expected: Filter{Page: 1, Limit: 20},
+		},
+		{
+			name:     "All filters",
+			query:    "from=2024-03-01&to=2024-04-01T12:30:00Z&opponent=2&players=3&page=2&limit=50",
+			expected: Filter{From: &from, To: &to, OpponentID: "2", Players: 3, Page: 2, Limit: 50},
+		},
+		{
+			name:         "Invalid date",
+			query:        "from=yesterday",
+			expectedCode: api.InvalidRequest,
+		},
+		{
+			name:         "Invalid page",
+			query:        "page=first",
+			expectedCode: api.InvalidRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/api/v1/stats?"+test.query, nil)
+
+			filter, err := filterFromQuery(c)
+			if test.expectedCode != "" {
+				if api.CodeOf(err) != test.expectedCode {
+					t.Errorf("expected %s, got %v", test.expectedCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if filter.key() != test.expected.key() {
+				t.Errorf("expected %s, got %s", test.expected.key(), filter.key())
+			}
+		})
+	}
+}
+
+func TestStats_Streaks(t *testing.T) {
+	tests := []struct {
+		name                string
+		results             []bool
+		expectedCurrent     int
+		expectedLongestWin  int
+		expectedLongestLoss int
+	}{
+		{name: "No games"},
+		{name: "One win", results: []bool{true}, expectedCurrent: 1, expectedLongestWin: 1},
+		{name: "One loss", results: []bool{false}, expectedCurrent: -1, expectedLongestLoss: 1},
+		{
+			name:                "Winning streak after losses",
+			results:             []bool{false, false, true, true, true},
+			expectedCurrent:     3,
+			expectedLongestWin:  3,
+			expectedLongestLoss: 2,
+		},
+		{
+			name:                "Losing streak shorter than the longest",
+			results:             []bool{false, false, false, true, true, false},
+			expectedCurrent:     -1,
+			expectedLongestWin:  2,
+			expectedLongestLoss: 3,
+		},
+		{
+			name:                "Alternating",
+			results:             []bool{true, false, true, false},
+			expectedCurrent:     -1,
+			expectedLongestWin:  1,
+			expectedLongestLoss: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			current, longestWin, longestLoss := streaks(test.results)
+			if current != test.expectedCurrent || longestWin != test.expectedLongestWin || longestLoss != test.expectedLongestLoss {
+				t.Errorf("expected %d %d %d, got %d %d %d", test.expectedCurrent, test.expectedLongestWin, test.expectedLongestLoss,
+					current, longestWin, longestLoss)
+			}
+		})
+	}
+}
+
+func TestStatsService_GetStats(t *testing.T) {
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	before := from.Add(-time.Hour)
+	cached := Stats{Games: []PlayerStats{{GameID: "1"}}, Summary: Summary{Games: 1}, Page: 1, Limit: 20}
+
+	tests := []struct {
+		name         string
+		filter       Filter
+		expectedCode api.ErrorCode
+	}{
+		{name: "Cached", filter: NewFilter()},
+		{name: "Page before the first", filter: Filter{Page: 0, Limit: 20}, expectedCode: api.InvalidRequest},
+		{name: "Page too far", filter: Filter{Page: maxPage + 1, Limit: 20}, expectedCode: api.InvalidRequest},
+		{name: "Too many games per page", filter: Filter{Page: 1, Limit: 101}, expectedCode: api.InvalidRequest},
+		{name: "Too many players", filter: Filter{Players: 7, Page: 1, Limit: 20}, expectedCode: api.InvalidRequest},
+		{name: "Range ends before it starts", filter: Filter{From: &from, To: &before, Page: 1, Limit: 20}, expectedCode: api.InvalidRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := Service{
+				Cache: &cache.MockCache[Stats]{
+					MockGetResult: &[]Stats{cached},
+					MockGetExists: &[]bool{true},
+					MockGetErr:    &[]error{nil},
+				},
+			}
+
+			stats, err := s.GetStats(context.Background(), "1", test.filter)
+			if test.expectedCode != "" {
+				if api.CodeOf(err) != test.expectedCode {
+					t.Errorf("expected %s, got %v", test.expectedCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stats.Summary.Games != 1 || len(stats.Games) != 1 {
+				t.Errorf("expected the cached stats, got %v", stats)
+			}
+		})
+	}
+}
+
+// stage returns the value of a stage of a pipeline, e.g. the filter of a $match, checking it is the expected stage.
+func stage(t *testing.T, pipeline interface{}, i int, operator string) interface{} {
+	t.Helper()
+	stages, ok := pipeline.(mongo.Pipeline)
+	if !ok || i >= len(stages) || len(stages[i]) == 0 || stages[i][0].Key != operator {
+		t.Fatalf("expected stage %d to be %s, got %v", i, operator, pipeline)
+	}
+	return stages[i][0].Value
+}
+
+// lookup returns the value of a field of a document, or nil if it isn't set.
+func lookup(d interface{}, key string) interface{} {
+	doc, _ := d.(bson.D)
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
+// missingCache is a cache that never has anything in it.
+func missingCache[T any]() *cache.MockCache[T] {
+	return &cache.MockCache[T]{
+		MockGetResult: &[]T{},
+		MockGetExists: &[]bool{},
+		MockGetErr:    &[]error{},
+		MockSetErr:    &[]error{},
+	}
+}
+
+func TestStatsService_GetStatsPipeline(t *testing.T) {
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	result := bson.M{
+		"games": []PlayerStats{{GameID: "3"}, {GameID: "2"}},
+		"summary": []bson.M{{
+			"games":   4,
+			"wins":    2,
+			"winRate": 0.5,
+			"results": []bool{false, true, true, false},
+		}},
+	}
+
+	tests := []struct {
+		name          string
+		filter        Filter
+		expectedMatch bson.D
+		expectedSkip  int
+		expectedLimit int
+	}{
+		{
+			name:          "All games",
+			filter:        NewFilter(),
+			expectedMatch: bson.D{{Key: "status", Value: game.Completed}, {Key: "players._id", Value: "1"}},
+			expectedLimit: defaultLimit,
+		},
+		{
+			name:   "Against an opponent",
+			filter: Filter{OpponentID: "2", Page: 1, Limit: 20},
+			expectedMatch: bson.D{
+				{Key: "status", Value: game.Completed},
+				{Key: "players._id", Value: bson.D{{Key: "$all", Value: bson.A{"1", "2"}}}},
+			},
+			expectedLimit: 20,
+		},
+		{
+			name:   "Number of players",
+			filter: Filter{Players: 4, Page: 1, Limit: 20},
+			expectedMatch: bson.D{
+				{Key: "status", Value: game.Completed},
+				{Key: "players._id", Value: "1"},
+				{Key: "players", Value: bson.D{{Key: "$size", Value: 4}}},
+			},
+			expectedLimit: 20,
+		},
+		{
+			name:   "Date range",
+			filter: Filter{From: &from, To: &to, Page: 1, Limit: 20},
+			expectedMatch: bson.D{
+				{Key: "status", Value: game.Completed},
+				{Key: "players._id", Value: "1"},
+				{Key: "timestamp", Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lt", Value: to}}},
+			},
+			expectedLimit: 20,
+		},
+		{
+			name:   "From a date",
+			filter: Filter{From: &from, Page: 1, Limit: 20},
+			expectedMatch: bson.D{
+				{Key: "status", Value: game.Completed},
+				{Key: "players._id", Value: "1"},
+				{Key: "timestamp", Value: bson.D{{Key: "$gte", Value: from}}},
+			},
+			expectedLimit: 20,
+		},
+		{
+			name:          "Third page",
+			filter:        Filter{Page: 3, Limit: 10},
+			expectedMatch: bson.D{{Key: "status", Value: game.Completed}, {Key: "players._id", Value: "1"}},
+			expectedSkip:  20,
+			expectedLimit: 10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			col := &db.MockCollection[game.Game]{MockAggregateResult: &[][]interface{}{{result}}}
+			s := Service{Col: col, Cache: missingCache[Stats]()}
+
+			stats, err := s.GetStats(context.Background(), "1", test.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(col.AggregateCalls) != 1 {
+				t.Fatalf("expected one aggregation, got %d", len(col.AggregateCalls))
+			}
+			pipeline := col.AggregateCalls[0]
+
+			if match := stage(t, pipeline, 0, "$match"); !reflect.DeepEqual(match, test.expectedMatch) {
+				t.Errorf("expected games matching %v, got %v", test.expectedMatch, match)
+			}
+			facet := stage(t, pipeline, 4, "$facet")
+			page, _ := lookup(facet, "games").(bson.A)
+			if len(page) != 3 {
+				t.Fatalf("expected the games to be sorted, skipped and limited, got %v", page)
+			}
+			if skip := lookup(page[1], "$skip"); skip != test.expectedSkip {
+				t.Errorf("expected %d games to be skipped, got %v", test.expectedSkip, skip)
+			}
+			if limit := lookup(page[2], "$limit"); limit != test.expectedLimit {
+				t.Errorf("expected a limit of %d, got %v", test.expectedLimit, limit)
+			}
+			if summary := lookup(facet, "summary"); !reflect.DeepEqual(summary, summaryPipeline()) {
+				t.Errorf("expected the summary pipeline, got %v", summary)
+			}
+
+			if len(stats.Games) != 2 || stats.Games[0].GameID != "3" {
+				t.Errorf("expected the page of games, got %v", stats.Games)
+			}
+			summary := stats.Summary
+			if summary.Games != 4 || summary.Wins != 2 || summary.WinRate != 0.5 {
+				t.Errorf("expected the summary totals, got %+v", summary)
+			}
+			if summary.CurrentStreak != -1 || summary.LongestWinStreak != 2 || summary.LongestLossStreak != 1 {
+				t.Errorf("expected the streaks to be worked out from the results, got %+v", summary)
+			}
+			if summary.Results != nil {
+				t.Errorf("expected the results to be cleared, got %v", summary.Results)
+			}
+			if stats.Page != test.filter.Page || stats.Limit != test.filter.Limit {
+				t.Errorf("expected page %d of %d, got %d of %d", test.filter.Page, test.filter.Limit, stats.Page, stats.Limit)
+			}
+		})
+	}
+}
+
+func TestStatsService_GetStatsNoGames(t *testing.T) {
+	col := &db.MockCollection[game.Game]{MockAggregateResult: &[][]interface{}{{bson.M{"games": bson.A{}, "summary": bson.A{}}}}}
+	s := Service{Col: col, Cache: missingCache[Stats]()}
+
+	stats, err := s.GetStats(context.Background(), "1", NewFilter())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Games == nil || len(stats.Games) != 0 || stats.Summary.Games != 0 {
+		t.Errorf("expected no games and an empty summary, got %+v", stats)
+	}
+}
+
+func TestStats_SummaryPipeline(t *testing.T) {
+	pipeline := summaryPipeline()
+	if len(pipeline) != 3 {
+		t.Fatalf("expected the games to be sorted, grouped and projected, got %v", pipeline)
+	}
+
+	// The streaks depend on the results being pushed oldest first
+	sort := lookup(pipeline[0], "$sort")
+	if !reflect.DeepEqual(sort, bson.D{{Key: "timestamp", Value: 1}, {Key: "gameId", Value: 1}}) {
+		t.Errorf("expected the games to be sorted oldest first, got %v", sort)
+	}
+	group := lookup(pipeline[1], "$group")
+	if results := lookup(group, "results"); !reflect.DeepEqual(results, bson.D{{Key: "$push", Value: "$winner"}}) {
+		t.Errorf("expected whether each game was won to be pushed, got %v", results)
+	}
+	if id := lookup(group, "_id"); id != nil {
+		t.Errorf("expected every game to be grouped together, got %v", id)
+	}
+
+	project, _ := lookup(pipeline[2], "$project").(bson.D)
+	for _, field := range []string{"games", "wins", "rings", "winRate", "meanScore", "results"} {
+		if lookup(project, field) == nil {
+			t.Errorf("expected %s to be in the summary, got %v", field, project)
+		}
+	}
+}
+
+// generations is an in-memory cache of generations.
+type generations map[string]int64
+
+func (g generations) Set(key string, value int64, _ time.Duration) error {
+	g[key] = value
+	return nil
+}
+
+func (g generations) Get(key string) (int64, bool, error) {
+	value, found := g[key]
+	return value, found, nil
+}
+
+func (g generations) Delete(key string) error {
+	delete(g, key)
+	return nil
+}
+
+func TestStatsService_GameCompleted(t *testing.T) {
+	s := Service{Generations: generations{}}
+	filter := NewFilter()
+	key := func(playerID string) string {
+		return getCacheKey(playerID, s.generation(playerID), filter)
+	}
+	players := []string{"1", "2", "3"}
+	before := map[string]string{}
+	for _, id := range players {
+		before[id] = key(id)
+	}
+	leaderboard := getLeaderboardCacheKey(Wins, AllTime, 10, s.generation(leaderboardGeneration))
+
+	g := game.Game{ID: "1", Status: game.Completed, Players: []game.Player{{ID: "1"}, {ID: "2"}}}
+	err := s.GameCompleted(context.Background(), g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, id := range []string{"1", "2"} {
+		if key(id) == before[id] {
+			t.Errorf("expected the stats of player %s to be invalidated", id)
+		}
+	}
+	if key("3") != before["3"] {
+		t.Errorf("expected the stats of player 3 to still be cached")
+	}
+	if getLeaderboardCacheKey(Wins, AllTime, 10, s.generation(leaderboardGeneration)) == leaderboard {
+		t.Errorf("expected the leaderboards to be invalidated")
+	}
+}
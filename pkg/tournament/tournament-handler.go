@@ -0,0 +1,239 @@
+package tournament
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/auth"
+	"cards-110-api/pkg/game"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type Handler struct {
+	S ServiceI
+}
+
+type CreateTournamentRequest struct {
+	Name          string           `json:"name"`
+	Format        Format           `json:"format"`
+	TableSize     int              `json:"tableSize"`
+	Rules         game.RulesPreset `json:"rules"`
+	TurnTimeLimit int              `json:"turnTimeLimit"`
+}
+
+// Create @Summary Create a new tournament
+// @Description Creates a tournament that players can register for until the admin starts it.
+// @Description The format is ROUND_ROBIN, where everyone plays a fixed number of rounds against different opponents, or KNOCKOUT, where only the winners of each table go through to the next round.
+// @Description Tables are made up of 2-6 players and every game is played with the same rules and turn time limit.
+// @Tags Tournament
+// @ID create-tournament
+// @Accept json
+// @Produce json
+// @Param tournament body CreateTournamentRequest true "Tournament"
+// @Security Bearer
+// @Success 200 {object} Tournament
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /tournament [put]
+func (h *Handler) Create(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the request body
+	var req CreateTournamentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.WriteError(c, api.Errorf(api.InvalidRequest, "%v", err))
+		return
+	}
+
+	// Create the tournament
+	tournament, err := h.S.Create(ctx, req.Name, req.Format, req.TableSize, req.Rules, req.TurnTimeLimit, id)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, tournament)
+}
+
+// Get @Summary Get a tournament
+// @Description Returns a tournament with the given ID, including the tables and games of every round played so far
+// @Tags Tournament
+// @ID get-tournament
+// @Produce json
+// @Param tournamentId path string true "Tournament ID"
+// @Security Bearer
+// @Success 200 {object} Tournament
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /tournament/{tournamentId} [get]
+func (h *Handler) Get(c *gin.Context) {
+	// Check the user is correctly authenticated
+	_, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the tournament ID from the request
+	tournamentId := c.Param("tournamentId")
+
+	// Get the tournament from the database
+	tournament, has, err := h.S.Get(ctx, tournamentId)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	if !has {
+		api.WriteError(c, ErrNotFound)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, tournament)
+}
+
+// GetAll @Summary Get all tournaments
+// @Description Returns all tournaments
+// @Tags Tournament
+// @ID get-all-tournaments
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} Tournament
+// @Failure 500 {object} api.ErrorResponse
+// @Router /tournament/all [get]
+func (h *Handler) GetAll(c *gin.Context) {
+	// Check the user is correctly authenticated
+	_, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get all tournaments from the database
+	tournaments, err := h.S.GetAll(ctx)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, tournaments)
+}
+
+// GetStandings @Summary Get the standings of a tournament
+// @Description Returns the entrants of a tournament ordered by how they are doing. In a knockout tournament the entrants still in the tournament come first.
+// @Tags Tournament
+// @ID get-tournament-standings
+// @Produce json
+// @Param tournamentId path string true "Tournament ID"
+// @Security Bearer
+// @Success 200 {array} Standing
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /tournament/{tournamentId}/standings [get]
+func (h *Handler) GetStandings(c *gin.Context) {
+	// Check the user is correctly authenticated
+	_, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the tournament ID from the request
+	tournamentId := c.Param("tournamentId")
+
+	// Get the tournament from the database
+	tournament, has, err := h.S.Get(ctx, tournamentId)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+	if !has {
+		api.WriteError(c, ErrNotFound)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, tournament.Standings())
+}
+
+// Register @Summary Register for a tournament
+// @Description Enters the current user in a tournament that hasn't started yet
+// @Tags Tournament
+// @ID register-tournament
+// @Produce json
+// @Param tournamentId path string true "Tournament ID"
+// @Security Bearer
+// @Success 200 {object} Tournament
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /tournament/{tournamentId}/register [put]
+func (h *Handler) Register(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the tournament ID from the request
+	tournamentId := c.Param("tournamentId")
+
+	// Register the player
+	tournament, err := h.S.Register(ctx, tournamentId, id)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, tournament)
+}
+
+// Start @Summary Start a tournament
+// @Description Closes registration, draws the tables for the first round and creates their games. Only the admin can start a tournament.
+// @Tags Tournament
+// @ID start-tournament
+// @Produce json
+// @Param tournamentId path string true "Tournament ID"
+// @Security Bearer
+// @Success 200 {object} Tournament
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 403 {object} api.ErrorResponse
+// @Failure 404 {object} api.ErrorResponse
+// @Failure 409 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /tournament/{tournamentId}/start [put]
+func (h *Handler) Start(c *gin.Context) {
+	// Check the user is correctly authenticated
+	id, ok := auth.CheckValidated(c)
+	if !ok {
+		return
+	}
+
+	// Get the context from the request
+	ctx := c.Request.Context()
+
+	// Get the tournament ID from the request
+	tournamentId := c.Param("tournamentId")
+
+	// Start the tournament
+	tournament, err := h.S.Start(ctx, tournamentId, id)
+	if err != nil {
+		api.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, tournament)
+}
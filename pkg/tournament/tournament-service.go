@@ -0,0 +1,264 @@
+package tournament
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"context"
+	"errors"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrNotFound = api.Errorf(api.NotFound, "tournament not found")
+
+// ErrConflict is returned when a tournament couldn't be saved because it kept being updated by other requests.
+var ErrConflict = api.Errorf(api.Conflict, "tournament was updated by another request, please try again")
+
+// errRecorded is returned when the result of a game has already been recorded, e.g. by another instance.
+var errRecorded = errors.New("result already recorded")
+
+// errRoundOver is returned when the games created for a round can no longer be added to it.
+var errRoundOver = errors.New("round is over")
+
+// maxUpdateAttempts is the number of times a change is attempted before giving up because of concurrent updates.
+const maxUpdateAttempts = 3
+
+type ServiceI interface {
+	Create(ctx context.Context, name string, format Format, tableSize int, rules game.RulesPreset, turnTimeLimit int, adminID string) (Tournament, error)
+	Get(ctx context.Context, tournamentId string) (Tournament, bool, error)
+	GetAll(ctx context.Context) ([]Tournament, error)
+	Register(ctx context.Context, tournamentId string, playerId string) (Tournament, error)
+	Start(ctx context.Context, tournamentId string, adminId string) (Tournament, error)
+	GameCompleted(ctx context.Context, g game.Game) error
+}
+
+type Service struct {
+	Col   db.CollectionI[Tournament]
+	Games game.ServiceI
+}
+
+func (s *Service) Create(ctx context.Context, name string, format Format, tableSize int, rules game.RulesPreset, turnTimeLimit int, adminID string) (Tournament, error) {
+	t, err := NewTournament(name, adminID, format, tableSize, rules, turnTimeLimit)
+	if err != nil {
+		return Tournament{}, err
+	}
+	err = s.Col.Upsert(ctx, t, t.ID)
+	if err != nil {
+		return Tournament{}, err
+	}
+	return t, nil
+}
+
+// Get returns a tournament by ID.
+// An active tournament is resumed first, in case a game in the current round couldn't be started or its result
+// couldn't be recorded when it was completed. If it still can't be resumed the tournament is returned as it is.
+func (s *Service) Get(ctx context.Context, tournamentId string) (Tournament, bool, error) {
+	t, has, err := s.Col.FindOne(ctx, bson.M{"_id": tournamentId})
+	if err != nil || !has || t.Status != Active {
+		return t, has, err
+	}
+
+	resumed, err := s.resume(ctx, t)
+	if err != nil {
+		log.Printf("Failed to resume tournament %s: %v", t.ID, err)
+		return t, true, nil
+	}
+	return resumed, true, nil
+}
+
+func (s *Service) GetAll(ctx context.Context) ([]Tournament, error) {
+	return s.Col.Find(ctx, bson.M{})
+}
+
+// resume records the results of any games in the current round that were completed without being recorded, then
+// starts the games of any tables that don't have one. It can be called any number of times.
+func (s *Service) resume(ctx context.Context, t Tournament) (Tournament, error) {
+	recorded := false
+	for _, table := range t.CurrentRound().Tables {
+		if table.Completed || table.GameID == "" {
+			continue
+		}
+		g, has, err := s.Games.Get(ctx, table.GameID)
+		if err != nil {
+			return Tournament{}, err
+		}
+		if !has || g.Status != game.Completed {
+			continue
+		}
+		err = s.GameCompleted(ctx, g)
+		if err != nil {
+			return Tournament{}, err
+		}
+		recorded = true
+	}
+
+	if recorded {
+		latest, has, err := s.Col.FindOne(ctx, bson.M{"_id": t.ID})
+		if err != nil {
+			return Tournament{}, err
+		}
+		if !has {
+			return Tournament{}, ErrNotFound
+		}
+		t = latest
+	}
+	return s.startGames(ctx, t)
+}
+
+// CheckDelete stops a game being deleted while it is seated in a tournament, which would leave its table without a
+// game to finish.
+func (s *Service) CheckDelete(ctx context.Context, g game.Game) error {
+	t, has, err := s.Col.FindOne(ctx, bson.M{"rounds.tables.gameId": g.ID, "status": Active})
+	if err != nil {
+		return err
+	}
+	if has {
+		return api.Errorf(api.Conflict, "game is part of tournament %s", t.ID)
+	}
+	return nil
+}
+
+// update applies a change to the latest revision of a tournament and saves it.
+// The save only succeeds if no one else has saved a new revision since the tournament was read. If they have, the
+// change is applied again to the new revision. ErrConflict is returned if the tournament still can't be saved after
+// maxUpdateAttempts.
+func (s *Service) update(ctx context.Context, tournamentId string, change func(t *Tournament) error) (Tournament, error) {
+	for attempt := 1; attempt <= maxUpdateAttempts; attempt++ {
+		t, has, err := s.Col.FindOne(ctx, bson.M{"_id": tournamentId})
+		if err != nil {
+			return Tournament{}, err
+		}
+		if !has {
+			return Tournament{}, ErrNotFound
+		}
+
+		revision := t.Revision
+		err = change(&t)
+		if err != nil {
+			return Tournament{}, err
+		}
+		t.Revision++
+
+		saved, err := s.Col.ConditionalUpdateOne(ctx, t, bson.M{"_id": t.ID, "revision": revision})
+		if err != nil {
+			return Tournament{}, err
+		}
+		if saved {
+			return t, nil
+		}
+		log.Printf("Tournament %s was updated concurrently at revision %d (attempt %d)", tournamentId, revision, attempt)
+	}
+
+	return Tournament{}, ErrConflict
+}
+
+func (s *Service) Register(ctx context.Context, tournamentId string, playerId string) (Tournament, error) {
+	return s.update(ctx, tournamentId, func(t *Tournament) error {
+		return t.Register(playerId)
+	})
+}
+
+// Start closes registration and starts the games of the first round once the tournament has been saved.
+func (s *Service) Start(ctx context.Context, tournamentId string, adminId string) (Tournament, error) {
+	t, err := s.update(ctx, tournamentId, func(t *Tournament) error {
+		return t.Start(adminId)
+	})
+	if err != nil {
+		return Tournament{}, err
+	}
+	return s.startGames(ctx, t)
+}
+
+// GameCompleted records the result of a tournament game and starts the games of the next round once every table in
+// the current round has finished. Games that aren't part of a tournament, or whose result has already been recorded,
+// are ignored.
+func (s *Service) GameCompleted(ctx context.Context, g game.Game) error {
+	t, has, err := s.Col.FindOne(ctx, bson.M{"rounds.tables.gameId": g.ID, "status": Active})
+	if err != nil {
+		return err
+	}
+	if !has {
+		return nil
+	}
+
+	t, err = s.update(ctx, t.ID, func(t *Tournament) error {
+		if t.Recorded(g.ID) {
+			return errRecorded
+		}
+		return t.RecordResult(g)
+	})
+	if errors.Is(err, errRecorded) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.startGames(ctx, t)
+	return err
+}
+
+// startGames creates a game for each table in the current round that doesn't have one yet, then adds them to the
+// saved tournament. Any game that can't be added to its table is deleted so that no games are left orphaned.
+// The tournament admin is the admin of the games they play in, otherwise the first player at the table is.
+func (s *Service) startGames(ctx context.Context, t Tournament) (Tournament, error) {
+	if t.Status != Active {
+		return t, nil
+	}
+
+	// Create the games, adding any that were created even if they couldn't all be
+	round := t.CurrentRound()
+	created := make(map[int]game.Game)
+	var errCreate error
+	for i, table := range round.Tables {
+		if table.Bye || table.GameID != "" {
+			continue
+		}
+		adminID := table.PlayerIDs[0]
+		for _, id := range table.PlayerIDs {
+			if id == t.AdminID {
+				adminID = id
+			}
+		}
+		g, err := s.Games.Create(ctx, table.PlayerIDs, nil, t.Rules, t.TurnTimeLimit, t.GameName(i), adminID)
+		if err != nil {
+			errCreate = err
+			break
+		}
+		created[i] = g
+	}
+	if len(created) == 0 {
+		return t, errCreate
+	}
+
+	number := round.Number
+	saved, err := s.update(ctx, t.ID, func(t *Tournament) error {
+		round := t.CurrentRound()
+		if t.Status != Active || round.Number != number {
+			return errRoundOver
+		}
+		for i, g := range created {
+			if round.Tables[i].GameID == "" {
+				round.Tables[i].GameID = g.ID
+			}
+		}
+		return nil
+	})
+
+	// Clean up any games that didn't make it onto their table
+	for i, g := range created {
+		if err == nil && saved.CurrentRound().Tables[i].GameID == g.ID {
+			continue
+		}
+		errD := s.Games.Delete(ctx, g.ID, g.AdminID)
+		if errD != nil {
+			log.Printf("Failed to delete game %s of tournament %s: %v", g.ID, t.ID, errD)
+		}
+	}
+	if err != nil {
+		return Tournament{}, err
+	}
+	return saved, errCreate
+}
@@ -0,0 +1,391 @@
+package tournament
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/game"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type Status string
+
+const (
+	Registering Status = "REGISTERING"
+	Active             = "ACTIVE"
+	Completed          = "COMPLETED"
+)
+
+type Format string
+
+const (
+	// RoundRobin plays a fixed number of rounds where the entrants are seated with different opponents each round.
+	// The entrants who won the most games win the tournament.
+	RoundRobin Format = "ROUND_ROBIN"
+	// Knockout advances only the winners of each table to the next round until a final table is played.
+	Knockout = "KNOCKOUT"
+)
+
+const (
+	minTableSize = 2
+	maxTableSize = 6
+)
+
+// Table is a group of entrants playing a game in a round.
+// An entrant left without opponents is given a bye, which takes them through a knockout round without playing.
+type Table struct {
+	PlayerIDs []string `bson:"playerIds" json:"playerIds"`
+	GameID    string   `bson:"gameId,omitempty" json:"gameId,omitempty"`
+	Bye       bool     `bson:"bye" json:"bye"`
+	Completed bool     `bson:"completed" json:"completed"`
+	WinnerIDs []string `bson:"winnerIds,omitempty" json:"winnerIds,omitempty"`
+}
+
+type Round struct {
+	Number int     `bson:"number" json:"number"`
+	Tables []Table `bson:"tables" json:"tables"`
+}
+
+type Tournament struct {
+	ID            string           `bson:"_id,omitempty" json:"id"`
+	Revision      int              `bson:"revision" json:"revision"`
+	Name          string           `bson:"name" json:"name"`
+	AdminID       string           `bson:"adminId" json:"adminId"`
+	Timestamp     time.Time        `bson:"timestamp" json:"timestamp"`
+	Status        Status           `bson:"status" json:"status"`
+	Format        Format           `bson:"format" json:"format"`
+	TableSize     int              `bson:"tableSize" json:"tableSize"`
+	Rules         game.RulesPreset `bson:"rules" json:"rules"`
+	TurnTimeLimit int              `bson:"turnTimeLimit,omitempty" json:"turnTimeLimit,omitempty"`
+	Entrants      []string         `bson:"entrants" json:"entrants"`
+	Rounds        []Round          `bson:"rounds" json:"rounds"`
+	WinnerIDs     []string         `bson:"winnerIds,omitempty" json:"winnerIds,omitempty"`
+}
+
+// Standing is how an entrant is doing in a tournament.
+type Standing struct {
+	PlayerID   string `json:"playerId"`
+	Played     int    `json:"played"`
+	Won        int    `json:"won"`
+	Byes       int    `json:"byes"`
+	Eliminated bool   `json:"eliminated"`
+}
+
+// NewTournament creates a tournament that entrants can register for until it is started.
+func NewTournament(name string, adminID string, format Format, tableSize int, rules game.RulesPreset, turnTimeLimit int) (Tournament, error) {
+	if format != RoundRobin && format != Knockout {
+		return Tournament{}, api.Errorf(api.InvalidRequest, "unknown format %s", format)
+	}
+	if tableSize < minTableSize || tableSize > maxTableSize {
+		return Tournament{}, api.Errorf(api.InvalidRequest, "tables must have %d-%d players", minTableSize, maxTableSize)
+	}
+
+	// Check the rules now rather than when the first games are created
+	r, err := game.NewRules(rules)
+	if err != nil {
+		return Tournament{}, err
+	}
+	_, err = r.WithTurnTimeLimit(turnTimeLimit)
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	return Tournament{
+		ID:            strconv.Itoa(rand.Intn(10000000)),
+		Name:          name,
+		AdminID:       adminID,
+		Timestamp:     time.Now(),
+		Status:        Registering,
+		Format:        format,
+		TableSize:     tableSize,
+		Rules:         r.Preset,
+		TurnTimeLimit: turnTimeLimit,
+		Entrants:      make([]string, 0),
+		Rounds:        make([]Round, 0),
+	}, nil
+}
+
+// Register enters a player in the tournament.
+func (t *Tournament) Register(playerID string) error {
+	if t.Status != Registering {
+		return api.Errorf(api.WrongPhase, "registration is closed")
+	}
+	for _, id := range t.Entrants {
+		if id == playerID {
+			return api.Errorf(api.Conflict, "already registered")
+		}
+	}
+	t.Entrants = append(t.Entrants, playerID)
+	return nil
+}
+
+// Start closes registration, draws the seating and sets up the first round.
+func (t *Tournament) Start(adminID string) error {
+	if t.AdminID != adminID {
+		return api.Errorf(api.Forbidden, "only the admin can start the tournament")
+	}
+	if t.Status != Registering {
+		return api.Errorf(api.WrongPhase, "tournament already started")
+	}
+	if len(t.Entrants) < 2 {
+		return api.Errorf(api.InvalidRequest, "at least 2 entrants are needed")
+	}
+
+	rand.Shuffle(len(t.Entrants), func(i, j int) {
+		t.Entrants[i], t.Entrants[j] = t.Entrants[j], t.Entrants[i]
+	})
+	t.Status = Active
+	t.nextRound()
+	return nil
+}
+
+// CurrentRound returns the round being played, or the last round if the tournament is over.
+func (t *Tournament) CurrentRound() *Round {
+	if len(t.Rounds) == 0 {
+		return nil
+	}
+	return &t.Rounds[len(t.Rounds)-1]
+}
+
+// GameName is the name of the game played at a table of the current round.
+func (t *Tournament) GameName(table int) string {
+	return fmt.Sprintf("%s Round %d Table %d", t.Name, t.CurrentRound().Number, table+1)
+}
+
+// Recorded checks whether the result of a game in the tournament has been recorded.
+func (t *Tournament) Recorded(gameID string) bool {
+	for _, round := range t.Rounds {
+		for _, table := range round.Tables {
+			if table.GameID == gameID {
+				return table.Completed
+			}
+		}
+	}
+	return false
+}
+
+// RecordResult completes the table a game was played at, moving on to the next round once every table is complete.
+func (t *Tournament) RecordResult(g game.Game) error {
+	if t.Status != Active {
+		return api.Errorf(api.WrongPhase, "tournament not active")
+	}
+	if g.Status != game.Completed {
+		return api.Errorf(api.WrongPhase, "game not completed")
+	}
+
+	round := t.CurrentRound()
+	found := false
+	for i, table := range round.Tables {
+		if table.GameID != g.ID {
+			continue
+		}
+		if table.Completed {
+			return api.Errorf(api.Conflict, "result already recorded")
+		}
+		round.Tables[i].Completed = true
+		round.Tables[i].WinnerIDs = make([]string, 0)
+		for _, p := range g.Players {
+			if p.Winner {
+				round.Tables[i].WinnerIDs = append(round.Tables[i].WinnerIDs, p.ID)
+			}
+		}
+		found = true
+		break
+	}
+	if !found {
+		return api.Errorf(api.Conflict, "game %s isn't in the current round", g.ID)
+	}
+
+	for _, table := range round.Tables {
+		if !table.Completed {
+			return nil
+		}
+	}
+	t.nextRound()
+	return nil
+}
+
+// nextRound sets up the next round, or completes the tournament if there are no more rounds to play.
+func (t *Tournament) nextRound() {
+	var playerIDs []string
+	switch t.Format {
+	case Knockout:
+		playerIDs = t.advancing()
+		if len(t.Rounds) > 0 && (len(t.CurrentRound().Tables) == 1 || len(playerIDs) < 2) {
+			t.complete(playerIDs)
+			return
+		}
+	default:
+		if len(t.Rounds) == roundRobinRounds(len(t.Entrants), t.TableSize) {
+			t.complete(t.leaders())
+			return
+		}
+		playerIDs = roundRobinOrder(t.Entrants, len(t.Rounds))
+	}
+
+	t.Rounds = append(t.Rounds, Round{
+		Number: len(t.Rounds) + 1,
+		Tables: seat(playerIDs, t.TableSize),
+	})
+}
+
+func (t *Tournament) complete(winnerIDs []string) {
+	t.Status = Completed
+	t.WinnerIDs = winnerIDs
+}
+
+// advancing returns the entrants still in a knockout tournament.
+func (t *Tournament) advancing() []string {
+	round := t.CurrentRound()
+	if round == nil {
+		return t.Entrants
+	}
+	playerIDs := make([]string, 0)
+	for _, table := range round.Tables {
+		if table.Bye {
+			playerIDs = append(playerIDs, table.PlayerIDs...)
+		} else {
+			playerIDs = append(playerIDs, table.WinnerIDs...)
+		}
+	}
+	return playerIDs
+}
+
+// leaders returns the entrants who have won the most games.
+func (t *Tournament) leaders() []string {
+	standings := t.Standings()
+	leaders := make([]string, 0)
+	for _, s := range standings {
+		if s.Won == standings[0].Won {
+			leaders = append(leaders, s.PlayerID)
+		}
+	}
+	return leaders
+}
+
+// Standings returns the entrants ordered by the number of games they have won.
+func (t *Tournament) Standings() []Standing {
+	standings := make([]Standing, len(t.Entrants))
+	index := make(map[string]int)
+	for i, id := range t.Entrants {
+		standings[i] = Standing{PlayerID: id}
+		index[id] = i
+	}
+
+	for _, round := range t.Rounds {
+		for _, table := range round.Tables {
+			for _, id := range table.PlayerIDs {
+				s := &standings[index[id]]
+				if table.Bye {
+					s.Byes++
+				} else if table.Completed {
+					s.Played++
+				}
+			}
+			for _, id := range table.WinnerIDs {
+				standings[index[id]].Won++
+			}
+		}
+	}
+
+	// Anyone not seated in the current round of a knockout has been knocked out
+	if t.Format == Knockout && t.Status == Active {
+		seated := make(map[string]bool)
+		for _, table := range t.CurrentRound().Tables {
+			for _, id := range table.PlayerIDs {
+				seated[id] = true
+			}
+		}
+		for i := range standings {
+			standings[i].Eliminated = !seated[standings[i].PlayerID]
+		}
+	}
+	if t.Format == Knockout && t.Status == Completed {
+		won := make(map[string]bool)
+		for _, id := range t.WinnerIDs {
+			won[id] = true
+		}
+		for i := range standings {
+			standings[i].Eliminated = !won[standings[i].PlayerID]
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Eliminated != standings[j].Eliminated {
+			return !standings[i].Eliminated
+		}
+		return standings[i].Won > standings[j].Won
+	})
+	return standings
+}
+
+// seat splits the players into as few tables as possible with sizes differing by at most one.
+// A player left at a table on their own has a bye.
+func seat(playerIDs []string, tableSize int) []Table {
+	count := (len(playerIDs) + tableSize - 1) / tableSize
+	tables := make([]Table, count)
+	start := 0
+	for i := range tables {
+		size := len(playerIDs) / count
+		if i < len(playerIDs)%count {
+			size++
+		}
+		tables[i] = Table{PlayerIDs: playerIDs[start : start+size]}
+		if size == 1 {
+			tables[i].Bye = true
+			tables[i].Completed = true
+		}
+		start += size
+	}
+	return tables
+}
+
+// roundRobinRounds returns how many rounds it takes for every entrant to be paired with every other entrant.
+// If everyone fits at one table a single round is enough.
+func roundRobinRounds(entrants int, tableSize int) int {
+	if entrants <= tableSize {
+		return 1
+	}
+	if entrants%2 == 1 {
+		return entrants
+	}
+	return entrants - 1
+}
+
+// roundRobinOrder orders the entrants for a round using the circle method, so that consecutive entrants are paired
+// with someone new each round. With an odd number of entrants the one left unpaired is placed last, so they have a
+// bye when playing head to head.
+func roundRobinOrder(entrants []string, round int) []string {
+	circle := append([]string{}, entrants...)
+	if len(circle)%2 == 1 {
+		circle = append(circle, "")
+	}
+
+	// Keep the first entrant in place and rotate everyone else
+	n := len(circle)
+	rotated := make([]string, n)
+	rotated[0] = circle[0]
+	for i := 1; i < n; i++ {
+		rotated[i] = circle[1+(i-1+round)%(n-1)]
+	}
+
+	order := make([]string, 0, len(entrants))
+	unpaired := ""
+	for i := 0; i < n/2; i++ {
+		a, b := rotated[i], rotated[n-1-i]
+		switch {
+		case a == "":
+			unpaired = b
+		case b == "":
+			unpaired = a
+		default:
+			order = append(order, a, b)
+		}
+	}
+	if unpaired != "" {
+		order = append(order, unpaired)
+	}
+	return order
+}
@@ -0,0 +1,555 @@
+package tournament
+
+import (
+	"cards-110-api/pkg/api"
+	"cards-110-api/pkg/db"
+	"cards-110-api/pkg/game"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func entrants(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("p%d", i+1)
+	}
+	return ids
+}
+
+// startedTournament returns a started tournament with n entrants.
+func startedTournament(t *testing.T, format Format, tableSize int, n int) Tournament {
+	tournament, err := NewTournament("Club", "p1", format, tableSize, game.HouseRules, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, id := range entrants(n) {
+		if err := tournament.Register(id); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := tournament.Start("p1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return tournament
+}
+
+// playRound completes every game of the current round, with the first player at each table winning.
+func playRound(t *testing.T, tournament *Tournament) {
+	round := tournament.CurrentRound()
+	for i := range round.Tables {
+		round.Tables[i].GameID = fmt.Sprintf("r%dt%d", round.Number, i)
+	}
+	for _, table := range round.Tables {
+		if table.Bye {
+			continue
+		}
+		players := make([]game.Player, len(table.PlayerIDs))
+		for i, id := range table.PlayerIDs {
+			players[i] = game.Player{ID: id, Winner: i == 0}
+		}
+		err := tournament.RecordResult(game.Game{ID: table.GameID, Status: game.Completed, Players: players})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestTournament_NewTournament(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        Format
+		tableSize     int
+		rules         game.RulesPreset
+		turnTimeLimit int
+		expectedCode  api.ErrorCode
+	}{
+		{name: "Round robin", format: RoundRobin, tableSize: 2},
+		{name: "Knockout", format: Knockout, tableSize: 6, rules: game.ShortGame},
+		{name: "Unknown format", format: "SWISS", tableSize: 2, expectedCode: api.InvalidRequest},
+		{name: "Table too small", format: Knockout, tableSize: 1, expectedCode: api.InvalidRequest},
+		{name: "Table too big", format: Knockout, tableSize: 7, expectedCode: api.InvalidRequest},
+		{name: "Unknown rules", format: Knockout, tableSize: 4, rules: "FAST", expectedCode: api.InvalidRequest},
+		{name: "Turn time limit too short", format: Knockout, tableSize: 4, turnTimeLimit: 1, expectedCode: api.InvalidRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tournament, err := NewTournament("Club", "1", test.format, test.tableSize, test.rules, test.turnTimeLimit)
+			if test.expectedCode != "" {
+				if api.CodeOf(err) != test.expectedCode {
+					t.Errorf("expected %s, got %v", test.expectedCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tournament.Status != Registering || tournament.Rules == "" {
+				t.Errorf("unexpected tournament %v", tournament)
+			}
+		})
+	}
+}
+
+func TestTournament_Start(t *testing.T) {
+	tournament, _ := NewTournament("Club", "p1", Knockout, 2, game.HouseRules, 0)
+	_ = tournament.Register("p1")
+
+	if err := tournament.Register("p1"); api.CodeOf(err) != api.Conflict {
+		t.Errorf("expected registering twice to conflict, got %v", err)
+	}
+	if err := tournament.Start("p1"); api.CodeOf(err) != api.InvalidRequest {
+		t.Errorf("expected starting with one entrant to fail, got %v", err)
+	}
+	_ = tournament.Register("p2")
+	if err := tournament.Start("p2"); api.CodeOf(err) != api.Forbidden {
+		t.Errorf("expected only the admin to be able to start, got %v", err)
+	}
+	if err := tournament.Start("p1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tournament.Register("p3"); api.CodeOf(err) != api.WrongPhase {
+		t.Errorf("expected registration to be closed, got %v", err)
+	}
+	if tournament.Status != Active || len(tournament.Rounds) != 1 {
+		t.Errorf("expected the first round to be set up, got %v", tournament)
+	}
+}
+
+func TestTournament_Seat(t *testing.T) {
+	tests := []struct {
+		name          string
+		players       int
+		tableSize     int
+		expectedSizes []int
+	}{
+		{name: "Head to head", players: 4, tableSize: 2, expectedSizes: []int{2, 2}},
+		{name: "Head to head with a bye", players: 5, tableSize: 2, expectedSizes: []int{2, 2, 1}},
+		{name: "Balanced tables", players: 7, tableSize: 3, expectedSizes: []int{3, 2, 2}},
+		{name: "One table", players: 5, tableSize: 6, expectedSizes: []int{5}},
+		{name: "Full tables", players: 12, tableSize: 6, expectedSizes: []int{6, 6}},
+		{name: "Split evenly", players: 7, tableSize: 6, expectedSizes: []int{4, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tables := seat(entrants(test.players), test.tableSize)
+			if len(tables) != len(test.expectedSizes) {
+				t.Fatalf("expected %d tables, got %d", len(test.expectedSizes), len(tables))
+			}
+			for i, table := range tables {
+				if len(table.PlayerIDs) != test.expectedSizes[i] {
+					t.Errorf("expected table %d to have %d players, got %d", i, test.expectedSizes[i], len(table.PlayerIDs))
+				}
+				if table.Bye != (len(table.PlayerIDs) == 1) {
+					t.Errorf("expected a bye only for a table of one")
+				}
+			}
+		})
+	}
+}
+
+func TestTournament_RoundRobin(t *testing.T) {
+	tests := []struct {
+		name           string
+		entrants       int
+		tableSize      int
+		expectedRounds int
+		expectedByes   int
+	}{
+		{name: "Even entrants", entrants: 4, tableSize: 2, expectedRounds: 3},
+		{name: "Odd entrants", entrants: 5, tableSize: 2, expectedRounds: 5, expectedByes: 1},
+		{name: "Everyone at one table", entrants: 4, tableSize: 4, expectedRounds: 1},
+		{name: "Larger tables", entrants: 8, tableSize: 4, expectedRounds: 7},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tournament := startedTournament(t, RoundRobin, test.tableSize, test.entrants)
+
+			// Record who has sat with whom
+			met := make(map[string]bool)
+			for tournament.Status == Active {
+				for _, table := range tournament.CurrentRound().Tables {
+					for _, a := range table.PlayerIDs {
+						for _, b := range table.PlayerIDs {
+							met[a+"-"+b] = true
+						}
+					}
+				}
+				playRound(t, &tournament)
+			}
+
+			if len(tournament.Rounds) != test.expectedRounds {
+				t.Errorf("expected %d rounds, got %d", test.expectedRounds, len(tournament.Rounds))
+			}
+			for _, a := range tournament.Entrants {
+				for _, b := range tournament.Entrants {
+					if !met[a+"-"+b] {
+						t.Errorf("expected %s to have played %s", a, b)
+					}
+				}
+			}
+			for _, s := range tournament.Standings() {
+				if s.Byes != test.expectedByes {
+					t.Errorf("expected %s to have %d byes, got %d", s.PlayerID, test.expectedByes, s.Byes)
+				}
+			}
+			if len(tournament.WinnerIDs) == 0 {
+				t.Errorf("expected the tournament to have a winner")
+			}
+		})
+	}
+}
+
+func TestTournament_Knockout(t *testing.T) {
+	tests := []struct {
+		name           string
+		entrants       int
+		tableSize      int
+		expectedRounds int
+	}{
+		{name: "Head to head", entrants: 8, tableSize: 2, expectedRounds: 3},
+		{name: "Byes", entrants: 5, tableSize: 2, expectedRounds: 3},
+		{name: "Final table", entrants: 9, tableSize: 3, expectedRounds: 2},
+		{name: "Single table", entrants: 4, tableSize: 6, expectedRounds: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tournament := startedTournament(t, Knockout, test.tableSize, test.entrants)
+			for tournament.Status == Active {
+				playRound(t, &tournament)
+			}
+
+			if len(tournament.Rounds) != test.expectedRounds {
+				t.Errorf("expected %d rounds, got %d", test.expectedRounds, len(tournament.Rounds))
+			}
+			if len(tournament.WinnerIDs) != 1 {
+				t.Fatalf("expected one winner, got %v", tournament.WinnerIDs)
+			}
+			standings := tournament.Standings()
+			if standings[0].PlayerID != tournament.WinnerIDs[0] || standings[0].Eliminated {
+				t.Errorf("expected the winner to top the standings, got %v", standings[0])
+			}
+			for _, s := range standings[1:] {
+				if !s.Eliminated {
+					t.Errorf("expected %s to be eliminated", s.PlayerID)
+				}
+			}
+		})
+	}
+}
+
+func TestTournament_RecordResultErrors(t *testing.T) {
+	tournament := startedTournament(t, Knockout, 2, 4)
+	tournament.CurrentRound().Tables[0].GameID = "1"
+	players := []game.Player{{ID: "p1", Winner: true}, {ID: "p2"}}
+
+	err := tournament.RecordResult(game.Game{ID: "1", Status: game.Active, Players: players})
+	if api.CodeOf(err) != api.WrongPhase {
+		t.Errorf("expected an unfinished game to be rejected, got %v", err)
+	}
+	err = tournament.RecordResult(game.Game{ID: "2", Status: game.Completed, Players: players})
+	if api.CodeOf(err) != api.Conflict {
+		t.Errorf("expected an unknown game to be rejected, got %v", err)
+	}
+	_ = tournament.RecordResult(game.Game{ID: "1", Status: game.Completed, Players: players})
+	err = tournament.RecordResult(game.Game{ID: "1", Status: game.Completed, Players: players})
+	if api.CodeOf(err) != api.Conflict {
+		t.Errorf("expected a result to only be recorded once, got %v", err)
+	}
+}
+
+// games creates and deletes the games of a tournament.
+type games struct {
+	game.ServiceI
+	createErrs []error
+	created    int
+	deleted    int
+	existing   map[string]game.Game
+}
+
+func (g *games) Get(ctx context.Context, gameId string) (game.Game, bool, error) {
+	existing, has := g.existing[gameId]
+	return existing, has, nil
+}
+
+func (g *games) Create(ctx context.Context, playerIDs []string, bots []game.BotType, rules game.RulesPreset, turnTimeLimit int, name string, adminID string) (game.Game, error) {
+	if len(g.createErrs) > 0 {
+		err := g.createErrs[0]
+		g.createErrs = g.createErrs[1:]
+		if err != nil {
+			return game.Game{}, err
+		}
+	}
+	g.created++
+	return game.Game{ID: fmt.Sprintf("g%d", g.created), AdminID: adminID}, nil
+}
+
+func (g *games) Delete(ctx context.Context, gameId string, adminId string) error {
+	g.deleted++
+	return nil
+}
+
+func TestTournamentService_Start(t *testing.T) {
+	ctx := context.Background()
+	registering := func() Tournament {
+		tournament, _ := NewTournament("Club", "p1", Knockout, 2, game.HouseRules, 0)
+		tournament.Entrants = entrants(4)
+		return tournament
+	}
+	started := func() Tournament {
+		return startedTournament(t, Knockout, 2, 4)
+	}
+	over := started()
+	over.Status = Completed
+
+	tests := []struct {
+		name            string
+		mockGetResult   *[]Tournament
+		mockConflict    *[]bool
+		createErrs      []error
+		expectedCreated int
+		expectedDeleted int
+		expectingError  bool
+	}{
+		{
+			name:            "games added once the tournament is saved",
+			mockGetResult:   &[]Tournament{registering(), started()},
+			mockConflict:    &[]bool{false, false},
+			expectedCreated: 2,
+		},
+		{
+			name:            "games that were created are kept if the rest can't be",
+			mockGetResult:   &[]Tournament{registering(), started()},
+			mockConflict:    &[]bool{false, false},
+			createErrs:      []error{nil, errors.New("failed to create")},
+			expectedCreated: 1,
+			expectingError:  true,
+		},
+		{
+			name:            "games are deleted if they can't be added",
+			mockGetResult:   &[]Tournament{registering(), over},
+			mockConflict:    &[]bool{false},
+			expectedCreated: 2,
+			expectedDeleted: 2,
+			expectingError:  true,
+		},
+		{
+			name:           "nothing created if the tournament can't be saved",
+			mockGetResult:  &[]Tournament{registering(), registering(), registering()},
+			mockConflict:   &[]bool{true, true, true},
+			expectingError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := &games{createErrs: test.createErrs}
+			s := Service{
+				Col: &db.MockCollection[Tournament]{
+					MockFindOneResult:                test.mockGetResult,
+					MockFindOneExists:                &[]bool{true, true, true},
+					MockFindOneErr:                   &[]error{},
+					MockConditionalUpdateOneConflict: test.mockConflict,
+				},
+				Games: g,
+			}
+
+			tournament, err := s.Start(ctx, "1", "p1")
+			if test.expectingError != (err != nil) {
+				t.Fatalf("expected error %v, got %v", test.expectingError, err)
+			}
+			if g.created != test.expectedCreated {
+				t.Errorf("expected %d games to be created, got %d", test.expectedCreated, g.created)
+			}
+			if g.deleted != test.expectedDeleted {
+				t.Errorf("expected %d games to be deleted, got %d", test.expectedDeleted, g.deleted)
+			}
+			if err == nil {
+				for _, table := range tournament.CurrentRound().Tables {
+					if table.GameID == "" {
+						t.Errorf("expected every table to have a game, got %v", table)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTournamentService_GameCompleted(t *testing.T) {
+	ctx := context.Background()
+	// Each read of the tournament gets its own copy, as it would from the database
+	playing := func() Tournament {
+		tournament := startedTournament(t, RoundRobin, 2, 4)
+		for i := range tournament.CurrentRound().Tables {
+			tournament.CurrentRound().Tables[i].GameID = fmt.Sprintf("t%d", i)
+		}
+		return tournament
+	}
+	recorded := playing()
+	table := recorded.CurrentRound().Tables[0]
+	players := []game.Player{{ID: table.PlayerIDs[0], Winner: true}, {ID: table.PlayerIDs[1]}}
+	completed := game.Game{ID: "t0", Status: game.Completed, Players: players}
+	_ = recorded.RecordResult(completed)
+
+	tests := []struct {
+		name           string
+		mockGetResult  *[]Tournament
+		mockConflict   *[]bool
+		expectedSaves  int
+		expectingError bool
+	}{
+		{
+			name:          "result recorded",
+			mockGetResult: &[]Tournament{playing(), playing()},
+			mockConflict:  &[]bool{false},
+			expectedSaves: 1,
+		},
+		{
+			name:          "conflict is retried against the latest revision",
+			mockGetResult: &[]Tournament{playing(), playing(), playing()},
+			mockConflict:  &[]bool{true, false},
+			expectedSaves: 2,
+		},
+		{
+			name:          "result already recorded by someone else",
+			mockGetResult: &[]Tournament{playing(), recorded},
+			mockConflict:  &[]bool{},
+		},
+		{
+			name:           "persistent conflict returns ErrConflict",
+			mockGetResult:  &[]Tournament{playing(), playing(), playing(), playing()},
+			mockConflict:   &[]bool{true, true, true},
+			expectedSaves:  3,
+			expectingError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := len(*test.mockConflict)
+			s := Service{
+				Col: &db.MockCollection[Tournament]{
+					MockFindOneResult:                test.mockGetResult,
+					MockFindOneExists:                &[]bool{true, true, true, true},
+					MockFindOneErr:                   &[]error{},
+					MockConditionalUpdateOneConflict: test.mockConflict,
+				},
+				Games: &games{},
+			}
+
+			err := s.GameCompleted(ctx, completed)
+			if test.expectingError != (err != nil) {
+				t.Fatalf("expected error %v, got %v", test.expectingError, err)
+			}
+			if saves := attempts - len(*test.mockConflict); saves != test.expectedSaves {
+				t.Errorf("expected %d saves, got %d", test.expectedSaves, saves)
+			}
+		})
+	}
+}
+
+func TestTournamentService_Get(t *testing.T) {
+	ctx := context.Background()
+	started := func() Tournament {
+		return startedTournament(t, RoundRobin, 2, 4)
+	}
+	playing := func() Tournament {
+		tournament := started()
+		for i := range tournament.CurrentRound().Tables {
+			tournament.CurrentRound().Tables[i].GameID = fmt.Sprintf("t%d", i)
+		}
+		return tournament
+	}
+	before := playing()
+	table := before.CurrentRound().Tables[0]
+	players := []game.Player{{ID: table.PlayerIDs[0], Winner: true}, {ID: table.PlayerIDs[1]}}
+	completed := game.Game{ID: "t0", Status: game.Completed, Players: players}
+	recorded := playing()
+	_ = recorded.RecordResult(completed)
+
+	tests := []struct {
+		name            string
+		mockGetResult   *[]Tournament
+		mockConflict    *[]bool
+		existing        map[string]game.Game
+		expectedCreated int
+		expectedSaves   int
+	}{
+		{
+			name:          "nothing to resume",
+			mockGetResult: &[]Tournament{playing()},
+			mockConflict:  &[]bool{},
+			existing:      map[string]game.Game{"t0": {ID: "t0", Status: game.Active}, "t1": {ID: "t1", Status: game.Active}},
+		},
+		{
+			name:            "games that weren't started are started",
+			mockGetResult:   &[]Tournament{started(), started()},
+			mockConflict:    &[]bool{false},
+			expectedCreated: 2,
+			expectedSaves:   1,
+		},
+		{
+			name:          "result of a completed game that wasn't recorded is recorded",
+			mockGetResult: &[]Tournament{playing(), playing(), playing(), recorded},
+			mockConflict:  &[]bool{false},
+			existing:      map[string]game.Game{"t0": completed, "t1": {ID: "t1", Status: game.Active}},
+			expectedSaves: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := len(*test.mockConflict)
+			g := &games{existing: test.existing}
+			s := Service{
+				Col: &db.MockCollection[Tournament]{
+					MockFindOneResult:                test.mockGetResult,
+					MockFindOneExists:                &[]bool{true, true, true, true},
+					MockFindOneErr:                   &[]error{},
+					MockConditionalUpdateOneConflict: test.mockConflict,
+				},
+				Games: g,
+			}
+
+			tournament, has, err := s.Get(ctx, "1")
+			if err != nil || !has {
+				t.Fatalf("expected the tournament, got %v %v", has, err)
+			}
+			if g.created != test.expectedCreated {
+				t.Errorf("expected %d games to be created, got %d", test.expectedCreated, g.created)
+			}
+			if saves := attempts - len(*test.mockConflict); saves != test.expectedSaves {
+				t.Errorf("expected %d saves, got %d", test.expectedSaves, saves)
+			}
+			for _, table := range tournament.CurrentRound().Tables {
+				if table.GameID == "" {
+					t.Errorf("expected every table to have a game, got %v", table)
+				}
+			}
+		})
+	}
+}
+
+func TestTournamentService_CheckDelete(t *testing.T) {
+	s := Service{
+		Col: &db.MockCollection[Tournament]{
+			MockFindOneResult: &[]Tournament{startedTournament(t, Knockout, 2, 4), {}},
+			MockFindOneExists: &[]bool{true, false},
+			MockFindOneErr:    &[]error{},
+		},
+	}
+
+	err := s.CheckDelete(context.Background(), game.Game{ID: "1"})
+	if api.CodeOf(err) != api.Conflict {
+		t.Errorf("expected a game in a tournament not to be deletable, got %v", err)
+	}
+	err = s.CheckDelete(context.Background(), game.Game{ID: "2"})
+	if err != nil {
+		t.Errorf("expected a game outside a tournament to be deletable, got %v", err)
+	}
+}